@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/internal/builtin"
 	"github.com/thalesfsp/inference/internal/config"
 	"github.com/thalesfsp/inference/message"
 	"github.com/thalesfsp/inference/provider"
@@ -26,6 +27,15 @@ const Name = "anthropic"
 // Singleton.
 var singleton provider.IProvider
 
+// capabilities of the provider, and its models.
+var capabilities = provider.Capabilities{
+	MaxContext: 200000,
+	Streaming:  true,
+	Tools:      true,
+	TopK:       true,
+	Vision:     true,
+}
+
 // Anthropic provider definition.
 type Anthropic struct {
 	*provider.Provider
@@ -50,49 +60,40 @@ type Anthropic struct {
 // NOTE: Not all options are available for all providers.
 func (p *Anthropic) Completion(ctx context.Context, options ...provider.Func) (string, error) {
 	//////
-	// Options initialization.
+	// Tracing.
 	//////
 
-	// Prepend the default model to the options.
-	options = append(
-		[]provider.Func{
-			provider.WithModel(p.DefaultModel),
-		},
-		options...,
-	)
-
-	processedOptions, err := provider.NewOptionsFrom(options...)
-	if err != nil {
-		return "", err
-	}
+	// Started first, so invalid requests are traced, and measured too.
+	ctx, span := p.StartSpan(ctx)
+	defer span.End()
 
 	//////
-	// Messages processing.
+	// Request.
 	//////
 
-	finalMessages := message.NewMessages(
-		[]string{},
-		processedOptions.UserMessages,
-	)
+	reqBody, processedOptions, err := p.BuildRequestBody(ctx, options...)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	span.SetRequest(processedOptions)
 
 	//////
-	// Request body formation.
+	// Budget, and usage accounting.
 	//////
 
-	reqBody := &RequestBody{
-		Messages: finalMessages,
-		Model:    processedOptions.Model,
-		Stream:   processedOptions.Stream,
+	settle, err := p.Reserve(ctx, processedOptions)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
 
-		MaxTokens:   processedOptions.MaxTokens,
-		Temperature: processedOptions.Temperature,
-		TopP:        processedOptions.TopP,
-		TopK:        processedOptions.TopK,
+		return "", span.Fail(err)
 	}
 
-	if len(processedOptions.SystemMessages) > 0 {
-		reqBody.System = processedOptions.SystemMessages[0]
-	}
+	var usage provider.Usage
+
+	defer func() { settle(usage) }()
 
 	//////
 	// Call LLM provider.
@@ -103,28 +104,35 @@ func (p *Anthropic) Completion(ctx context.Context, options ...provider.Func) (s
 	// Track performance.
 	now := time.Now()
 
-	resp, err := p.client.Post(
-		ctx,
-		p.Endpoint,
-		httpclient.WithHeader("x-api-key", p.Token),
-		httpclient.WithHeader("anthropic-version", "2023-06-01"),
-		httpclient.WithReqBody(reqBody),
-		httpclient.WithRespBody(&respBody),
-	)
+	if processedOptions.StreamHandler != nil {
+		respBody, err = p.stream(ctx, reqBody, processedOptions.StreamHandler)
+	} else {
+		err = p.post(ctx, reqBody, &respBody)
+	}
+
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
 	}
 
-	defer resp.Body.Close()
+	usage = respBody.Usage.ToUsage()
+	usage.FinishReason = ToFinishReason(respBody.StopReason)
+
+	span.SetResponse(respBody.ID, respBody.Model, respBody.StopReason)
+	span.SetUsage(usage)
 
 	// Response processing.
 	response, err := ProcessResponse(respBody)
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
+	}
+
+	// Optional thinking processing.
+	if extra, _, _ := provider.GetExtra[Extra](processedOptions, Name); extra.Thought != nil {
+		*extra.Thought = ProcessThinking(respBody)
 	}
 
 	// Optional response body processing.
@@ -133,7 +141,7 @@ func (p *Anthropic) Completion(ctx context.Context, options ...provider.Func) (s
 			[]byte(response),
 			processedOptions.ResponseBody,
 		); err != nil {
-			return "", err
+			return "", span.Fail(err)
 		}
 	}
 
@@ -146,6 +154,10 @@ func (p *Anthropic) Completion(ctx context.Context, options ...provider.Func) (s
 		level.Debug,
 		fmt.Sprintf("Completion %s", status.Created.String()),
 		sypl.WithField("duration", time.Since(now)),
+		sypl.WithField("inputTokens", usage.InputTokens),
+		sypl.WithField("cacheCreationInputTokens", usage.CacheCreationInputTokens),
+		sypl.WithField("cacheReadInputTokens", usage.CacheReadInputTokens),
+		sypl.WithField("outputTokens", usage.OutputTokens),
 	)
 
 	// Metrics.
@@ -154,6 +166,80 @@ func (p *Anthropic) Completion(ctx context.Context, options ...provider.Func) (s
 	return response, nil
 }
 
+// BuildRequestBody processes the options, and builds the request body sent to
+// the provider API.
+func (p *Anthropic) BuildRequestBody(ctx context.Context, options ...provider.Func) (*RequestBody, *provider.Options, error) {
+	//////
+	// Options initialization.
+	//////
+
+	// Prepend the provider, its default model, and capabilities to the
+	// options.
+	options = append(
+		[]provider.Func{
+			provider.WithProvider(p.GetName()),
+			provider.WithModel(p.DefaultModel),
+			provider.WithCapabilities(p.GetCapabilities()),
+		},
+		options...,
+	)
+
+	processedOptions, err := provider.NewOptionsFrom(options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Context window.
+	if err := provider.Truncate(ctx, processedOptions); err != nil {
+		return nil, nil, err
+	}
+
+	//////
+	// Messages processing.
+	//////
+
+	finalMessages := ToMessages(message.NewConversation(
+		[]string{},
+		processedOptions.History,
+		processedOptions.UserMessages,
+	))
+
+	//////
+	// Request body formation.
+	//////
+
+	reqBody := &RequestBody{
+		Messages: finalMessages,
+		Model:    processedOptions.Model,
+		Stream:   processedOptions.Stream,
+
+		MaxTokens:     processedOptions.MaxTokens,
+		StopSequences: processedOptions.Stop,
+		Temperature:   processedOptions.Temperature,
+		TopP:          processedOptions.TopP,
+		TopK:          processedOptions.TopK,
+	}
+
+	// All system messages, in order, as text blocks.
+	if len(processedOptions.SystemMessages) > 0 {
+		reqBody.System = textBlocks(processedOptions.SystemMessages)
+	}
+
+	// Anthropic-specific options.
+	extra, ok, err := provider.GetExtra[Extra](processedOptions, Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok {
+		if err := extra.apply(reqBody, len(processedOptions.History)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return reqBody, processedOptions, nil
+}
+
 // GetClient returns the client.
 func (p *Anthropic) GetClient() any {
 	return p.client
@@ -163,8 +249,20 @@ func (p *Anthropic) GetClient() any {
 // Factory.
 //////
 
-// New creates a new Anthropic provider.
-func New(
+// New creates a new Anthropic provider, and sets it as the one Get returns.
+func New(options ...provider.ClientFunc) (*Anthropic, error) {
+	p, err := newProvider(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	singleton = p
+
+	return p, nil
+}
+
+// newProvider creates a new Anthropic provider, without setting the singleton.
+func newProvider(
 	options ...provider.ClientFunc,
 ) (*Anthropic, error) {
 	// Enforces IProvider interface implementation.
@@ -175,9 +273,9 @@ func New(
 		return nil, err
 	}
 
-	client, err := httpclient.NewDefault(
-		httpclient.WithClientName(Name),
-	)
+	p.Capabilities = Capabilities()
+
+	client, err := p.NewHTTPClient()
 	if err != nil {
 		return nil, err
 	}
@@ -195,8 +293,6 @@ func New(
 		return nil, err
 	}
 
-	singleton = provider
-
 	return provider, nil
 }
 
@@ -212,10 +308,28 @@ func NewDefault(options ...provider.ClientFunc) (*Anthropic, error) {
 	return New(opts...)
 }
 
+// Registers the constructor, for the registry.
+func init() {
+	builtin.Register(Name, func(options ...provider.ClientFunc) (provider.IProvider, error) {
+		p, err := newProvider(options...)
+		if err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	})
+}
+
 //////
 // Exported functionalities.
 //////
 
+// Capabilities returns a copy of the capabilities of the provider, and its
+// models.
+func Capabilities() provider.Capabilities {
+	return capabilities.Clone()
+}
+
 // Get returns a setup MongoDB, or set it up.
 func Get() provider.IProvider {
 	if singleton == nil {
@@ -1,10 +1,14 @@
 package anthropic
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/cassette/cassettetest"
 	"github.com/thalesfsp/inference/internal/config"
 	"github.com/thalesfsp/inference/provider"
 )
@@ -91,3 +95,66 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildRequestBody(t *testing.T) {
+	a := &Anthropic{Provider: &provider.Provider{DefaultModel: "claude-3-5-sonnet-20240620"}}
+
+	reqBody, _, err := a.BuildRequestBody(
+		context.Background(),
+		provider.WithTopK(40),
+		provider.WithUserMessages("why is the sky blue"),
+		WithExtra(Extra{Metadata: &Metadata{UserID: "user-1234"}}),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "claude-3-5-sonnet-20240620", reqBody.Model)
+	assert.Equal(t, 40, reqBody.TopK)
+	assert.Equal(t, &Metadata{UserID: "user-1234"}, reqBody.Metadata)
+	assert.Empty(t, reqBody.System)
+
+	// All system messages are sent, in order, as text blocks.
+	reqBody, _, err = a.BuildRequestBody(
+		context.Background(),
+		provider.WithSystemMessages("be brief", "be kind"),
+		provider.WithUserMessages("why is the sky blue"),
+	)
+	assert.NoError(t, err)
+
+	data, err := json.Marshal(reqBody)
+	assert.NoError(t, err)
+	assert.Contains(
+		t,
+		string(data),
+		`"system":[{"text":"be brief","type":"text"},{"text":"be kind","type":"text"}]`,
+	)
+}
+
+func TestCompletion(t *testing.T) {
+	// Replays `testdata/cassettes/completion.json`. Set `CASSETTE_MODE` to
+	// `record` to record it again against the API.
+	//
+	// NOTE: The cassette is synthetic, written by hand, not recorded, thus its
+	// IDs, and response headers aren't the API's. Record it with `make record`.
+	r := cassettetest.ForTest(t, "completion")
+
+	p, err := NewDefault(
+		provider.WithToken(cmp.Or(config.Get().AnthropicToken, "test")),
+		provider.WithTransport(r),
+	)
+	assert.NoError(t, err)
+
+	var usage provider.Usage
+
+	response, err := p.Completion(
+		context.Background(),
+		provider.WithModel("claude-3-5-haiku-20241022"),
+		provider.WithMaxToken(16),
+		provider.WithSystemMessages("Answer with a single word."),
+		provider.WithUserMessages("What is the color of a clear daytime sky?"),
+		provider.WithUsage(&usage),
+	)
+	assert.NoError(t, err)
+	assert.Contains(t, strings.ToLower(response), "blue")
+	assert.Positive(t, usage.InputTokens)
+	assert.Positive(t, usage.OutputTokens)
+}
@@ -0,0 +1,268 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/batch"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Enforces the batch.IBatcher interface implementation.
+var _ batch.IBatcher = (*Anthropic)(nil)
+
+// Types of batch results.
+const (
+	BatchResultCanceled  = "canceled"
+	BatchResultErrored   = "errored"
+	BatchResultExpired   = "expired"
+	BatchResultSucceeded = "succeeded"
+)
+
+// Processing statuses of batches.
+const (
+	BatchStatusCanceling  = "canceling"
+	BatchStatusEnded      = "ended"
+	BatchStatusInProgress = "in_progress"
+)
+
+//////
+// Implements the batch.IBatcher interface.
+//////
+
+// SubmitBatch builds the requests as a message batch, and creates it.
+func (p *Anthropic) SubmitBatch(ctx context.Context, requests []batch.Request) (*batch.Job, error) {
+	if err := batch.Validate(requests); err != nil {
+		return nil, err
+	}
+
+	reqBody := BatchRequestBody{Requests: make([]BatchRequest, 0, len(requests))}
+
+	for _, r := range requests {
+		params, _, err := p.BuildRequestBody(ctx, r.Options...)
+		if err != nil {
+			return nil, customerror.NewInvalidError(fmt.Sprintf("request %s", r.ID), customerror.WithError(err))
+		}
+
+		if params.Stream {
+			return nil, customerror.NewInvalidError(fmt.Sprintf("request %s, streaming isn't supported in batches", r.ID))
+		}
+
+		reqBody.Requests = append(reqBody.Requests, BatchRequest{CustomID: r.ID, Params: params})
+	}
+
+	u, err := batchesURL(p.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody Batch
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithHeader("x-api-key", p.Token),
+		httpclient.WithHeader("anthropic-version", "2023-06-01"),
+		httpclient.WithReqBody(reqBody),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return respBody.toJob(), nil
+}
+
+// GetBatch returns the batch.
+func (p *Anthropic) GetBatch(ctx context.Context, id string) (*batch.Job, error) {
+	b, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.toJob(), nil
+}
+
+// CancelBatch cancels the batch. Requests processed before have results.
+func (p *Anthropic) CancelBatch(ctx context.Context, id string) (*batch.Job, error) {
+	if id == "" {
+		return nil, customerror.NewRequiredError("id")
+	}
+
+	u, err := batchesURL(p.Endpoint, id, "cancel")
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody Batch
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithHeader("x-api-key", p.Token),
+		httpclient.WithHeader("anthropic-version", "2023-06-01"),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return respBody.toJob(), nil
+}
+
+// BatchResults downloads the results of the batch, and returns the result of
+// each request. Costs are discounted.
+func (p *Anthropic) BatchResults(ctx context.Context, id string) ([]batch.Result, error) {
+	b, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.ProcessingStatus != BatchStatusEnded || b.ResultsURL == "" {
+		return nil, customerror.NewInvalidError(
+			fmt.Sprintf("batch %s, not ended, status %s", id, b.ProcessingStatus),
+		)
+	}
+
+	resp, err := p.client.Get(
+		ctx,
+		b.ResultsURL,
+		httpclient.WithHeader("x-api-key", p.Token),
+		httpclient.WithHeader("anthropic-version", "2023-06-01"),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	results := []batch.Result{}
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var line BatchResultLine
+
+		if err := decoder.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, customerror.NewFailedToError("decode batch results", customerror.WithError(err))
+		}
+
+		results = append(results, line.toResult())
+	}
+
+	return results, nil
+}
+
+//////
+// Helpers.
+//////
+
+// getBatch returns the API batch.
+func (p *Anthropic) getBatch(ctx context.Context, id string) (*Batch, error) {
+	if id == "" {
+		return nil, customerror.NewRequiredError("id")
+	}
+
+	u, err := batchesURL(p.Endpoint, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody Batch
+
+	resp, err := p.client.Get(
+		ctx,
+		u,
+		httpclient.WithHeader("x-api-key", p.Token),
+		httpclient.WithHeader("anthropic-version", "2023-06-01"),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return &respBody, nil
+}
+
+// toJob converts the API batch to a job. Ended batches are completed, even
+// if some requests were canceled, or expired: they are counted as failed, and
+// so are their results.
+func (b Batch) toJob() *batch.Job {
+	c := b.RequestCounts
+
+	status := batch.StatusInProgress
+
+	if b.ProcessingStatus == BatchStatusEnded {
+		status = batch.StatusCompleted
+	}
+
+	return &batch.Job{
+		CreatedAt: b.CreatedAt,
+		Counts: batch.Counts{
+			Failed:    c.Errored + c.Canceled + c.Expired,
+			Succeeded: c.Succeeded,
+			Total:     c.Processing + c.Succeeded + c.Errored + c.Canceled + c.Expired,
+		},
+		ID:       b.ID,
+		Provider: Name,
+		Status:   status,
+	}
+}
+
+// toResult converts the result line to a result.
+func (l BatchResultLine) toResult() batch.Result {
+	result := batch.Result{ID: l.CustomID}
+
+	switch {
+	case l.Result.Type == BatchResultSucceeded && l.Result.Message != nil:
+		response, err := ProcessResponse(*l.Result.Message)
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		result.Response = response
+		result.Usage = l.Result.Message.Usage.ToUsage()
+		result.Usage.Cost = provider.UsageCost(Name, l.Result.Message.Model, result.Usage) * batch.Discount
+	case l.Result.Error != nil:
+		result.Error = l.Result.Error.Error.Message
+	default:
+		result.Error = l.Result.Type
+	}
+
+	return result
+}
+
+// batchesURL derives the message batches API URL from the messages one, e.g.:
+// `https://api.anthropic.com/v1/messages` ->
+// `https://api.anthropic.com/v1/messages/batches`.
+func batchesURL(endpoint string, elem ...string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", customerror.NewInvalidError("endpoint", customerror.WithError(err))
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/batches"
+
+	return u.JoinPath(elem...).String(), nil
+}
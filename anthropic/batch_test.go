@@ -0,0 +1,125 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/batch"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestBatch(t *testing.T) {
+	var polls atomic.Int32
+
+	var server *httptest.Server
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/messages/batches", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "test", r.Header.Get("x-api-key"))
+
+		var reqBody BatchRequestBody
+
+		_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+		assert.Len(t, reqBody.Requests, 3)
+		assert.Equal(t, "sky", reqBody.Requests[0].CustomID)
+		assert.Equal(t, "claude-3-5-haiku-20241022", reqBody.Requests[0].Params.Model)
+		assert.Equal(t, "be brief", reqBody.Requests[0].Params.System[0].Text)
+
+		_, _ = w.Write([]byte(`{
+			"id": "msgbatch_1",
+			"processing_status": "in_progress",
+			"created_at": "2024-09-24T18:37:24.100435Z",
+			"request_counts": {"processing": 3}
+		}`))
+	})
+
+	mux.HandleFunc("GET /v1/messages/batches/msgbatch_1", func(w http.ResponseWriter, _ *http.Request) {
+		if polls.Add(1) < 3 {
+			_, _ = w.Write([]byte(`{"id": "msgbatch_1", "processing_status": "in_progress", "request_counts": {"processing": 3}}`))
+
+			return
+		}
+
+		fmt.Fprintf(w, `{
+			"id": "msgbatch_1",
+			"processing_status": "ended",
+			"request_counts": {"succeeded": 1, "errored": 1, "expired": 1},
+			"results_url": "%s/v1/messages/batches/msgbatch_1/results"
+		}`, server.URL)
+	})
+
+	mux.HandleFunc("GET /v1/messages/batches/msgbatch_1/results", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"custom_id": "moon", "result": {"type": "expired"}}`)
+		fmt.Fprintln(w, `{"custom_id": "sea", "result": {"type": "errored", "error": {"type": "error", `+
+			`"error": {"type": "invalid_request_error", "message": "max_tokens: too large"}}}}`)
+		fmt.Fprintln(w, `{"custom_id": "sky", "result": {"type": "succeeded", "message": {`+
+			`"model": "claude-3-5-haiku-20241022", "content": [{"type": "text", "text": "Blue"}], `+
+			`"usage": {"input_tokens": 1000000, "output_tokens": 1000000}}}}`)
+	})
+
+	mux.HandleFunc("POST /v1/messages/batches/msgbatch_1/cancel", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "msgbatch_1", "processing_status": "canceling"}`))
+	})
+
+	server = httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := New(
+		provider.WithEndpoint(server.URL+"/v1/messages"),
+		provider.WithToken("test"),
+		provider.WithDefaulModel("claude-3-5-haiku-20241022"),
+	)
+	assert.NoError(t, err)
+
+	requests := []batch.Request{
+		{ID: "sky", Options: []provider.Func{
+			provider.WithSystemMessages("be brief"),
+			provider.WithUserMessages("What's the color of the sky?"),
+		}},
+		{ID: "sea", Options: []provider.Func{provider.WithUserMessages("Why is the sea salty?")}},
+		{ID: "moon", Options: []provider.Func{provider.WithUserMessages("How far is the moon?")}},
+	}
+
+	var last batch.Job
+
+	results, err := batch.Run(
+		context.Background(),
+		p,
+		requests,
+		batch.WithPollInterval(time.Millisecond),
+		batch.WithProgress(func(job batch.Job) { last = job }),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, batch.StatusCompleted, last.Status)
+	assert.Equal(t, batch.Counts{Failed: 2, Succeeded: 1, Total: 3}, last.Counts)
+
+	assert.Equal(t, "Blue", results[0].Response)
+	assert.Equal(t, 1000000, results[0].Usage.OutputTokens)
+
+	// Half of 0.8, and 4 per million.
+	assert.InDelta(t, 2.4, results[0].Usage.Cost, 1e-9)
+
+	assert.Equal(t, batch.Result{Error: "max_tokens: too large", ID: "sea"}, results[1])
+	assert.Equal(t, batch.Result{Error: "expired", ID: "moon"}, results[2])
+
+	job, err := p.CancelBatch(context.Background(), "msgbatch_1")
+	assert.NoError(t, err)
+	assert.Equal(t, batch.StatusInProgress, job.Status)
+
+	// Errors.
+	_, err = p.SubmitBatch(context.Background(), nil)
+	assert.Error(t, err)
+
+	_, err = p.GetBatch(context.Background(), "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,227 @@
+package anthropic
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Breakpoint is where the prompt is cached up to.
+type Breakpoint string
+
+// Available breakpoints.
+const (
+	// BreakpointSystem caches the system prompt.
+	BreakpointSystem Breakpoint = "system"
+
+	// BreakpointHistory caches the system prompt, and the history, e.g.: of a
+	// multi-turn conversation.
+	BreakpointHistory Breakpoint = "history"
+
+	// BreakpointMessages caches the whole prompt, e.g.: large documents sent
+	// as user messages, and asked about repeatedly.
+	BreakpointMessages Breakpoint = "messages"
+)
+
+// MinThinkingBudget is the minimum thinking budget, in tokens.
+const MinThinkingBudget = 1024
+
+// minThinkingTopP is the minimum top P allowed with thinking.
+const minThinkingTopP = 0.95
+
+// Extra are the Anthropic-specific options. Zero values are not sent, so the
+// API defaults apply.
+type Extra struct {
+	// CacheBreakpoints where the prompt is cached up to. Set through
+	// WithCacheBreakpoint.
+	CacheBreakpoints []Breakpoint `json:"cacheBreakpoints,omitempty"`
+
+	// Metadata about the request.
+	Metadata *Metadata `json:"metadata,omitempty"`
+
+	// Thinking enables extended thinking. Set through WithThinking.
+	Thinking *Thinking `json:"thinking,omitempty"`
+
+	// ThinkingBlocks are sent back before the text of the last assistant
+	// message of the history. Set through WithThinkingBlocks.
+	ThinkingBlocks []Block `json:"thinkingBlocks,omitempty"`
+
+	// Thought receives the thinking of the response. Set through
+	// WithThinking.
+	Thought *Thought `json:"-"`
+}
+
+// Thought is the thinking of a response.
+type Thought struct {
+	// Blocks are the thinking, and redacted thinking blocks, in order. Send
+	// them back with the assistant message through WithThinkingBlocks, as
+	// required when continuing a turn, e.g.: with tool results.
+	Blocks []Block `json:"blocks,omitempty"`
+
+	// Text of the thinking blocks, joined. Redacted thinking is encrypted,
+	// thus excluded.
+	Text string `json:"text,omitempty"`
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithExtra sets the Anthropic-specific options. Other providers ignore them.
+func WithExtra(extra Extra) provider.Func {
+	return provider.WithExtra(Name, extra)
+}
+
+// WithCacheBreakpoint caches the prompt up to the breakpoints, with
+// `cache_control: {type: ephemeral}`. Default to BreakpointSystem. It merges
+// with the Anthropic-specific options set before, but WithExtra set after
+// replaces it. Other providers ignore it.
+//
+// NOTE: Prompts shorter than the model's minimum cacheable length, e.g.: 1024
+// tokens, are not cached.
+func WithCacheBreakpoint(breakpoints ...Breakpoint) provider.Func {
+	return func(o *provider.Options) error {
+		if len(breakpoints) == 0 {
+			breakpoints = []Breakpoint{BreakpointSystem}
+		}
+
+		extra, _, err := provider.GetExtra[Extra](o, Name)
+		if err != nil {
+			return err
+		}
+
+		for _, b := range breakpoints {
+			switch b {
+			case BreakpointSystem, BreakpointHistory, BreakpointMessages:
+			default:
+				return customerror.NewInvalidError(fmt.Sprintf("cache breakpoint %s", b))
+			}
+
+			if !slices.Contains(extra.CacheBreakpoints, b) {
+				extra.CacheBreakpoints = append(extra.CacheBreakpoints, b)
+			}
+		}
+
+		return provider.WithExtra(Name, extra)(o)
+	}
+}
+
+// WithThinking enables extended thinking, using up to `budgetTokens` tokens,
+// at least 1024, and less than MaxTokens. If `thought` isn't nil, it receives
+// the thinking, the completion is the answer only. It merges with the
+// Anthropic-specific options set before. Other providers ignore it.
+//
+// NOTE: As the API requires, temperature, and top K aren't sent, and top P
+// only if between 0.95, and 1.
+func WithThinking(budgetTokens int, thought *Thought) provider.Func {
+	return func(o *provider.Options) error {
+		if budgetTokens < MinThinkingBudget {
+			return customerror.NewInvalidError(
+				fmt.Sprintf("thinking budget %d, must be at least %d", budgetTokens, MinThinkingBudget),
+			)
+		}
+
+		extra, _, err := provider.GetExtra[Extra](o, Name)
+		if err != nil {
+			return err
+		}
+
+		extra.Thinking = &Thinking{BudgetTokens: budgetTokens, Type: ThinkingTypeEnabled}
+		extra.Thought = thought
+
+		return provider.WithExtra(Name, extra)(o)
+	}
+}
+
+// WithThinkingBlocks sends the thinking, and redacted thinking blocks back,
+// unmodified, before the text of the last assistant message of the history,
+// e.g.: Thought.Blocks of its response. It merges with the Anthropic-specific
+// options set before. Other providers ignore it.
+func WithThinkingBlocks(blocks ...Block) provider.Func {
+	return func(o *provider.Options) error {
+		for _, b := range blocks {
+			if b.Type != BlockTypeThinking && b.Type != BlockTypeRedactedThinking {
+				return customerror.NewInvalidError(fmt.Sprintf("thinking block type %s", b.Type))
+			}
+		}
+
+		extra, _, err := provider.GetExtra[Extra](o, Name)
+		if err != nil {
+			return err
+		}
+
+		extra.ThinkingBlocks = blocks
+
+		return provider.WithExtra(Name, extra)(o)
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// apply merges the options into the request body. The first `historyLen`
+// messages are the history.
+func (e Extra) apply(reqBody *RequestBody, historyLen int) error {
+	reqBody.Metadata = e.Metadata
+
+	if e.Thinking != nil {
+		if e.Thinking.BudgetTokens >= reqBody.MaxTokens {
+			return customerror.NewInvalidError(fmt.Sprintf(
+				"thinking budget %d, must be less than max tokens %d", e.Thinking.BudgetTokens, reqBody.MaxTokens,
+			))
+		}
+
+		reqBody.Thinking = e.Thinking
+		reqBody.Temperature = 0
+		reqBody.TopK = 0
+
+		if reqBody.TopP < minThinkingTopP {
+			reqBody.TopP = 0
+		}
+	}
+
+	if len(e.ThinkingBlocks) > 0 {
+		if err := withThinkingBlocks(reqBody.Messages[:historyLen], e.ThinkingBlocks); err != nil {
+			return err
+		}
+	}
+
+	for _, b := range e.CacheBreakpoints {
+		switch b {
+		case BreakpointSystem:
+			Blocks(reqBody.System).cache()
+		case BreakpointHistory:
+			if historyLen > 0 {
+				reqBody.Messages[historyLen-1].Content.cache()
+			}
+		case BreakpointMessages:
+			if len(reqBody.Messages) > 0 {
+				reqBody.Messages[len(reqBody.Messages)-1].Content.cache()
+			}
+		}
+	}
+
+	return nil
+}
+
+// withThinkingBlocks prepends the thinking blocks to the last assistant
+// message of the history.
+func withThinkingBlocks(history []Message, blocks []Block) error {
+	for i := len(history) - 1; i >= 0; i-- {
+		if history[i].Role == message.Assistant {
+			history[i].Content = append(slices.Clone(blocks), history[i].Content...)
+
+			return nil
+		}
+	}
+
+	return customerror.NewRequiredError("assistant message in the history, to send the thinking blocks with")
+}
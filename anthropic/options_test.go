@@ -0,0 +1,234 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestWithCacheBreakpoint(t *testing.T) {
+	a := &Anthropic{Provider: &provider.Provider{DefaultModel: "claude-3-5-haiku-20241022"}}
+
+	options := []provider.Func{
+		provider.WithSystemMessages("You answer questions about the document."),
+		provider.WithHistory(
+			message.Message{Content: "What's it about?", Role: message.User},
+			message.Message{Content: "Caching.", Role: message.Assistant},
+		),
+		provider.WithUserMessages("Summarize it."),
+	}
+
+	t.Run("Default", func(t *testing.T) {
+		reqBody, _, err := a.BuildRequestBody(context.Background(), append(options,
+			WithExtra(Extra{Metadata: &Metadata{UserID: "user-1234"}}),
+			WithCacheBreakpoint(),
+		)...)
+		assert.NoError(t, err)
+
+		// Merged with the extra set before.
+		assert.Equal(t, &Metadata{UserID: "user-1234"}, reqBody.Metadata)
+
+		data, err := json.Marshal(reqBody)
+		assert.NoError(t, err)
+
+		var body map[string]any
+
+		assert.NoError(t, json.Unmarshal(data, &body))
+
+		assert.Equal(t, []any{map[string]any{
+			"cache_control": map[string]any{"type": "ephemeral"},
+			"text":          "You answer questions about the document.",
+			"type":          "text",
+		}}, body["system"])
+
+		// Messages without breakpoints are plain strings.
+		assert.Equal(t, map[string]any{"content": "Summarize it.", "role": "user"}, body["messages"].([]any)[2])
+	})
+
+	t.Run("History, and messages", func(t *testing.T) {
+		reqBody, _, err := a.BuildRequestBody(context.Background(), append(options,
+			WithCacheBreakpoint(BreakpointHistory),
+			WithCacheBreakpoint(BreakpointMessages, BreakpointHistory),
+		)...)
+		assert.NoError(t, err)
+
+		assert.Nil(t, reqBody.System[0].CacheControl)
+		assert.Nil(t, reqBody.Messages[0].Content[0].CacheControl)
+		assert.Equal(t, &CacheControl{Type: CacheControlEphemeral}, reqBody.Messages[1].Content[0].CacheControl)
+		assert.Equal(t, &CacheControl{Type: CacheControlEphemeral}, reqBody.Messages[2].Content[0].CacheControl)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		_, _, err := a.BuildRequestBody(context.Background(), append(options, WithCacheBreakpoint("tools"))...)
+		assert.Error(t, err)
+	})
+}
+
+func TestBlocks_UnmarshalJSON(t *testing.T) {
+	var m Message
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"content":"hi","role":"user"}`), &m))
+	assert.Equal(t, Blocks{{Text: "hi", Type: BlockTypeText}}, m.Content)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"content":[{"type":"text","text":"a"},{"type":"text","text":"b"}],"role":"user"}`), &m))
+	assert.Len(t, m.Content, 2)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"content":1,"role":"user"}`), &m))
+}
+
+func TestCompletion_promptCaching(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{
+			"content": [{"type": "text", "text": "Blue"}],
+			"id": "msg_1",
+			"model": "claude-3-5-haiku-20241022",
+			"role": "assistant",
+			"stop_reason": "end_turn",
+			"type": "message",
+			"usage": {
+				"cache_creation_input_tokens": 1000,
+				"cache_read_input_tokens": 2000,
+				"input_tokens": 10,
+				"output_tokens": 4
+			}
+		}`))
+	}))
+	defer server.Close()
+
+	p, err := New(
+		provider.WithEndpoint(server.URL+"/v1/messages"),
+		provider.WithToken("test"),
+		provider.WithDefaulModel("claude-3-5-haiku-20241022"),
+	)
+	assert.NoError(t, err)
+
+	var usage provider.Usage
+
+	_, err = p.Completion(
+		context.Background(),
+		provider.WithSystemMessages("You answer questions about the document."),
+		provider.WithUserMessages("What's the color of the sky?"),
+		WithCacheBreakpoint(),
+		provider.WithUsage(&usage),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1000, usage.CacheCreationInputTokens)
+	assert.Equal(t, 2000, usage.CacheReadInputTokens)
+	assert.Equal(t, 3010, usage.InputTokens)
+	assert.Equal(t, 4, usage.OutputTokens)
+
+	// 10 * 0.8, 1000 * 1, 2000 * 0.08, and 4 * 4, per million.
+	assert.InDelta(t, 0.001184, usage.Cost, 1e-9)
+}
+
+func TestWithThinking(t *testing.T) {
+	a := &Anthropic{Provider: &provider.Provider{DefaultModel: "claude-3-7-sonnet-20250219"}}
+
+	thinking := Block{Signature: "sig", Thinking: "The sky is blue.", Type: BlockTypeThinking}
+	redacted := Block{Data: "encrypted", Type: BlockTypeRedactedThinking}
+
+	options := []provider.Func{
+		provider.WithMaxToken(4096),
+		provider.WithTopK(40),
+		provider.WithHistory(
+			message.Message{Content: "What's the color of the sky?", Role: message.User},
+			message.Message{Content: "Blue.", Role: message.Assistant},
+		),
+		provider.WithUserMessages("Why?"),
+	}
+
+	reqBody, _, err := a.BuildRequestBody(context.Background(), append(options,
+		WithThinking(2048, nil),
+		WithThinkingBlocks(thinking, redacted),
+		WithCacheBreakpoint(BreakpointHistory),
+	)...)
+	assert.NoError(t, err)
+
+	assert.Equal(t, &Thinking{BudgetTokens: 2048, Type: ThinkingTypeEnabled}, reqBody.Thinking)
+	assert.Zero(t, reqBody.Temperature)
+	assert.Zero(t, reqBody.TopK)
+
+	// Thinking blocks go before the text of the assistant message, which is
+	// the cache breakpoint.
+	data, err := json.Marshal(reqBody.Messages[1])
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{
+		"content": [
+			{"signature": "sig", "thinking": "The sky is blue.", "type": "thinking"},
+			{"data": "encrypted", "type": "redacted_thinking"},
+			{"cache_control": {"type": "ephemeral"}, "text": "Blue.", "type": "text"}
+		],
+		"role": "assistant"
+	}`, string(data))
+
+	// Errors.
+	_, _, err = a.BuildRequestBody(context.Background(), append(options, WithThinking(512, nil))...)
+	assert.Error(t, err)
+
+	_, _, err = a.BuildRequestBody(context.Background(), append(options, WithThinking(4096, nil))...)
+	assert.Error(t, err)
+
+	_, _, err = a.BuildRequestBody(context.Background(), append(options, WithThinkingBlocks(Block{Text: "hi", Type: BlockTypeText}))...)
+	assert.Error(t, err)
+
+	_, _, err = a.BuildRequestBody(context.Background(), provider.WithUserMessages("Why?"), WithThinkingBlocks(thinking))
+	assert.Error(t, err)
+}
+
+func TestCompletion_thinking(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reqBody RequestBody
+
+		_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+		assert.Equal(t, &Thinking{BudgetTokens: 1024, Type: ThinkingTypeEnabled}, reqBody.Thinking)
+
+		_, _ = w.Write([]byte(`{
+			"content": [
+				{"type": "thinking", "thinking": "Rayleigh scattering.", "signature": "sig"},
+				{"type": "redacted_thinking", "data": "encrypted"},
+				{"type": "text", "text": "Blue, "},
+				{"type": "text", "text": "because of scattering."}
+			],
+			"id": "msg_1",
+			"model": "claude-3-7-sonnet-20250219",
+			"role": "assistant",
+			"stop_reason": "end_turn",
+			"type": "message",
+			"usage": {"input_tokens": 10, "output_tokens": 40}
+		}`))
+	}))
+	defer server.Close()
+
+	p, err := New(
+		provider.WithEndpoint(server.URL+"/v1/messages"),
+		provider.WithToken("test"),
+		provider.WithDefaulModel("claude-3-7-sonnet-20250219"),
+	)
+	assert.NoError(t, err)
+
+	var thought Thought
+
+	response, err := p.Completion(
+		context.Background(),
+		provider.WithMaxToken(2048),
+		provider.WithUserMessages("What's the color of the sky?"),
+		WithThinking(1024, &thought),
+	)
+	assert.NoError(t, err)
+
+	// The thinking is excluded from the answer.
+	assert.Equal(t, "Blue, because of scattering.", response)
+	assert.Equal(t, "Rayleigh scattering.", thought.Text)
+	assert.Equal(t, []Block{
+		{Signature: "sig", Thinking: "Rayleigh scattering.", Type: BlockTypeThinking},
+		{Data: "encrypted", Type: BlockTypeRedactedThinking},
+	}, thought.Blocks)
+}
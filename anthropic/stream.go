@@ -0,0 +1,150 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Types of stream events.
+const (
+	EventContentBlockDelta = "content_block_delta"
+	EventContentBlockStart = "content_block_start"
+	EventError             = "error"
+	EventMessageDelta      = "message_delta"
+	EventMessageStart      = "message_start"
+)
+
+// Types of stream deltas.
+const (
+	DeltaTypeSignature = "signature_delta"
+	DeltaTypeText      = "text_delta"
+	DeltaTypeThinking  = "thinking_delta"
+)
+
+//////
+// Helpers.
+//////
+
+// post posts the request body, and decodes the response body.
+func (p *Anthropic) post(ctx context.Context, reqBody *RequestBody, respBody *ResponseBody) error {
+	resp, err := p.client.Post(
+		ctx,
+		p.Endpoint,
+		httpclient.WithHeader("x-api-key", p.Token),
+		httpclient.WithHeader("anthropic-version", "2023-06-01"),
+		httpclient.WithReqBody(reqBody),
+		httpclient.WithRespBody(respBody),
+	)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// stream posts the request body, calls the handler with each chunk of text of
+// the streamed response, as server-sent events, and returns it assembled,
+// thinking included.
+func (p *Anthropic) stream(
+	ctx context.Context,
+	reqBody *RequestBody,
+	handler provider.StreamFunc,
+) (ResponseBody, error) {
+	respBody := ResponseBody{}
+
+	resp, err := provider.PostStream(
+		ctx,
+		p.client,
+		p.Endpoint,
+		map[string]string{"x-api-key": p.Token, "anthropic-version": "2023-06-01"},
+		reqBody,
+	)
+	if err != nil {
+		return respBody, err
+	}
+
+	defer resp.Body.Close()
+
+	if err := provider.ReadEvents(resp.Body, func(_ string, data []byte) error {
+		var event StreamEvent
+
+		if err := json.Unmarshal(data, &event); err != nil {
+			return customerror.NewFailedToError("decode stream event", customerror.WithError(err))
+		}
+
+		switch event.Type {
+		case EventMessageStart:
+			if event.Message != nil {
+				respBody = *event.Message
+			}
+		case EventContentBlockStart:
+			if event.ContentBlock != nil && event.Index == len(respBody.Content) {
+				respBody.Content = append(respBody.Content, *event.ContentBlock)
+			}
+		case EventContentBlockDelta:
+			if event.Delta == nil || event.Index < 0 || event.Index >= len(respBody.Content) {
+				return nil
+			}
+
+			block := &respBody.Content[event.Index]
+
+			switch event.Delta.Type {
+			case DeltaTypeText:
+				block.Text += event.Delta.Text
+
+				return handler(event.Delta.Text)
+			case DeltaTypeThinking:
+				block.Thinking += event.Delta.Thinking
+			case DeltaTypeSignature:
+				block.Signature += event.Delta.Signature
+			}
+		case EventMessageDelta:
+			if event.Delta != nil && event.Delta.StopReason != "" {
+				respBody.StopReason = event.Delta.StopReason
+			}
+
+			if event.Usage != nil {
+				respBody.Usage.merge(*event.Usage)
+			}
+		case EventError:
+			if event.Error != nil {
+				return customerror.New(event.Error.Message, customerror.WithField("type", event.Error.Type))
+			}
+
+			return customerror.New("stream error")
+		}
+
+		return nil
+	}); err != nil {
+		return respBody, err
+	}
+
+	return respBody, nil
+}
+
+// merge merges the non-zero counts of the cumulative usage.
+func (u *Usage) merge(other Usage) {
+	if other.CacheCreationInputTokens > 0 {
+		u.CacheCreationInputTokens = other.CacheCreationInputTokens
+	}
+
+	if other.CacheReadInputTokens > 0 {
+		u.CacheReadInputTokens = other.CacheReadInputTokens
+	}
+
+	if other.InputTokens > 0 {
+		u.InputTokens = other.InputTokens
+	}
+
+	if other.OutputTokens > 0 {
+		u.OutputTokens = other.OutputTokens
+	}
+}
@@ -0,0 +1,89 @@
+package anthropic
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/message"
+)
+
+//////
+// Token counting.
+//////
+
+// CountTokens counts the input tokens of the messages using the API, without
+// creating a message. System messages are sent as the system prompt, in order,
+// as in completions. It implements the `tokenizer.Counter` interface.
+func (p *Anthropic) CountTokens(ctx context.Context, model string, messages []message.Message) (int, error) {
+	if model == "" {
+		model = p.DefaultModel
+	}
+
+	if model == "" {
+		return 0, customerror.NewRequiredError("model")
+	}
+
+	u, err := countTokensURL(p.Endpoint)
+	if err != nil {
+		return 0, err
+	}
+
+	reqBody := CountTokensRequestBody{Model: model}
+	system := []string{}
+	conversation := []message.Message{}
+
+	for _, m := range messages {
+		if m.Role == message.System {
+			system = append(system, m.Content)
+
+			continue
+		}
+
+		conversation = append(conversation, m)
+	}
+
+	reqBody.Messages = ToMessages(conversation)
+
+	if len(system) > 0 {
+		reqBody.System = textBlocks(system)
+	}
+
+	var respBody CountTokensResponseBody
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithHeader("x-api-key", p.Token),
+		httpclient.WithHeader("anthropic-version", "2023-06-01"),
+		httpclient.WithReqBody(reqBody),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	defer resp.Body.Close()
+
+	return respBody.InputTokens, nil
+}
+
+//////
+// Helpers.
+//////
+
+// countTokensURL derives the token counting API URL from the messages one,
+// e.g.: `https://api.anthropic.com/v1/messages` ->
+// `https://api.anthropic.com/v1/messages/count_tokens`.
+func countTokensURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", customerror.NewInvalidError("endpoint", customerror.WithError(err))
+	}
+
+	u.Path = strings.TrimSuffix(u.Path, "/") + "/count_tokens"
+
+	return u.String(), nil
+}
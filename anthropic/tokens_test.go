@@ -0,0 +1,45 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestCountTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/messages/count_tokens", r.URL.Path)
+		assert.Equal(t, "test", r.Header.Get("x-api-key"))
+
+		var reqBody CountTokensRequestBody
+
+		_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+		assert.Equal(t, "claude-3-5-haiku-20241022", reqBody.Model)
+		assert.Equal(t, []Block{
+			{Text: "be brief", Type: BlockTypeText},
+			{Text: "be kind", Type: BlockTypeText},
+		}, reqBody.System)
+		assert.Equal(t, []Message{{Content: Blocks{{Text: "hi", Type: BlockTypeText}}, Role: message.User}}, reqBody.Messages)
+
+		_ = json.NewEncoder(w).Encode(CountTokensResponseBody{InputTokens: 14})
+	}))
+	defer server.Close()
+
+	p, err := New(
+		provider.WithEndpoint(server.URL+"/v1/messages"),
+		provider.WithToken("test"),
+		provider.WithDefaulModel("claude-3-5-haiku-20241022"),
+	)
+	assert.NoError(t, err)
+
+	tokens, err := p.CountTokens(context.Background(), "", message.NewMessages([]string{"be brief", "be kind"}, []string{"hi"}))
+	assert.NoError(t, err)
+	assert.Equal(t, 14, tokens)
+}
@@ -1,41 +1,115 @@
 package anthropic
 
-import "github.com/thalesfsp/inference/message"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+)
 
 //////
 // Const, vars, types.
 //////
 
+// Types of content blocks, cache control, and thinking.
+const (
+	BlockTypeRedactedThinking = "redacted_thinking"
+	BlockTypeText             = "text"
+	BlockTypeThinking         = "thinking"
+	CacheControlEphemeral     = "ephemeral"
+	ThinkingTypeEnabled       = "enabled"
+)
+
+// Stop reasons of the responses.
+const (
+	StopReasonEndTurn      = "end_turn"
+	StopReasonMaxTokens    = "max_tokens"
+	StopReasonRefusal      = "refusal"
+	StopReasonStopSequence = "stop_sequence"
+	StopReasonToolUse      = "tool_use"
+)
+
 //////
 // Request body.
 
+// CacheControl marks a cache breakpoint: the prompt up to, and including the
+// block is cached.
+type CacheControl struct {
+	Type string `json:"type"`
+}
+
+// Block is a content block: text, thinking, or redacted thinking.
+type Block struct {
+	CacheControl *CacheControl `json:"cache_control,omitempty"`
+	Text         string        `json:"text,omitempty"`
+	Type         string        `json:"type"`
+
+	// Data is the encrypted thinking of redacted thinking blocks.
+	Data string `json:"data,omitempty"`
+
+	// Signature verifies the thinking of thinking blocks.
+	Signature string `json:"signature,omitempty"`
+
+	// Thinking is the thinking text of thinking blocks.
+	Thinking string `json:"thinking,omitempty"`
+}
+
+// Blocks are content blocks. A single text block, without cache control, is
+// sent as a plain string.
+type Blocks []Block
+
+// Message of the conversation.
+type Message struct {
+	Content Blocks `json:"content"`
+	Role    string `json:"role"`
+}
+
+// Metadata about the request.
+type Metadata struct {
+	// UserID is an external identifier for the user associated with the
+	// request, e.g.: a UUID, or hash value. Do not include identifying
+	// information such as name, email, or phone number.
+	UserID string `json:"user_id,omitempty"`
+}
+
+// Thinking configures extended thinking.
+type Thinking struct {
+	// BudgetTokens is the maximum number of tokens used to think, part of
+	// MaxTokens.
+	BudgetTokens int    `json:"budget_tokens"`
+	Type         string `json:"type"`
+}
+
 // RequestBody represents the request body for the API.
 type RequestBody struct {
-	Messages []message.Message `json:"messages"`
-	Model    string            `json:"model"`
-	Stream   bool              `json:"stream"`
+	Messages []Message `json:"messages"`
+	Model    string    `json:"model"`
+	Stream   bool      `json:"stream"`
 
-	MaxTokens   int     `json:"max_tokens,omitempty"`
-	System      string  `json:"system,omitempty"`
-	Temperature float64 `json:"temperature,omitempty"`
-	TopK        int     `json:"top_k,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
+	MaxTokens     int       `json:"max_tokens,omitempty"`
+	Metadata      *Metadata `json:"metadata,omitempty"`
+	StopSequences []string  `json:"stop_sequences,omitempty"`
+	System        []Block   `json:"system,omitempty"`
+	Temperature   float64   `json:"temperature,omitempty"`
+	Thinking      *Thinking `json:"thinking,omitempty"`
+	TopK          int       `json:"top_k,omitempty"`
+	TopP          float64   `json:"top_p,omitempty"`
 }
 
 //////
 // Response body.
 
-// Usage definition.
+// Usage definition. InputTokens excludes the tokens written to, and read from
+// the prompt cache.
 type Usage struct {
-	InputTokens  int `json:"input_tokens"`
-	OutputTokens int `json:"output_tokens"`
+	CacheCreationInputTokens int `json:"cache_creation_input_tokens,omitempty"`
+	CacheReadInputTokens     int `json:"cache_read_input_tokens,omitempty"`
+	InputTokens              int `json:"input_tokens"`
+	OutputTokens             int `json:"output_tokens"`
 }
 
-// Content definition.
-type Content struct {
-	Text string `json:"text"`
-	Type string `json:"type"`
-}
+// Content is a content block of the response.
+type Content = Block
 
 // ResponseBody represents the response body from the API.
 type ResponseBody struct {
@@ -48,3 +122,152 @@ type ResponseBody struct {
 	Type         string    `json:"type"`
 	Usage        Usage     `json:"usage"`
 }
+
+//////
+// Token counting.
+
+// CountTokensRequestBody represents the request body of the token counting
+// API.
+type CountTokensRequestBody struct {
+	Messages []Message `json:"messages"`
+	Model    string    `json:"model"`
+	System   []Block   `json:"system,omitempty"`
+}
+
+// CountTokensResponseBody represents the response body of the token counting
+// API.
+type CountTokensResponseBody struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+//////
+// Methods.
+//////
+
+// MarshalJSON implements the json.Marshaler interface.
+func (b Blocks) MarshalJSON() ([]byte, error) {
+	if len(b) == 1 && b[0].Type == BlockTypeText && b[0].CacheControl == nil {
+		return json.Marshal(b[0].Text)
+	}
+
+	return json.Marshal([]Block(b))
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (b *Blocks) UnmarshalJSON(data []byte) error {
+	var text string
+
+	if err := json.Unmarshal(data, &text); err == nil {
+		*b = Blocks{{Text: text, Type: BlockTypeText}}
+
+		return nil
+	}
+
+	var blocks []Block
+
+	if err := json.Unmarshal(data, &blocks); err != nil {
+		return customerror.NewFailedToError("unmarshal content blocks", customerror.WithError(err))
+	}
+
+	*b = blocks
+
+	return nil
+}
+
+// textBlocks returns a text block per text, in order.
+func textBlocks(texts []string) []Block {
+	blocks := make([]Block, 0, len(texts))
+
+	for _, text := range texts {
+		blocks = append(blocks, Block{Text: text, Type: BlockTypeText})
+	}
+
+	return blocks
+}
+
+// cache marks the last block as a cache breakpoint.
+func (b Blocks) cache() {
+	if len(b) > 0 {
+		b[len(b)-1].CacheControl = &CacheControl{Type: CacheControlEphemeral}
+	}
+}
+
+//////
+// Batch.
+
+// BatchRequest is a request of a batch.
+type BatchRequest struct {
+	CustomID string       `json:"custom_id"`
+	Params   *RequestBody `json:"params"`
+}
+
+// BatchRequestBody represents the request body of the message batches API.
+type BatchRequestBody struct {
+	Requests []BatchRequest `json:"requests"`
+}
+
+// BatchRequestCounts Anthropic API definition.
+type BatchRequestCounts struct {
+	Canceled   int `json:"canceled"`
+	Errored    int `json:"errored"`
+	Expired    int `json:"expired"`
+	Processing int `json:"processing"`
+	Succeeded  int `json:"succeeded"`
+}
+
+// Batch Anthropic API definition.
+type Batch struct {
+	CreatedAt        time.Time          `json:"created_at"`
+	ID               string             `json:"id"`
+	ProcessingStatus string             `json:"processing_status"`
+	RequestCounts    BatchRequestCounts `json:"request_counts"`
+	ResultsURL       string             `json:"results_url,omitempty"`
+}
+
+// Error Anthropic API definition.
+type Error struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// ErrorResponse Anthropic API definition.
+type ErrorResponse struct {
+	Error Error  `json:"error"`
+	Type  string `json:"type"`
+}
+
+// BatchResult Anthropic API definition.
+type BatchResult struct {
+	Error   *ErrorResponse `json:"error,omitempty"`
+	Message *ResponseBody  `json:"message,omitempty"`
+	Type    string         `json:"type"`
+}
+
+// BatchResultLine is a line of the batch results.
+type BatchResultLine struct {
+	CustomID string      `json:"custom_id"`
+	Result   BatchResult `json:"result"`
+}
+
+//////
+// Streaming.
+
+// StreamDelta is the delta of a streamed content block, or message.
+type StreamDelta struct {
+	Signature  string `json:"signature,omitempty"`
+	StopReason string `json:"stop_reason,omitempty"`
+	Text       string `json:"text,omitempty"`
+	Thinking   string `json:"thinking,omitempty"`
+	Type       string `json:"type,omitempty"`
+}
+
+// StreamEvent is an event of the streamed response.
+type StreamEvent struct {
+	ContentBlock *Block        `json:"content_block,omitempty"`
+	Delta        *StreamDelta  `json:"delta,omitempty"`
+	Error        *Error        `json:"error,omitempty"`
+	Index        int           `json:"index"`
+	Message      *ResponseBody `json:"message,omitempty"`
+	Type         string        `json:"type"`
+	Usage        *Usage        `json:"usage,omitempty"`
+}
@@ -5,18 +5,93 @@ import (
 	"strings"
 
 	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
 )
 
-// ProcessResponse processes the response from the API.
+// ProcessResponse processes the response from the API. It returns the text
+// blocks, joined. Thinking is excluded, see ProcessThinking.
 func ProcessResponse(resp ResponseBody) (string, error) {
+	var b strings.Builder
+
+	for _, content := range resp.Content {
+		if content.Type == BlockTypeText {
+			b.WriteString(content.Text)
+		}
+	}
+
+	if len(strings.TrimSpace(b.String())) == 0 {
+		return "", customerror.New(
+			"no content",
+			customerror.WithStatusCode(http.StatusNoContent),
+		)
+	}
+
+	return b.String(), nil
+}
+
+// ProcessThinking returns the thinking of the response, if any.
+func ProcessThinking(resp ResponseBody) Thought {
+	var thought Thought
+
+	texts := []string{}
+
 	for _, content := range resp.Content {
-		if len(strings.TrimSpace(content.Text)) != 0 {
-			return content.Text, nil
+		switch content.Type {
+		case BlockTypeThinking:
+			texts = append(texts, content.Thinking)
+		case BlockTypeRedactedThinking:
+		default:
+			continue
 		}
+
+		thought.Blocks = append(thought.Blocks, content)
+	}
+
+	thought.Text = strings.Join(texts, "\n\n")
+
+	return thought
+}
+
+// ToUsage converts the API usage to the provider usage. Input tokens include
+// the ones written to, and read from the prompt cache.
+func (u Usage) ToUsage() provider.Usage {
+	return provider.Usage{
+		CacheCreationInputTokens: u.CacheCreationInputTokens,
+		CacheReadInputTokens:     u.CacheReadInputTokens,
+		InputTokens:              u.InputTokens + u.CacheCreationInputTokens + u.CacheReadInputTokens,
+		OutputTokens:             u.OutputTokens,
+	}
+}
+
+// ToFinishReason converts the stop reason to the finish reason, e.g.:
+// `max_tokens` to provider.FinishReasonLength. Unknown ones are kept.
+func ToFinishReason(stopReason string) string {
+	switch stopReason {
+	case StopReasonEndTurn, StopReasonStopSequence:
+		return provider.FinishReasonStop
+	case StopReasonMaxTokens:
+		return provider.FinishReasonLength
+	case StopReasonRefusal:
+		return provider.FinishReasonContentFilter
+	case StopReasonToolUse:
+		return provider.FinishReasonToolCalls
+	default:
+		return stopReason
+	}
+}
+
+// ToMessages converts messages to the API messages, each with a single text
+// block.
+func ToMessages(messages []message.Message) []Message {
+	converted := make([]Message, 0, len(messages))
+
+	for _, m := range messages {
+		converted = append(converted, Message{
+			Content: Blocks{{Text: m.Content, Type: BlockTypeText}},
+			Role:    m.Role,
+		})
 	}
 
-	return "", customerror.New(
-		"no content",
-		customerror.WithStatusCode(http.StatusNoContent),
-	)
+	return converted
 }
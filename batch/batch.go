@@ -0,0 +1,280 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Discount is the fraction of the regular price billed for batched requests.
+const Discount = 0.5
+
+// DefaultPollInterval is the default interval between status checks.
+const DefaultPollInterval = time.Minute
+
+// Status of a batch.
+type Status string
+
+// Statuses of a batch.
+const (
+	// StatusInProgress means the batch is being validated, processed, or
+	// finalized.
+	StatusInProgress Status = "in_progress"
+
+	// StatusCompleted means all requests were processed, successfully, or
+	// not.
+	StatusCompleted Status = "completed"
+
+	// StatusFailed means the batch failed, e.g.: validation of its input.
+	// Requests weren't processed.
+	StatusFailed Status = "failed"
+
+	// StatusCanceled means the batch was canceled. Requests processed before
+	// have results.
+	StatusCanceled Status = "canceled"
+
+	// StatusExpired means the batch didn't complete in time. Requests
+	// processed before have results.
+	StatusExpired Status = "expired"
+)
+
+// Request of a batch.
+type Request struct {
+	// ID of the request, unique within the batch, used to map the result
+	// back.
+	ID string `json:"id"`
+
+	// Options of the completion.
+	Options []provider.Func `json:"-"`
+}
+
+// Result of a request.
+type Result struct {
+	// ID of the request.
+	ID string `json:"id"`
+
+	// Error of the request, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Response of the request, if it succeeded.
+	Response string `json:"response,omitempty"`
+
+	// Usage of the request, its cost discounted.
+	Usage provider.Usage `json:"usage"`
+}
+
+// Counts of requests of a batch.
+type Counts struct {
+	Failed    int `json:"failed"`
+	Succeeded int `json:"succeeded"`
+	Total     int `json:"total"`
+}
+
+// Job is a submitted batch.
+type Job struct {
+	// CreatedAt is when the batch was submitted.
+	CreatedAt time.Time `json:"createdAt"`
+
+	// Counts of requests.
+	Counts Counts `json:"counts"`
+
+	// ID of the batch, assigned by the vendor.
+	ID string `json:"id"`
+
+	// Provider the batch was submitted to.
+	Provider string `json:"provider"`
+
+	// Status of the batch.
+	Status Status `json:"status"`
+}
+
+// IBatcher is implemented by providers supporting batches.
+type IBatcher interface {
+	// SubmitBatch builds, and submits the requests as a batch.
+	SubmitBatch(ctx context.Context, requests []Request) (*Job, error)
+
+	// GetBatch returns the batch.
+	GetBatch(ctx context.Context, id string) (*Job, error)
+
+	// CancelBatch cancels the batch.
+	CancelBatch(ctx context.Context, id string) (*Job, error)
+
+	// BatchResults downloads the results of the batch, once ended, in no
+	// particular order.
+	BatchResults(ctx context.Context, id string) ([]Result, error)
+}
+
+// Func allows to set options.
+type Func func(o *Options) error
+
+// Options of Run, and Wait.
+type Options struct {
+	// PollInterval is the interval between status checks.
+	PollInterval time.Duration
+
+	// Progress, if set, is called with the batch on every status check.
+	Progress func(job Job)
+}
+
+//////
+// Exported options.
+//////
+
+// WithPollInterval sets the interval between status checks. Default to
+// DefaultPollInterval.
+func WithPollInterval(interval time.Duration) Func {
+	return func(o *Options) error {
+		if interval <= 0 {
+			return customerror.NewInvalidError("poll interval, must be positive")
+		}
+
+		o.PollInterval = interval
+
+		return nil
+	}
+}
+
+// WithProgress calls `fn` with the batch on every status check.
+func WithProgress(fn func(job Job)) Func {
+	return func(o *Options) error {
+		o.Progress = fn
+
+		return nil
+	}
+}
+
+//////
+// Methods.
+//////
+
+// Done returns true if the batch ended, successfully, or not.
+func (s Status) Done() bool {
+	return s != StatusInProgress
+}
+
+// Err returns the error of the result, if it failed.
+func (r Result) Err() error {
+	if r.Error == "" {
+		return nil
+	}
+
+	return customerror.New(fmt.Sprintf("request %s: %s", r.ID, r.Error))
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Run submits the requests as a batch, waits for it to end, and returns the
+// results in the order of the requests. Requests without a result, e.g.: the
+// batch expired, or was canceled before processing them, fail. It errors if
+// the batch failed. Cancel ctx to stop waiting, the batch isn't canceled.
+func Run(ctx context.Context, b IBatcher, requests []Request, options ...Func) ([]Result, error) {
+	job, err := b.SubmitBatch(ctx, requests)
+	if err != nil {
+		return nil, err
+	}
+
+	job, err = Wait(ctx, b, job.ID, options...)
+	if err != nil {
+		return nil, err
+	}
+
+	if job.Status == StatusFailed {
+		return nil, customerror.NewFailedToError(fmt.Sprintf("process batch %s", job.ID))
+	}
+
+	results, err := b.BatchResults(ctx, job.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return Map(requests, results), nil
+}
+
+// Wait polls the batch until it ends, and returns it.
+func Wait(ctx context.Context, b IBatcher, id string, options ...Func) (*Job, error) {
+	o := Options{PollInterval: DefaultPollInterval}
+
+	for _, option := range options {
+		if err := option(&o); err != nil {
+			return nil, err
+		}
+	}
+
+	ticker := time.NewTicker(o.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		job, err := b.GetBatch(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if o.Progress != nil {
+			o.Progress(*job)
+		}
+
+		if job.Status.Done() {
+			return job, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Map orders the results as the requests. Requests without a result fail.
+func Map(requests []Request, results []Result) []Result {
+	byID := make(map[string]Result, len(results))
+
+	for _, r := range results {
+		byID[r.ID] = r
+	}
+
+	mapped := make([]Result, 0, len(requests))
+
+	for _, r := range requests {
+		result, ok := byID[r.ID]
+		if !ok {
+			result = Result{Error: "no result", ID: r.ID}
+		}
+
+		mapped = append(mapped, result)
+	}
+
+	return mapped
+}
+
+// Validate validates the requests: at least one, each with an unique ID.
+func Validate(requests []Request) error {
+	if len(requests) == 0 {
+		return customerror.NewRequiredError("requests")
+	}
+
+	seen := make(map[string]bool, len(requests))
+
+	for _, r := range requests {
+		if r.ID == "" {
+			return customerror.NewRequiredError("request ID")
+		}
+
+		if seen[r.ID] {
+			return customerror.NewInvalidError(fmt.Sprintf("request ID %s, duplicated", r.ID))
+		}
+
+		seen[r.ID] = true
+	}
+
+	return nil
+}
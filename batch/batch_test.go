@@ -0,0 +1,108 @@
+package batch
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+// fakeBatcher ends batches after `polls` status checks.
+type fakeBatcher struct {
+	mu sync.Mutex
+
+	polls   int
+	results []Result
+	status  Status
+}
+
+func (f *fakeBatcher) SubmitBatch(_ context.Context, requests []Request) (*Job, error) {
+	if err := Validate(requests); err != nil {
+		return nil, err
+	}
+
+	return &Job{ID: "batch_1", Status: StatusInProgress}, nil
+}
+
+func (f *fakeBatcher) GetBatch(_ context.Context, id string) (*Job, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.polls--
+
+	if f.polls > 0 {
+		return &Job{ID: id, Status: StatusInProgress}, nil
+	}
+
+	return &Job{ID: id, Status: f.status}, nil
+}
+
+func (f *fakeBatcher) CancelBatch(_ context.Context, id string) (*Job, error) {
+	return &Job{ID: id, Status: StatusCanceled}, nil
+}
+
+func (f *fakeBatcher) BatchResults(_ context.Context, _ string) ([]Result, error) {
+	return f.results, nil
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+
+	requests := []Request{
+		{ID: "a", Options: []provider.Func{provider.WithUserMessages("a")}},
+		{ID: "b", Options: []provider.Func{provider.WithUserMessages("b")}},
+		{ID: "c", Options: []provider.Func{provider.WithUserMessages("c")}},
+	}
+
+	b := &fakeBatcher{
+		polls: 3,
+		results: []Result{
+			{ID: "c", Error: "invalid request"},
+			{ID: "a", Response: "A"},
+		},
+		status: StatusExpired,
+	}
+
+	var progress []Status
+
+	results, err := Run(ctx, b, requests, WithPollInterval(time.Millisecond), WithProgress(func(job Job) {
+		progress = append(progress, job.Status)
+	}))
+	assert.NoError(t, err)
+
+	assert.Equal(t, []Status{StatusInProgress, StatusInProgress, StatusExpired}, progress)
+
+	// In the order of the requests, those without a result fail.
+	assert.Equal(t, []Result{
+		{ID: "a", Response: "A"},
+		{Error: "no result", ID: "b"},
+		{Error: "invalid request", ID: "c"},
+	}, results)
+
+	assert.NoError(t, results[0].Err())
+	assert.ErrorContains(t, results[1].Err(), "request b: no result")
+
+	// Failed batches.
+	_, err = Run(ctx, &fakeBatcher{status: StatusFailed}, requests, WithPollInterval(time.Millisecond))
+	assert.Error(t, err)
+
+	// Waiting is stopped with the context.
+	cancelCtx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err = Wait(cancelCtx, &fakeBatcher{polls: 1000}, "batch_1", WithPollInterval(time.Millisecond))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	_, err = Wait(ctx, b, "batch_1", WithPollInterval(0))
+	assert.Error(t, err)
+}
+
+func TestValidate(t *testing.T) {
+	assert.NoError(t, Validate([]Request{{ID: "a"}, {ID: "b"}}))
+	assert.Error(t, Validate(nil))
+	assert.Error(t, Validate([]Request{{ID: ""}}))
+	assert.Error(t, Validate([]Request{{ID: "a"}, {ID: "a"}}))
+}
@@ -0,0 +1,19 @@
+// Package batch runs requests through vendor batch APIs, e.g.: OpenAI Batch,
+// and Anthropic Message Batches, processed asynchronously, within 24 hours,
+// at a discount.
+//
+// Requests are the options of a completion, identified by caller-supplied
+// IDs. Run submits them, waits for the batch to end, downloads the results,
+// and maps them back to the IDs, in the order of the requests:
+//
+//	p, err := openai.NewDefault()
+//
+//	results, err := batch.Run(ctx, p, []batch.Request{
+//		{ID: "q1", Options: []provider.Func{provider.WithUserMessages("Why is the sky blue?")}},
+//		{ID: "q2", Options: []provider.Func{provider.WithUserMessages("Why is the sea salty?")}},
+//	}, batch.WithPollInterval(5*time.Minute))
+//
+// Providers supporting batches implement the IBatcher interface. Long-running
+// jobs can be submitted, and collected separately, e.g.: by a nightly job, and
+// a later one, through SubmitBatch, Wait, and BatchResults.
+package batch
@@ -0,0 +1,56 @@
+package bulk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Helpers.
+//////
+
+// loadCheckpoint returns the results already in the output file, by ID, the
+// latest winning. A partially written last line, e.g.: of a crash, is removed.
+func loadCheckpoint(path string) (map[string]Result, error) {
+	results := make(map[string]Result)
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return results, nil
+		}
+
+		return nil, customerror.NewFailedToError("read the checkpoint", customerror.WithError(err))
+	}
+
+	complete := bytes.LastIndexByte(data, '\n') + 1
+
+	if complete < len(data) {
+		if err := os.Truncate(path, int64(complete)); err != nil {
+			return nil, customerror.NewFailedToError("truncate the checkpoint", customerror.WithError(err))
+		}
+	}
+
+	for i, line := range bytes.Split(data[:complete], []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var r Result
+
+		if err := json.Unmarshal(line, &r); err != nil {
+			return nil, customerror.NewFailedToError(
+				fmt.Sprintf("decode line %d of the checkpoint", i+1), customerror.WithError(err),
+			)
+		}
+
+		results[r.ID] = r
+	}
+
+	return results, nil
+}
@@ -0,0 +1,16 @@
+// Package bulk processes large input files through any provider, locally,
+// with bounded concurrency, independently of vendor batch APIs.
+//
+// Each input item, a line of a JSONL file, or a row of a CSV file, is a
+// prompt, optionally with an ID, a system prompt, and a model:
+//
+//	{"id": "q1", "prompt": "Why is the sky blue?", "system": "Be brief."}
+//
+// Results are appended to the output JSONL file as soon as they're available.
+// The output file is also the checkpoint: running again, e.g.: after a crash,
+// skips the items already there, and continues where it left off:
+//
+//	r, err := bulk.New(p, bulk.WithConcurrency(8), bulk.WithOptions(provider.WithMaxToken(256)))
+//
+//	summary, err := r.Run(ctx, "questions.jsonl", "answers.jsonl")
+package bulk
@@ -0,0 +1,191 @@
+package bulk
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Format of the input file.
+type Format string
+
+// Supported formats.
+const (
+	// CSV files have a header row, naming the columns: `id`, `prompt`,
+	// `system`, and `model`. Other columns are ignored.
+	CSV Format = "csv"
+
+	// JSONL files have an item per line.
+	JSONL Format = "jsonl"
+)
+
+// Item is an input item.
+type Item struct {
+	// ID of the item, unique within the file. Default to its position,
+	// starting at 1.
+	ID string `json:"id,omitempty"`
+
+	// Model overrides the default one.
+	Model string `json:"model,omitempty"`
+
+	// Prompt sent as the user message.
+	Prompt string `json:"prompt"`
+
+	// System is sent as the system message.
+	System string `json:"system,omitempty"`
+
+	// err is why the item is invalid, e.g.: malformed. It's recorded as its
+	// result, instead of being sent.
+	err error
+}
+
+// reader reads items, one at a time, returning io.EOF once done. Invalid
+// items are returned with their error set, errors are fatal.
+type reader func() (Item, error)
+
+// maxLineSize is the maximum size, in bytes, of a JSONL line.
+const maxLineSize = 16 << 20
+
+//////
+// Helpers.
+//////
+
+// formatOf returns the format of the file, by its extension.
+func formatOf(path string) (Format, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return CSV, nil
+	case ".jsonl", ".ndjson":
+		return JSONL, nil
+	default:
+		return "", customerror.NewInvalidError(
+			fmt.Sprintf("input format of %s, expected %s, or %s", path, CSV, JSONL),
+		)
+	}
+}
+
+// newReader returns a reader of the items of `r`, in the format.
+func newReader(r io.Reader, format Format) (reader, error) {
+	position := 0
+
+	// validate defaults the ID, and validates the item.
+	validate := func(item Item, err error) (Item, error) {
+		position++
+
+		if item.ID == "" {
+			item.ID = strconv.Itoa(position)
+		}
+
+		switch {
+		case err != nil:
+			item.err = err
+		case item.Prompt == "":
+			item.err = customerror.NewRequiredError(fmt.Sprintf("prompt of item %s", item.ID))
+		}
+
+		return item, nil
+	}
+
+	switch format {
+	case JSONL:
+		lines := bufio.NewScanner(r)
+		lines.Buffer(nil, maxLineSize)
+
+		return func() (Item, error) {
+			for lines.Scan() {
+				line := bytes.TrimSpace(lines.Bytes())
+				if len(line) == 0 {
+					continue
+				}
+
+				var item Item
+
+				if err := json.Unmarshal(line, &item); err != nil {
+					return validate(Item{}, customerror.NewFailedToError(
+						fmt.Sprintf("decode item %d", position+1), customerror.WithError(err),
+					))
+				}
+
+				return validate(item, nil)
+			}
+
+			if err := lines.Err(); err != nil {
+				return Item{}, customerror.NewFailedToError(
+					fmt.Sprintf("read item %d", position+1), customerror.WithError(err),
+				)
+			}
+
+			return Item{}, io.EOF
+		}, nil
+	case CSV:
+		records := csv.NewReader(r)
+		records.FieldsPerRecord = -1
+
+		header, err := records.Read()
+		if err != nil {
+			return nil, customerror.NewFailedToError("read the CSV header", customerror.WithError(err))
+		}
+
+		columns := make(map[string]int, len(header))
+
+		for i, name := range header {
+			columns[strings.ToLower(strings.TrimSpace(name))] = i
+		}
+
+		if _, ok := columns["prompt"]; !ok {
+			return nil, customerror.NewRequiredError("prompt column")
+		}
+
+		return func() (Item, error) {
+			record, err := records.Read()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					return Item{}, io.EOF
+				}
+
+				// Malformed, the next record can still be read.
+				var parseErr *csv.ParseError
+
+				malformed := errors.As(err, &parseErr)
+
+				err = customerror.NewFailedToError(fmt.Sprintf("read item %d", position+1), customerror.WithError(err))
+
+				if malformed {
+					return validate(Item{}, err)
+				}
+
+				return Item{}, err
+			}
+
+			field := func(name string) string {
+				if i, ok := columns[name]; ok && i < len(record) {
+					return record[i]
+				}
+
+				return ""
+			}
+
+			return validate(Item{
+				ID:     field("id"),
+				Model:  field("model"),
+				Prompt: field("prompt"),
+				System: field("system"),
+			}, nil)
+		}, nil
+	default:
+		return nil, customerror.NewInvalidError(fmt.Sprintf("format %s", format))
+	}
+}
@@ -0,0 +1,390 @@
+package bulk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// DefaultConcurrency is the default number of items processed at a time.
+const DefaultConcurrency = 4
+
+// Func allows to set options.
+type Func func(r *Runner) error
+
+// Result of an item.
+type Result struct {
+	// ID of the item.
+	ID string `json:"id"`
+
+	// Duration of the completion.
+	Duration time.Duration `json:"duration"`
+
+	// Error of the completion, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Response of the completion, if it succeeded.
+	Response string `json:"response,omitempty"`
+
+	// Usage of the completion.
+	Usage provider.Usage `json:"usage"`
+}
+
+// Summary of a run, covering all the results in the output file, including
+// the ones of previous runs.
+type Summary struct {
+	// Cost, in USD.
+	Cost float64 `json:"cost"`
+
+	// Duration of the run.
+	Duration time.Duration `json:"duration"`
+
+	// Failed items.
+	Failed int `json:"failed"`
+
+	// InputTokens used.
+	InputTokens int `json:"inputTokens"`
+
+	// OutputTokens generated.
+	OutputTokens int `json:"outputTokens"`
+
+	// Processed items, in this run.
+	Processed int `json:"processed"`
+
+	// Skipped items, already in the output file.
+	Skipped int `json:"skipped"`
+
+	// Succeeded items.
+	Succeeded int `json:"succeeded"`
+}
+
+// Runner processes input files through a provider.
+type Runner struct {
+	concurrency int
+	format      Format
+	options     []provider.Func
+	progress    func(result Result)
+	provider    provider.IProvider
+	retryFailed bool
+}
+
+//////
+// Exported options.
+//////
+
+// WithConcurrency sets the number of items processed at a time. Default to
+// DefaultConcurrency.
+func WithConcurrency(concurrency int) Func {
+	return func(r *Runner) error {
+		if concurrency <= 0 {
+			return customerror.NewInvalidError("concurrency, must be positive")
+		}
+
+		r.concurrency = concurrency
+
+		return nil
+	}
+}
+
+// WithFormat sets the format of the input file. Default to its extension.
+func WithFormat(format Format) Func {
+	return func(r *Runner) error {
+		if format != CSV && format != JSONL {
+			return customerror.NewInvalidError(fmt.Sprintf("format %s", format))
+		}
+
+		r.format = format
+
+		return nil
+	}
+}
+
+// WithOptions sets the options of every completion, e.g.: MaxTokens. Items
+// set the messages, and the model.
+func WithOptions(options ...provider.Func) Func {
+	return func(r *Runner) error {
+		r.options = options
+
+		return nil
+	}
+}
+
+// WithProgress calls `fn` with every result, once written. Calls are
+// serialized.
+func WithProgress(fn func(result Result)) Func {
+	return func(r *Runner) error {
+		r.progress = fn
+
+		return nil
+	}
+}
+
+// WithRetryFailed retries the items which failed in previous runs, instead of
+// skipping them.
+func WithRetryFailed() Func {
+	return func(r *Runner) error {
+		r.retryFailed = true
+
+		return nil
+	}
+}
+
+//////
+// Methods.
+//////
+
+// Run processes the items of the input file not yet in the output file,
+// appending each result to it as soon as available, and returns the summary.
+// Item failures, including invalid items, e.g.: malformed, or without prompt,
+// are recorded, not returned. Duplicated IDs, and unreadable inputs stop the
+// run. Cancel ctx to stop: in-flight
+// items aren't recorded, thus processed by the next run.
+func (r *Runner) Run(ctx context.Context, inputPath, outputPath string) (*Summary, error) {
+	start := time.Now()
+
+	format := r.format
+	if format == "" {
+		f, err := formatOf(inputPath)
+		if err != nil {
+			return nil, err
+		}
+
+		format = f
+	}
+
+	previous, err := loadCheckpoint(outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	input, err := os.Open(inputPath)
+	if err != nil {
+		return nil, customerror.NewFailedToError("open the input", customerror.WithError(err))
+	}
+
+	defer input.Close()
+
+	next, err := newReader(input, format)
+	if err != nil {
+		return nil, err
+	}
+
+	output, err := os.OpenFile(outputPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, customerror.NewFailedToError("open the output", customerror.WithError(err))
+	}
+
+	defer output.Close()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	summary := &Summary{}
+
+	items := make(chan Item)
+	done := make(chan Result)
+
+	// Reads the items, skipping the ones already processed.
+	var readErr error
+
+	go func() {
+		defer close(items)
+
+		seen := make(map[string]bool)
+
+		for {
+			item, err := next()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					readErr = err
+
+					cancel()
+				}
+
+				return
+			}
+
+			if seen[item.ID] {
+				readErr = customerror.NewInvalidError(fmt.Sprintf("item ID %s, duplicated", item.ID))
+
+				cancel()
+
+				return
+			}
+
+			seen[item.ID] = true
+
+			if result, ok := previous[item.ID]; ok && (result.Error == "" || !r.retryFailed) {
+				summary.Skipped++
+
+				continue
+			}
+
+			select {
+			case items <- item:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// Processes the items.
+	var wg sync.WaitGroup
+
+	for range r.concurrency {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for item := range items {
+				result := r.complete(ctx, item)
+
+				// Interrupted, processed by the next run.
+				if ctx.Err() != nil {
+					continue
+				}
+
+				done <- result
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+
+		close(done)
+	}()
+
+	// Writes the results.
+	var writeErr error
+
+	processed := []Result{}
+
+	encoder := json.NewEncoder(output)
+
+	for result := range done {
+		if writeErr != nil {
+			continue
+		}
+
+		if err := encoder.Encode(result); err != nil {
+			writeErr = customerror.NewFailedToError("write the result", customerror.WithError(err))
+
+			cancel()
+
+			continue
+		}
+
+		processed = append(processed, result)
+
+		if r.progress != nil {
+			r.progress(result)
+		}
+	}
+
+	summary.Processed = len(processed)
+
+	// The latest result of each item.
+	for _, result := range processed {
+		previous[result.ID] = result
+	}
+
+	for _, result := range previous {
+		if result.Error != "" {
+			summary.Failed++
+		} else {
+			summary.Succeeded++
+		}
+
+		summary.Cost += result.Usage.Cost
+		summary.InputTokens += result.Usage.InputTokens
+		summary.OutputTokens += result.Usage.OutputTokens
+	}
+
+	summary.Duration = time.Since(start)
+
+	if err := errors.Join(readErr, writeErr); err != nil {
+		return summary, err
+	}
+
+	return summary, context.Cause(ctx)
+}
+
+//////
+// Helpers.
+//////
+
+// complete processes the item. Invalid items fail without being sent.
+func (r *Runner) complete(ctx context.Context, item Item) Result {
+	if item.err != nil {
+		return Result{ID: item.ID, Error: item.err.Error()}
+	}
+
+	var usage provider.Usage
+
+	options := append([]provider.Func{}, r.options...)
+
+	if item.Model != "" {
+		options = append(options, provider.WithModel(item.Model))
+	}
+
+	if item.System != "" {
+		options = append(options, provider.WithSystemMessages(item.System))
+	}
+
+	options = append(options, provider.WithUserMessages(item.Prompt), provider.WithUsage(&usage))
+
+	start := time.Now()
+
+	response, err := r.provider.Completion(ctx, options...)
+
+	result := Result{
+		Duration: time.Since(start),
+		ID:       item.ID,
+		Response: response,
+		Usage:    usage,
+	}
+
+	if err != nil {
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+//////
+// Factory.
+//////
+
+// New returns a runner processing items through the provider.
+func New(p provider.IProvider, options ...Func) (*Runner, error) {
+	if p == nil {
+		return nil, customerror.NewRequiredError("provider")
+	}
+
+	r := &Runner{
+		concurrency: DefaultConcurrency,
+		provider:    p,
+	}
+
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,258 @@
+package bulk
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/providertest"
+)
+
+// newMock returns a mock failing prompts starting with "fail".
+func newMock(t *testing.T, latency time.Duration) *providertest.Mock {
+	t.Helper()
+
+	p, err := providertest.New(
+		providertest.WithLatency(latency),
+		providertest.WithFallback(providertest.Response{
+			Content: "ok",
+			Expect: func(o *provider.Options) error {
+				if len(o.UserMessages) > 0 && len(o.UserMessages[0]) >= 4 && o.UserMessages[0][:4] == "fail" {
+					return errors.New("provider is unavailable")
+				}
+
+				return nil
+			},
+			Usage: provider.Usage{InputTokens: 10, OutputTokens: 2},
+		}),
+	)
+	assert.NoError(t, err)
+
+	return p
+}
+
+// readResults reads the results of the output file.
+func readResults(t *testing.T, path string) []Result {
+	t.Helper()
+
+	r, err := loadCheckpoint(path)
+	assert.NoError(t, err)
+
+	results := []Result{}
+
+	for _, result := range r {
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// countLines counts the lines of the file.
+func countLines(t *testing.T, path string) int {
+	t.Helper()
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+
+	defer f.Close()
+
+	n := 0
+
+	for s := bufio.NewScanner(f); s.Scan(); {
+		n++
+	}
+
+	return n
+}
+
+func TestRun(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	input := filepath.Join(dir, "input.jsonl")
+	output := filepath.Join(dir, "output.jsonl")
+
+	assert.NoError(t, os.WriteFile(input, []byte(`{"id": "a", "prompt": "hi", "system": "be brief", "model": "other-model"}
+{"prompt": "hello"}
+{"id": "c", "prompt": "fail please"}
+{"id": "d", "prompt": "hey"}
+`), 0o600))
+
+	p := newMock(t, 0)
+
+	var progress []string
+
+	r, err := New(
+		p,
+		WithConcurrency(2),
+		WithOptions(provider.WithMaxToken(64)),
+		WithProgress(func(result Result) { progress = append(progress, result.ID) }),
+	)
+	assert.NoError(t, err)
+
+	summary, err := r.Run(ctx, input, output)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 4, summary.Processed)
+	assert.Equal(t, 3, summary.Succeeded)
+	assert.Equal(t, 1, summary.Failed)
+	assert.Equal(t, 30, summary.InputTokens)
+	assert.Equal(t, 6, summary.OutputTokens)
+	assert.ElementsMatch(t, []string{"a", "2", "c", "d"}, progress)
+
+	for _, call := range p.Calls() {
+		assert.Equal(t, 64, call.Options.MaxTokens)
+
+		if call.Options.UserMessages[0] == "hi" {
+			assert.Equal(t, "other-model", call.Options.Model)
+			assert.Equal(t, []string{"be brief"}, call.Options.SystemMessages)
+		}
+	}
+
+	for _, result := range readResults(t, output) {
+		if result.ID == "c" {
+			assert.Contains(t, result.Error, "provider is unavailable")
+		} else {
+			assert.Equal(t, "ok", result.Response)
+		}
+	}
+
+	// Resumes, skipping all.
+	summary, err = r.Run(ctx, input, output)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, summary.Processed)
+	assert.Equal(t, 4, summary.Skipped)
+	assert.Equal(t, 3, summary.Succeeded)
+	assert.Len(t, p.Calls(), 4)
+
+	// Retries the failed ones, the latest result wins.
+	assert.NoError(t, os.WriteFile(input, []byte(`{"id": "c", "prompt": "now it works"}`), 0o600))
+
+	r, err = New(p, WithRetryFailed())
+	assert.NoError(t, err)
+
+	summary, err = r.Run(ctx, input, output)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, summary.Processed)
+	assert.Equal(t, 4, summary.Succeeded)
+	assert.Equal(t, 0, summary.Failed)
+	assert.Equal(t, 5, countLines(t, output))
+}
+
+func TestRun_resume(t *testing.T) {
+	dir := t.TempDir()
+
+	input := filepath.Join(dir, "input.csv")
+	output := filepath.Join(dir, "output.jsonl")
+
+	content := "id,prompt,extra\n"
+	for i := range 20 {
+		content += string(rune('a'+i)) + ",hi,x\n"
+	}
+
+	assert.NoError(t, os.WriteFile(input, []byte(content), 0o600))
+
+	// A crash left a partially written line.
+	assert.NoError(t, os.WriteFile(output, []byte(`{"id":"a","response":"ok","usage":{"inputTokens":10}}
+{"id":"b","resp`), 0o600))
+
+	r, err := New(newMock(t, 20*time.Millisecond), WithConcurrency(2))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	summary, err := r.Run(ctx, input, output)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Equal(t, 1, summary.Skipped)
+	assert.Less(t, summary.Processed, 19)
+
+	// Interrupted items aren't recorded, the next run processes them.
+	summary, err = r.Run(context.Background(), input, output)
+	assert.NoError(t, err)
+	assert.Equal(t, 20, summary.Succeeded)
+	assert.Equal(t, 20, countLines(t, output))
+}
+
+func TestRun_invalidItems(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	r, err := New(newMock(t, 0))
+	assert.NoError(t, err)
+
+	for name, content := range map[string]string{
+		"items.jsonl": `{"id": "a", "prompt": "hi"}
+{"id": 
+{"id": "c"}
+
+{"id": "d", "prompt": "hey"}
+`,
+		"items.csv": "id,prompt\na,hi\n\"b,\"x\"\nc,\nd,hey\n",
+	} {
+		t.Run(name, func(t *testing.T) {
+			input := filepath.Join(dir, name)
+			output := filepath.Join(dir, name+".out.jsonl")
+
+			assert.NoError(t, os.WriteFile(input, []byte(content), 0o600))
+
+			summary, err := r.Run(ctx, input, output)
+			assert.NoError(t, err)
+			assert.Equal(t, 4, summary.Processed)
+			assert.Equal(t, 2, summary.Failed)
+			assert.Equal(t, 2, summary.Succeeded)
+
+			results, err := loadCheckpoint(output)
+			assert.NoError(t, err)
+			assert.Empty(t, results["a"].Error)
+			assert.Contains(t, results["2"].Error, "item 2")
+			assert.Contains(t, results["c"].Error, "prompt")
+			assert.Empty(t, results["d"].Error)
+		})
+	}
+}
+
+func TestRun_errors(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+		return path
+	}
+
+	r, err := New(newMock(t, 0))
+	assert.NoError(t, err)
+
+	output := filepath.Join(dir, "output.jsonl")
+
+	for name, input := range map[string]string{
+		"Duplicated ID":     write("duplicated.jsonl", `{"id": "a", "prompt": "hi"}`+"\n"+`{"id": "a", "prompt": "hi"}`),
+		"Missing column":    write("column.csv", "id,text\na,hi\n"),
+		"Unknown format":    write("input.txt", "hi"),
+		"Missing the input": filepath.Join(dir, "missing.csv"),
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := r.Run(ctx, input, output)
+			assert.Error(t, err)
+		})
+	}
+
+	_, err = New(nil)
+	assert.Error(t, err)
+
+	_, err = New(newMock(t, 0), WithConcurrency(0))
+	assert.Error(t, err)
+
+	_, err = New(newMock(t, 0), WithFormat("xml"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,114 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Name of the cache, also used to key its options in provider.Options.Extras.
+const Name = "cache"
+
+// keyVersion is part of every key. Bump it when the key format changes.
+const keyVersion = "v2"
+
+// Mode changes how a request uses the cache.
+type Mode string
+
+// Available modes.
+const (
+	// ModeBypass neither reads, nor writes the cache.
+	ModeBypass Mode = "bypass"
+
+	// ModeRefresh doesn't read the cache, but writes the fresh response.
+	ModeRefresh Mode = "refresh"
+)
+
+// Cache stores responses by key. Implementations must be safe for concurrent
+// use.
+type Cache interface {
+	// Get returns the value stored under key, if found, and not expired.
+	Get(ctx context.Context, key string) (string, bool, error)
+
+	// Set stores the value under key. A zero ttl means it doesn't expire.
+	Set(ctx context.Context, key, value string, ttl time.Duration) error
+
+	// Delete removes the value stored under key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithBypass makes the request neither read, nor write the cache.
+func WithBypass() provider.Func {
+	return provider.WithExtra(Name, ModeBypass)
+}
+
+// WithRefresh makes the request skip the cached response, if any, and store
+// the fresh one.
+func WithRefresh() provider.Func {
+	return provider.WithExtra(Name, ModeRefresh)
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Key returns the canonical key of the processed options, sent to the
+// provider. Its name, and endpoint are part of it, so instances of the same
+// vendor, e.g.: two Ollama hosts, don't share responses. Options which don't
+// change the response, e.g.: ResponseBody, Usage, Strict, Template, and the
+// cache mode, are not part of it.
+func Key(p provider.IMeta, o *provider.Options) (string, error) {
+	normalized := *o
+
+	normalized.Capabilities = nil
+	normalized.ResponseBody = nil
+	normalized.Strict = false
+	normalized.Template = nil
+	normalized.Usage = nil
+	normalized.Extras = nil
+
+	for name, extra := range o.Extras {
+		if name == Name {
+			continue
+		}
+
+		if normalized.Extras == nil {
+			normalized.Extras = make(map[string]any)
+		}
+
+		normalized.Extras[name] = extra
+	}
+
+	// Maps are marshalled with sorted keys, thus the output is canonical.
+	data, err := json.Marshal(struct {
+		Endpoint string            `json:"endpoint"`
+		Options  *provider.Options `json:"options"`
+		Provider string            `json:"provider"`
+		Version  string            `json:"version"`
+	}{
+		Endpoint: provider.EndpointOf(p),
+		Options:  &normalized,
+		Provider: p.GetName(),
+		Version:  keyVersion,
+	})
+	if err != nil {
+		return "", customerror.NewFailedToError("marshal cache key", customerror.WithError(err))
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
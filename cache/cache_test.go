@@ -0,0 +1,171 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+// echo is a provider answering with the user messages, counting calls.
+type echo struct {
+	*provider.Provider
+
+	calls int
+	err   error
+}
+
+func (e *echo) Completion(_ context.Context, options ...provider.Func) (string, error) {
+	e.calls++
+
+	if e.err != nil {
+		return "", e.err
+	}
+
+	o, err := provider.NewOptionsFrom(append([]provider.Func{provider.WithModel(e.DefaultModel)}, options...)...)
+	if err != nil {
+		return "", err
+	}
+
+	response := `{"response":"` + o.UserMessages[0] + `"}`
+
+	if o.ResponseBody != nil {
+		if err := json.Unmarshal([]byte(response), o.ResponseBody); err != nil {
+			return "", err
+		}
+	}
+
+	return response, nil
+}
+
+func (e *echo) GetClient() any {
+	return nil
+}
+
+func newEcho(t *testing.T) *echo {
+	t.Helper()
+
+	p, err := provider.New("echo", provider.WithEndpoint("http://localhost"), provider.WithDefaulModel("m"))
+	assert.NoError(t, err)
+
+	return &echo{Provider: p}
+}
+
+func TestKey(t *testing.T) {
+	e := newEcho(t)
+
+	keyOf := func(p provider.IMeta, options ...provider.Func) string {
+		o, err := provider.NewOptionsFrom(
+			append([]provider.Func{provider.WithProvider("openai"), provider.WithModel("gpt-4o")}, options...)...,
+		)
+		assert.NoError(t, err)
+
+		k, err := Key(p, o)
+		assert.NoError(t, err)
+
+		return k
+	}
+
+	key := func(options ...provider.Func) string {
+		return keyOf(e, options...)
+	}
+
+	base := key(provider.WithUserMessages("hi"), provider.WithSeed(1))
+
+	assert.Len(t, base, 64)
+	assert.Equal(t, base, key(provider.WithUserMessages("hi"), provider.WithSeed(1)))
+
+	// Options not changing the response.
+	assert.Equal(t, base, key(
+		provider.WithUserMessages("hi"),
+		provider.WithSeed(1),
+		provider.WithResponseBody(&struct{}{}),
+		provider.WithUsage(&provider.Usage{}),
+		provider.WithStrict(false),
+		WithRefresh(),
+	))
+
+	// Options changing the response.
+	assert.NotEqual(t, base, key(provider.WithUserMessages("hello"), provider.WithSeed(1)))
+	assert.NotEqual(t, base, key(provider.WithUserMessages("hi"), provider.WithSeed(2)))
+	assert.NotEqual(t, base, key(provider.WithUserMessages("hi"), provider.WithSeed(1), provider.WithModel("gpt-4o-mini")))
+	assert.NotEqual(t, base, key(provider.WithUserMessages("hi"), provider.WithSeed(1), provider.WithExtra("openai", "x")))
+
+	// Instances of the same vendor, at another endpoint, or named otherwise.
+	for _, name := range []string{"echo", "other"} {
+		p, err := provider.New(name, provider.WithEndpoint("http://other"), provider.WithDefaulModel("m"))
+		assert.NoError(t, err)
+
+		assert.NotEqual(t, base, keyOf(&echo{Provider: p}, provider.WithUserMessages("hi"), provider.WithSeed(1)))
+	}
+
+	p, err := provider.New("other", provider.WithEndpoint("http://localhost"), provider.WithDefaulModel("m"))
+	assert.NoError(t, err)
+
+	assert.NotEqual(t, base, keyOf(&echo{Provider: p}, provider.WithUserMessages("hi"), provider.WithSeed(1)))
+}
+
+func TestProvider(t *testing.T) {
+	ctx := context.Background()
+	e := newEcho(t)
+
+	p, err := New(e, NewMemory(10), 0)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "echo", p.GetName())
+	assert.Equal(t, "m", provider.DefaultModel(p))
+
+	_, ok := provider.CapabilitiesOf(p)
+	assert.True(t, ok)
+
+	hits, misses := p.GetCounterHit().Value(), p.GetCounterMiss().Value()
+
+	// Miss, then hit.
+	for i := range 2 {
+		var body struct {
+			Response string `json:"response"`
+		}
+
+		usage := provider.Usage{InputTokens: 1}
+
+		response, err := p.Completion(
+			ctx,
+			provider.WithUserMessages("hi"),
+			provider.WithResponseBody(&body),
+			provider.WithUsage(&usage),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"response":"hi"}`, response)
+		assert.Equal(t, "hi", body.Response)
+
+		// The fake doesn't fill usage, cached responses have zero usage.
+		if i == 1 {
+			assert.Equal(t, provider.Usage{}, usage)
+		}
+	}
+
+	assert.Equal(t, 1, e.calls)
+	assert.Equal(t, hits+1, p.GetCounterHit().Value())
+	assert.Equal(t, misses+1, p.GetCounterMiss().Value())
+
+	// Refresh calls the provider, and stores the response.
+	_, err = p.Completion(ctx, provider.WithUserMessages("hi"), WithRefresh())
+	assert.NoError(t, err)
+	assert.Equal(t, 2, e.calls)
+
+	// Bypass calls the provider, and doesn't store the response.
+	_, err = p.Completion(ctx, provider.WithUserMessages("bye"), WithBypass())
+	assert.NoError(t, err)
+	assert.Equal(t, 3, e.calls)
+	assert.Equal(t, 1, p.GetCache().(*Memory).Len())
+
+	// Errors aren't cached.
+	e.err = errors.New("boom")
+
+	_, err = p.Completion(ctx, provider.WithUserMessages("error"))
+	assert.Error(t, err)
+	assert.Equal(t, 1, p.GetCache().(*Memory).Len())
+}
@@ -0,0 +1,11 @@
+// Package cache provides caching wrappers around providers, and the stores
+// backing them.
+//
+// Provider keys responses by a canonical hash of the provider name, its
+// endpoint, the model, and the processed options, thus identical requests,
+// e.g.: deterministic classification with a seed, are answered without
+// calling, and billing the provider API again.
+//
+// Semantic goes further: it embeds the prompt, and answers it with the
+// response of the most similar previously answered prompt, if similar enough.
+package cache
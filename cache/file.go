@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// File is an on-disk cache, storing one JSON file per key in a directory. It
+// survives restarts, and can be shared by processes.
+type File struct {
+	dir string
+
+	// now allows to control time in tests.
+	now func() time.Time
+}
+
+//////
+// Methods.
+//////
+
+// Get returns the value stored under key, if found, and not expired.
+func (f *File) Get(ctx context.Context, key string) (string, bool, error) {
+	data, err := os.ReadFile(f.path(key))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return "", false, nil
+		}
+
+		return "", false, customerror.NewFailedToError("read cache entry", customerror.WithError(err))
+	}
+
+	var e entry
+
+	if err := json.Unmarshal(data, &e); err != nil {
+		return "", false, customerror.NewFailedToError("unmarshal cache entry", customerror.WithError(err))
+	}
+
+	if e.expired(f.now()) {
+		return "", false, f.Delete(ctx, key)
+	}
+
+	return e.Value, true, nil
+}
+
+// Set stores the value under key. A zero ttl means it doesn't expire. Writes
+// are atomic.
+func (f *File) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	e := entry{Key: key, Value: value}
+
+	if ttl > 0 {
+		e.ExpiresAt = f.now().Add(ttl)
+	}
+
+	data, err := json.Marshal(e)
+	if err != nil {
+		return customerror.NewFailedToError("marshal cache entry", customerror.WithError(err))
+	}
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return customerror.NewFailedToError("create cache entry", customerror.WithError(err))
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return customerror.NewFailedToError("write cache entry", customerror.WithError(err))
+	}
+
+	if err := tmp.Close(); err != nil {
+		return customerror.NewFailedToError("write cache entry", customerror.WithError(err))
+	}
+
+	if err := os.Rename(tmp.Name(), f.path(key)); err != nil {
+		return customerror.NewFailedToError("write cache entry", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// Delete removes the value stored under key, if any.
+func (f *File) Delete(_ context.Context, key string) error {
+	if err := os.Remove(f.path(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return customerror.NewFailedToError("delete cache entry", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+//////
+// Helpers.
+//////
+
+// path returns the file path of the key. Keys are hashed, thus any key is a
+// valid file name.
+func (f *File) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+//////
+// Factory.
+//////
+
+// NewFile returns an on-disk cache storing in `dir`, created if needed.
+func NewFile(dir string) (*File, error) {
+	// Enforces Cache interface implementation.
+	var _ Cache = (*File)(nil)
+
+	if dir == "" {
+		return nil, customerror.NewRequiredError("dir")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, customerror.NewFailedToError("create cache dir", customerror.WithError(err))
+	}
+
+	return &File{dir: dir, now: time.Now}, nil
+}
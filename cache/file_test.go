@@ -0,0 +1,56 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFile(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	dir := t.TempDir()
+
+	f, err := NewFile(dir)
+	assert.NoError(t, err)
+
+	f.now = func() time.Time { return now }
+
+	_, ok, err := f.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, f.Set(ctx, "a", "1", time.Minute))
+	assert.NoError(t, f.Set(ctx, "../b", "2", 0))
+
+	// Survives reopening.
+	f, err = NewFile(dir)
+	assert.NoError(t, err)
+
+	f.now = func() time.Time { return now }
+
+	value, ok, err := f.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+
+	value, ok, err = f.Get(ctx, "../b")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "2", value)
+
+	// Expiration.
+	now = now.Add(time.Minute)
+
+	_, ok, err = f.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.NoError(t, f.Delete(ctx, "../b"))
+	assert.NoError(t, f.Delete(ctx, "../b"))
+
+	_, ok, _ = f.Get(ctx, "../b")
+	assert.False(t, ok)
+}
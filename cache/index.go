@@ -0,0 +1,199 @@
+package cache
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Match is the nearest previously answered prompt.
+type Match struct {
+	Prompt     string  `json:"prompt"`
+	Response   string  `json:"response"`
+	Similarity float64 `json:"similarity"`
+}
+
+// vectorEntry is an indexed prompt.
+type vectorEntry struct {
+	expiresAt time.Time
+	prompt    string
+	response  string
+	vector    []float64
+}
+
+// Index is an in-memory vector index of answered prompts, partitioned by
+// scope. Search is exhaustive, which is fast enough for thousands of prompts
+// per scope.
+type Index struct {
+	capacity int
+
+	mu     sync.RWMutex
+	scopes map[string][]*vectorEntry
+
+	// now allows to control time in tests.
+	now func() time.Time
+}
+
+//////
+// Methods.
+//////
+
+// Add indexes the prompt, and its response under the scope, evicting the
+// oldest prompts of the scope above capacity. A zero ttl means it doesn't
+// expire.
+func (i *Index) Add(scope, prompt string, vector []float64, response string, ttl time.Duration) error {
+	normalized, err := normalize(vector)
+	if err != nil {
+		return err
+	}
+
+	e := &vectorEntry{prompt: prompt, response: response, vector: normalized}
+
+	if ttl > 0 {
+		e.expiresAt = i.now().Add(ttl)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	entries := append(i.scopes[scope], e)
+
+	if i.capacity > 0 && len(entries) > i.capacity {
+		entries = entries[len(entries)-i.capacity:]
+	}
+
+	i.scopes[scope] = entries
+
+	return nil
+}
+
+// Nearest returns the most similar, by cosine similarity, not expired prompt
+// of the scope. Vectors of different dimensions are ignored.
+func (i *Index) Nearest(scope string, vector []float64) (Match, bool) {
+	normalized, err := normalize(vector)
+	if err != nil {
+		return Match{}, false
+	}
+
+	now := i.now()
+
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	var (
+		best  Match
+		found bool
+	)
+
+	for _, e := range i.scopes[scope] {
+		if len(e.vector) != len(normalized) {
+			continue
+		}
+
+		if !e.expiresAt.IsZero() && !now.Before(e.expiresAt) {
+			continue
+		}
+
+		similarity := dot(e.vector, normalized)
+
+		if !found || similarity > best.Similarity {
+			best = Match{Prompt: e.prompt, Response: e.response, Similarity: similarity}
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// Prune removes the expired prompts.
+func (i *Index) Prune() {
+	now := i.now()
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for scope, entries := range i.scopes {
+		kept := entries[:0]
+
+		for _, e := range entries {
+			if e.expiresAt.IsZero() || now.Before(e.expiresAt) {
+				kept = append(kept, e)
+			}
+		}
+
+		if len(kept) == 0 {
+			delete(i.scopes, scope)
+
+			continue
+		}
+
+		i.scopes[scope] = kept
+	}
+}
+
+// Len returns the amount of indexed prompts, including expired ones not yet
+// pruned.
+func (i *Index) Len() int {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
+
+	n := 0
+
+	for _, entries := range i.scopes {
+		n += len(entries)
+	}
+
+	return n
+}
+
+//////
+// Helpers.
+//////
+
+// normalize returns the unit vector, so cosine similarity is a dot product.
+func normalize(vector []float64) ([]float64, error) {
+	norm := math.Sqrt(dot(vector, vector))
+
+	if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return nil, customerror.NewInvalidError("vector, must be non-zero, and finite")
+	}
+
+	normalized := make([]float64, len(vector))
+
+	for i, v := range vector {
+		normalized[i] = v / norm
+	}
+
+	return normalized, nil
+}
+
+// dot returns the dot product of two vectors of the same dimension.
+func dot(a, b []float64) float64 {
+	sum := 0.0
+
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+
+	return sum
+}
+
+//////
+// Factory.
+//////
+
+// NewIndex returns an empty index holding at most `capacity` prompts per
+// scope. Zero means unbounded.
+func NewIndex(capacity int) *Index {
+	return &Index{
+		capacity: capacity,
+		now:      time.Now,
+		scopes:   make(map[string][]*vectorEntry),
+	}
+}
@@ -0,0 +1,54 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIndex(t *testing.T) {
+	now := time.Now()
+
+	i := NewIndex(2)
+	i.now = func() time.Time { return now }
+
+	assert.Error(t, i.Add("s", "zero", []float64{0, 0}, "", 0))
+
+	assert.NoError(t, i.Add("s", "a", []float64{1, 0}, "A", 0))
+	assert.NoError(t, i.Add("s", "b", []float64{0, 1}, "B", time.Minute))
+	assert.NoError(t, i.Add("other", "c", []float64{1, 1}, "C", 0))
+
+	match, ok := i.Nearest("s", []float64{2, 0.1})
+	assert.True(t, ok)
+	assert.Equal(t, "A", match.Response)
+	assert.InDelta(t, 0.9988, match.Similarity, 0.0001)
+
+	// Scopes are isolated.
+	match, ok = i.Nearest("other", []float64{1, 0})
+	assert.True(t, ok)
+	assert.Equal(t, "C", match.Response)
+
+	_, ok = i.Nearest("unknown", []float64{1, 0})
+	assert.False(t, ok)
+
+	// Different dimensions are ignored.
+	_, ok = i.Nearest("s", []float64{1, 0, 0})
+	assert.False(t, ok)
+
+	// Capacity evicts the oldest of the scope.
+	assert.NoError(t, i.Add("s", "d", []float64{-1, 0}, "D", 0))
+
+	match, _ = i.Nearest("s", []float64{1, 0})
+	assert.Equal(t, "B", match.Response)
+	assert.Equal(t, 3, i.Len())
+
+	// Expiration.
+	now = now.Add(time.Minute)
+
+	match, _ = i.Nearest("s", []float64{0, 1})
+	assert.Equal(t, "D", match.Response)
+
+	i.Prune()
+	assert.Equal(t, 2, i.Len())
+}
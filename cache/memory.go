@@ -0,0 +1,142 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// entry is a stored value.
+type entry struct {
+	ExpiresAt time.Time `json:"expiresAt,omitempty"`
+	Key       string    `json:"key"`
+	Value     string    `json:"value"`
+}
+
+// Memory is an in-memory, least recently used cache.
+type Memory struct {
+	capacity int
+
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+
+	// now allows to control time in tests.
+	now func() time.Time
+}
+
+//////
+// Methods.
+//////
+
+// Get returns the value stored under key, if found, and not expired.
+func (m *Memory) Get(_ context.Context, key string) (string, bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	element, ok := m.items[key]
+	if !ok {
+		return "", false, nil
+	}
+
+	e := element.Value.(*entry)
+
+	if e.expired(m.now()) {
+		m.remove(element)
+
+		return "", false, nil
+	}
+
+	m.order.MoveToFront(element)
+
+	return e.Value, true, nil
+}
+
+// Set stores the value under key, evicting the least recently used values
+// above capacity. A zero ttl means it doesn't expire.
+func (m *Memory) Set(_ context.Context, key, value string, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e := &entry{Key: key, Value: value}
+
+	if ttl > 0 {
+		e.ExpiresAt = m.now().Add(ttl)
+	}
+
+	if element, ok := m.items[key]; ok {
+		element.Value = e
+
+		m.order.MoveToFront(element)
+
+		return nil
+	}
+
+	m.items[key] = m.order.PushFront(e)
+
+	for m.capacity > 0 && m.order.Len() > m.capacity {
+		m.remove(m.order.Back())
+	}
+
+	return nil
+}
+
+// Delete removes the value stored under key, if any.
+func (m *Memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if element, ok := m.items[key]; ok {
+		m.remove(element)
+	}
+
+	return nil
+}
+
+// Len returns the amount of stored values, including expired ones not yet
+// evicted.
+func (m *Memory) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return m.order.Len()
+}
+
+//////
+// Helpers.
+//////
+
+// expired returns true if the entry is expired at `t`.
+func (e *entry) expired(t time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !t.Before(e.ExpiresAt)
+}
+
+// remove removes the element. Must be called with the lock held.
+func (m *Memory) remove(element *list.Element) {
+	m.order.Remove(element)
+
+	delete(m.items, element.Value.(*entry).Key)
+}
+
+//////
+// Factory.
+//////
+
+// NewMemory returns an in-memory cache holding at most `capacity` values. Zero
+// means unbounded.
+func NewMemory(capacity int) *Memory {
+	// Enforces Cache interface implementation.
+	var _ Cache = (*Memory)(nil)
+
+	return &Memory{
+		capacity: capacity,
+		items:    make(map[string]*list.Element),
+		now:      time.Now,
+		order:    list.New(),
+	}
+}
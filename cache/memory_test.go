@@ -0,0 +1,46 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	m := NewMemory(2)
+	m.now = func() time.Time { return now }
+
+	assert.NoError(t, m.Set(ctx, "a", "1", 0))
+	assert.NoError(t, m.Set(ctx, "b", "2", time.Minute))
+
+	// Touch "a", so "b" is the least recently used.
+	value, ok, err := m.Get(ctx, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "1", value)
+
+	assert.NoError(t, m.Set(ctx, "c", "3", 0))
+	assert.Equal(t, 2, m.Len())
+
+	_, ok, _ = m.Get(ctx, "b")
+	assert.False(t, ok)
+
+	// Expiration.
+	assert.NoError(t, m.Set(ctx, "c", "3", time.Minute))
+
+	now = now.Add(time.Minute)
+
+	_, ok, _ = m.Get(ctx, "c")
+	assert.False(t, ok)
+
+	_, ok, _ = m.Get(ctx, "a")
+	assert.True(t, ok)
+
+	assert.NoError(t, m.Delete(ctx, "a"))
+	assert.Equal(t, 0, m.Len())
+}
@@ -0,0 +1,253 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/internal/metrics"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/sypl"
+	"github.com/thalesfsp/sypl/level"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// MetricRequests is the name of the cache requests metric.
+const MetricRequests = "inference_cache_requests_total"
+
+// Results of a cache lookup.
+const (
+	ResultBypass = "bypass"
+	ResultHit    = "hit"
+	ResultMiss   = "miss"
+)
+
+// Types of cache.
+const (
+	TypeExact    = "exact"
+	TypeSemantic = "semantic"
+)
+
+// metricRequests counts cache lookups, by type, and result.
+var metricRequests = metrics.Default.NewCounterVec(
+	MetricRequests,
+	"Amount of cache lookups.",
+	"provider", "model", "type", "result",
+)
+
+// Provider wraps a provider, answering identical requests from the cache.
+// Errors aren't cached.
+type Provider struct {
+	provider.IProvider
+
+	cache Cache
+	ttl   time.Duration
+
+	// Metrics.
+	counterHit  *expvar.Int
+	counterMiss *expvar.Int
+}
+
+//////
+// Methods.
+//////
+
+// Completion returns the cached response, if any, otherwise calls the wrapped
+// provider, and caches the response. Use WithBypass, or WithRefresh to change
+// that per request. Cached responses have zero usage.
+func (p *Provider) Completion(ctx context.Context, options ...provider.Func) (string, error) {
+	o, mode, err := processOptions(p, options...)
+	if err != nil {
+		return "", err
+	}
+
+	if mode == ModeBypass {
+		metricRequests.Add(1, p.GetName(), o.Model, TypeExact, ResultBypass)
+
+		return p.IProvider.Completion(ctx, options...)
+	}
+
+	key, err := Key(p.IProvider, o)
+	if err != nil {
+		return "", err
+	}
+
+	if mode != ModeRefresh {
+		response, ok, err := p.cache.Get(ctx, key)
+		if err != nil {
+			logError(p, "get", err)
+		}
+
+		if ok {
+			p.counterHit.Add(1)
+			metricRequests.Add(1, p.GetName(), o.Model, TypeExact, ResultHit)
+
+			return answer(response, o)
+		}
+	}
+
+	p.counterMiss.Add(1)
+	metricRequests.Add(1, p.GetName(), o.Model, TypeExact, ResultMiss)
+
+	response, err := p.IProvider.Completion(ctx, options...)
+	if err != nil {
+		return "", err
+	}
+
+	if err := p.cache.Set(ctx, key, response, p.ttl); err != nil {
+		logError(p, "set", err)
+	}
+
+	return response, nil
+}
+
+// GetCapabilities returns the capabilities of the wrapped provider, if any.
+func (p *Provider) GetCapabilities() provider.Capabilities {
+	c, _ := provider.CapabilitiesOf(p.IProvider)
+
+	return c
+}
+
+// GetDefaultModel returns the default model of the wrapped provider, if any.
+func (p *Provider) GetDefaultModel() string {
+	return provider.DefaultModel(p.IProvider)
+}
+
+// GetEndpoint returns the endpoint of the wrapped provider, if any.
+func (p *Provider) GetEndpoint() string {
+	return provider.EndpointOf(p.IProvider)
+}
+
+// GetInstance returns the instance name of the wrapped provider, if any.
+func (p *Provider) GetInstance() string {
+	if i, ok := p.IProvider.(provider.IInstance); ok {
+		return i.GetInstance()
+	}
+
+	return ""
+}
+
+// GetCache returns the cache.
+func (p *Provider) GetCache() Cache {
+	return p.cache
+}
+
+// GetCounterHit returns the cache hit metric.
+func (p *Provider) GetCounterHit() *expvar.Int {
+	return p.counterHit
+}
+
+// GetCounterMiss returns the cache miss metric.
+func (p *Provider) GetCounterMiss() *expvar.Int {
+	return p.counterMiss
+}
+
+//////
+// Helpers.
+//////
+
+// processOptions processes the options the same way the wrapped provider
+// does, and returns the cache mode. Processing has no side effects, e.g.: the
+// conversation isn't truncated, nor summarized, the wrapped provider does it
+// if the request isn't answered from the cache.
+func processOptions(p provider.IProvider, options ...provider.Func) (*provider.Options, Mode, error) {
+	o, err := provider.NewOptionsFrom(append(
+		[]provider.Func{
+			provider.WithProvider(p.GetName()),
+			provider.WithModel(provider.DefaultModel(p)),
+		},
+		options...,
+	)...)
+	if err != nil {
+		return nil, "", err
+	}
+
+	mode, _, err := provider.GetExtra[Mode](o, Name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return o, mode, nil
+}
+
+// answer answers the request with the cached response. If streaming, it's
+// streamed as a single chunk.
+func answer(response string, o *provider.Options) (string, error) {
+	if o.Usage != nil {
+		*o.Usage = provider.Usage{}
+	}
+
+	if o.StreamHandler != nil {
+		if err := o.StreamHandler(response); err != nil {
+			return "", err
+		}
+	}
+
+	// Optional response body processing.
+	if o.ResponseBody != nil {
+		if err := json.Unmarshal([]byte(response), o.ResponseBody); err != nil {
+			return "", customerror.NewFailedToError("unmarshal cached response", customerror.WithError(err))
+		}
+	}
+
+	return response, nil
+}
+
+// logError logs a cache error. A failing cache should not fail the
+// completion.
+func logError(p provider.IMeta, operation string, err error) {
+	p.GetLogger().PrintlnWithOptions(
+		level.Error,
+		fmt.Sprintf("Failed to %s cache entry", operation),
+		sypl.WithField("error", err),
+	)
+}
+
+//////
+// Factory.
+//////
+
+// New wraps the provider, caching responses in `c` for `ttl`. A zero ttl
+// means they don't expire.
+func New(p provider.IProvider, c Cache, ttl time.Duration) (*Provider, error) {
+	// Enforces IProvider interface implementation.
+	var _ provider.IProvider = (*Provider)(nil)
+
+	if p == nil {
+		return nil, customerror.NewRequiredError("provider")
+	}
+
+	if c == nil {
+		return nil, customerror.NewRequiredError("cache")
+	}
+
+	if ttl < 0 {
+		return nil, customerror.NewInvalidError("ttl, must be zero or positive")
+	}
+
+	counterHit, err := metrics.NewIntCounter(Name, provider.InstanceOf(p), ResultHit)
+	if err != nil {
+		return nil, err
+	}
+
+	counterMiss, err := metrics.NewIntCounter(Name, provider.InstanceOf(p), ResultMiss)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Provider{
+		IProvider: p,
+
+		cache: c,
+		ttl:   ttl,
+
+		counterHit:  counterHit,
+		counterMiss: counterMiss,
+	}, nil
+}
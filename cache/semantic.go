@@ -0,0 +1,248 @@
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/internal/metrics"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/sypl"
+	"github.com/thalesfsp/sypl/level"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// DefaultThreshold is a conservative cosine similarity threshold. Tune it per
+// embedding model, and use case.
+const DefaultThreshold = 0.95
+
+// EmbedFunc returns the embedding of the text.
+type EmbedFunc func(ctx context.Context, text string) ([]float64, error)
+
+// Embedder is implemented by providers able to embed texts, e.g.: Ollama, and
+// OpenAI.
+type Embedder interface {
+	Embed(ctx context.Context, model string, input ...string) ([][]float64, error)
+}
+
+// Semantic wraps a provider, answering prompts similar enough to previously
+// answered ones from the cache. Prompts are only compared with prompts sent
+// to the same provider, and model, with the same system messages. Errors
+// aren't cached.
+type Semantic struct {
+	provider.IProvider
+
+	embed     EmbedFunc
+	index     *Index
+	threshold float64
+	ttl       time.Duration
+
+	// Metrics.
+	counterHit  *expvar.Int
+	counterMiss *expvar.Int
+}
+
+//////
+// Methods.
+//////
+
+// Completion returns the cached response of the most similar prompt, if
+// similar enough, otherwise calls the wrapped provider, and indexes the
+// prompt, and its response. Use WithBypass, or WithRefresh to change that per
+// request. Cached responses have zero usage. If embedding fails, the wrapped
+// provider is called without caching.
+func (s *Semantic) Completion(ctx context.Context, options ...provider.Func) (string, error) {
+	o, mode, err := processOptions(s, options...)
+	if err != nil {
+		return "", err
+	}
+
+	if mode == ModeBypass {
+		metricRequests.Add(1, s.GetName(), o.Model, TypeSemantic, ResultBypass)
+
+		return s.IProvider.Completion(ctx, options...)
+	}
+
+	prompt := strings.Join(o.UserMessages, "\n")
+
+	vector, err := s.embed(ctx, prompt)
+	if err != nil {
+		logError(s, "embed", err)
+
+		return s.IProvider.Completion(ctx, options...)
+	}
+
+	scope := Scope(o)
+
+	if mode != ModeRefresh {
+		if match, ok := s.index.Nearest(scope, vector); ok && match.Similarity >= s.threshold {
+			s.counterHit.Add(1)
+			metricRequests.Add(1, s.GetName(), o.Model, TypeSemantic, ResultHit)
+
+			s.GetLogger().PrintlnWithOptions(
+				level.Debug,
+				"Semantic cache hit",
+				sypl.WithField("prompt", match.Prompt),
+				sypl.WithField("similarity", match.Similarity),
+			)
+
+			return answer(match.Response, o)
+		}
+	}
+
+	s.counterMiss.Add(1)
+	metricRequests.Add(1, s.GetName(), o.Model, TypeSemantic, ResultMiss)
+
+	response, err := s.IProvider.Completion(ctx, options...)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.index.Add(scope, prompt, vector, response, s.ttl); err != nil {
+		logError(s, "index", err)
+	}
+
+	return response, nil
+}
+
+// GetCapabilities returns the capabilities of the wrapped provider, if any.
+func (s *Semantic) GetCapabilities() provider.Capabilities {
+	c, _ := provider.CapabilitiesOf(s.IProvider)
+
+	return c
+}
+
+// GetDefaultModel returns the default model of the wrapped provider, if any.
+func (s *Semantic) GetDefaultModel() string {
+	return provider.DefaultModel(s.IProvider)
+}
+
+// GetEndpoint returns the endpoint of the wrapped provider, if any.
+func (s *Semantic) GetEndpoint() string {
+	return provider.EndpointOf(s.IProvider)
+}
+
+// GetInstance returns the instance name of the wrapped provider, if any.
+func (s *Semantic) GetInstance() string {
+	if i, ok := s.IProvider.(provider.IInstance); ok {
+		return i.GetInstance()
+	}
+
+	return ""
+}
+
+// GetIndex returns the index.
+func (s *Semantic) GetIndex() *Index {
+	return s.index
+}
+
+// GetCounterHit returns the cache hit metric.
+func (s *Semantic) GetCounterHit() *expvar.Int {
+	return s.counterHit
+}
+
+// GetCounterMiss returns the cache miss metric.
+func (s *Semantic) GetCounterMiss() *expvar.Int {
+	return s.counterMiss
+}
+
+//////
+// Exported functionalities.
+//////
+
+// EmbedWith returns an EmbedFunc embedding through the embedder, using the
+// embedding model.
+func EmbedWith(e Embedder, model string) EmbedFunc {
+	return func(ctx context.Context, text string) ([]float64, error) {
+		embeddings, err := e.Embed(ctx, model, text)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(embeddings) != 1 {
+			return nil, customerror.NewInvalidError(fmt.Sprintf("embeddings, expected 1, got %d", len(embeddings)))
+		}
+
+		return embeddings[0], nil
+	}
+}
+
+// Scope returns the semantic cache scope of the processed options: the
+// provider, the model, the system messages, and the history.
+func Scope(o *provider.Options) string {
+	data, _ := json.Marshal([]any{o.Provider, o.Model, o.SystemMessages, o.History})
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+//////
+// Factory.
+//////
+
+// NewSemantic wraps the provider, caching responses in the index for `ttl`,
+// and answering prompts whose cosine similarity to an answered one is at least
+// `threshold`. A nil index means a new, unbounded one. A zero ttl means they
+// don't expire.
+func NewSemantic(
+	p provider.IProvider,
+	embed EmbedFunc,
+	index *Index,
+	threshold float64,
+	ttl time.Duration,
+) (*Semantic, error) {
+	// Enforces IProvider interface implementation.
+	var _ provider.IProvider = (*Semantic)(nil)
+
+	if p == nil {
+		return nil, customerror.NewRequiredError("provider")
+	}
+
+	if embed == nil {
+		return nil, customerror.NewRequiredError("embed")
+	}
+
+	if threshold <= 0 || threshold > 1 {
+		return nil, customerror.NewInvalidError("threshold, must be in (0, 1]")
+	}
+
+	if ttl < 0 {
+		return nil, customerror.NewInvalidError("ttl, must be zero or positive")
+	}
+
+	if index == nil {
+		index = NewIndex(0)
+	}
+
+	counterHit, err := metrics.NewIntCounter(Name, provider.InstanceOf(p), TypeSemantic+"."+ResultHit)
+	if err != nil {
+		return nil, err
+	}
+
+	counterMiss, err := metrics.NewIntCounter(Name, provider.InstanceOf(p), TypeSemantic+"."+ResultMiss)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Semantic{
+		IProvider: p,
+
+		embed:     embed,
+		index:     index,
+		threshold: threshold,
+		ttl:       ttl,
+
+		counterHit:  counterHit,
+		counterMiss: counterMiss,
+	}, nil
+}
@@ -0,0 +1,95 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+// embedWords embeds texts as bags of known words.
+func embedWords(_ context.Context, text string) ([]float64, error) {
+	words := []string{"reset", "password", "my", "how", "do", "i", "weather"}
+	vector := make([]float64, len(words))
+
+	for _, w := range strings.Fields(strings.ToLower(strings.Trim(text, "?"))) {
+		for i, known := range words {
+			if w == known {
+				vector[i]++
+			}
+		}
+	}
+
+	return vector, nil
+}
+
+func TestSemantic(t *testing.T) {
+	ctx := context.Background()
+	e := newEcho(t)
+
+	s, err := NewSemantic(e, embedWords, nil, 0.9, 0)
+	assert.NoError(t, err)
+
+	_, err = NewSemantic(e, embedWords, nil, 1.5, 0)
+	assert.Error(t, err)
+
+	response, err := s.Completion(ctx, provider.WithUserMessages("how do i reset my password"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"response":"how do i reset my password"}`, response)
+
+	// Similar enough.
+	response, err = s.Completion(ctx, provider.WithUserMessages("How do I reset my password?"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{"response":"how do i reset my password"}`, response)
+	assert.Equal(t, 1, e.calls)
+
+	// Not similar enough.
+	_, err = s.Completion(ctx, provider.WithUserMessages("how do i reset"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, e.calls)
+
+	// Scoped per system messages, and model.
+	_, err = s.Completion(
+		ctx,
+		provider.WithSystemMessages("you are a pirate"),
+		provider.WithUserMessages("how do i reset my password"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, e.calls)
+
+	_, err = s.Completion(ctx, provider.WithModel("other"), provider.WithUserMessages("how do i reset my password"))
+	assert.NoError(t, err)
+	assert.Equal(t, 4, e.calls)
+
+	// Bypass.
+	_, err = s.Completion(ctx, provider.WithUserMessages("how do i reset my password"), WithBypass())
+	assert.NoError(t, err)
+	assert.Equal(t, 5, e.calls)
+	assert.Equal(t, 4, s.GetIndex().Len())
+
+	// Embedding failures don't fail the completion.
+	s, err = NewSemantic(e, func(context.Context, string) ([]float64, error) {
+		return nil, errors.New("boom")
+	}, nil, DefaultThreshold, 0)
+	assert.NoError(t, err)
+
+	_, err = s.Completion(ctx, provider.WithUserMessages("how do i reset my password"))
+	assert.NoError(t, err)
+	assert.Equal(t, 6, e.calls)
+}
+
+// embedder is a fake Embedder.
+type embedder struct{}
+
+func (embedder) Embed(_ context.Context, model string, input ...string) ([][]float64, error) {
+	return [][]float64{{float64(len(model)), float64(len(input[0]))}}, nil
+}
+
+func TestEmbedWith(t *testing.T) {
+	vector, err := EmbedWith(embedder{}, "abc")(context.Background(), "hello")
+	assert.NoError(t, err)
+	assert.Equal(t, []float64{3, 5}, vector)
+}
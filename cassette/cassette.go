@@ -0,0 +1,414 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Mode of a Recorder.
+type Mode string
+
+// Available modes.
+const (
+	// ModeOff passes requests through, without recording.
+	ModeOff Mode = "off"
+
+	// ModeRecord passes requests through, recording them. Saving overwrites
+	// the cassette.
+	ModeRecord Mode = "record"
+
+	// ModeReplay replays the cassette, failing requests not recorded. No
+	// request reaches the network.
+	ModeReplay Mode = "replay"
+
+	// ModeAuto replays the cassette if it exists, otherwise records it.
+	ModeAuto Mode = "auto"
+)
+
+// Redacted replaces scrubbed values.
+const Redacted = "REDACTED"
+
+// DefaultScrubHeaders are the headers scrubbed by default.
+var DefaultScrubHeaders = []string{
+	"Api-Key",
+	"Authorization",
+	"Cookie",
+	"Openai-Organization",
+	"Openai-Project",
+	"Proxy-Authorization",
+	"Set-Cookie",
+	"X-Api-Key",
+}
+
+// DefaultScrubQueryParams are the query parameters scrubbed by default.
+var DefaultScrubQueryParams = []string{
+	"access_token",
+	"api_key",
+	"key",
+	"token",
+}
+
+// Request is a recorded request.
+type Request struct {
+	Body    string      `json:"body,omitempty"`
+	Headers http.Header `json:"headers,omitempty"`
+	Method  string      `json:"method"`
+	URL     string      `json:"url"`
+}
+
+// Response is a recorded response.
+type Response struct {
+	Body       string      `json:"body,omitempty"`
+	Headers    http.Header `json:"headers,omitempty"`
+	StatusCode int         `json:"statusCode"`
+}
+
+// Interaction is a recorded request, and its response.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Cassette is the format of fixture files.
+type Cassette struct {
+	// Note about the cassette, e.g.: that it's synthetic, written by hand, not
+	// recorded. Recording it again drops it.
+	Note string `json:"note,omitempty"`
+
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Func allows to set recorder options.
+type Func func(r *Recorder) error
+
+// Recorder is a http.RoundTripper recording, or replaying HTTP exchanges. It's
+// safe for concurrent use.
+type Recorder struct {
+	mode      Mode
+	path      string
+	transport http.RoundTripper
+
+	scrubHeaders     []string
+	scrubQueryParams []string
+
+	mu       sync.Mutex
+	cassette Cassette
+	used     []bool
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithTransport sets the transport used to reach the network. Default to
+// http.DefaultTransport.
+func WithTransport(transport http.RoundTripper) Func {
+	return func(r *Recorder) error {
+		if transport != nil {
+			r.transport = transport
+		}
+
+		return nil
+	}
+}
+
+// WithScrubHeaders adds headers to be scrubbed.
+func WithScrubHeaders(headers ...string) Func {
+	return func(r *Recorder) error {
+		r.scrubHeaders = append(r.scrubHeaders, headers...)
+
+		return nil
+	}
+}
+
+// WithScrubQueryParams adds query parameters to be scrubbed.
+func WithScrubQueryParams(params ...string) Func {
+	return func(r *Recorder) error {
+		r.scrubQueryParams = append(r.scrubQueryParams, params...)
+
+		return nil
+	}
+}
+
+//////
+// Methods.
+//////
+
+// RoundTrip implements the http.RoundTripper interface.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	if r.mode == ModeOff {
+		return r.transport.RoundTrip(req)
+	}
+
+	body, err := readBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	recorded := Request{
+		Body:    string(body),
+		Headers: r.scrubHeader(req.Header),
+		Method:  req.Method,
+		URL:     r.scrubURL(req.URL),
+	}
+
+	if r.mode == ModeReplay {
+		return r.replay(req, recorded)
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	if err != nil {
+		return nil, customerror.NewFailedToError("read response body", customerror.WithError(err))
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.cassette.Interactions = append(r.cassette.Interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			Body:       string(respBody),
+			Headers:    r.scrubHeader(resp.Header),
+			StatusCode: resp.StatusCode,
+		},
+	})
+
+	return resp, nil
+}
+
+// Mode returns the effective mode.
+func (r *Recorder) Mode() Mode {
+	return r.mode
+}
+
+// Interactions returns the recorded, or loaded interactions.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.cassette.Interactions...)
+}
+
+// Save writes the recorded interactions to the cassette. It's a no-op unless
+// recording.
+func (r *Recorder) Save() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	data, err := json.MarshalIndent(r.cassette, "", "  ")
+	if err != nil {
+		return customerror.NewFailedToError("marshal cassette", customerror.WithError(err))
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0o755); err != nil {
+		return customerror.NewFailedToError("create cassette dir", customerror.WithError(err))
+	}
+
+	if err := os.WriteFile(r.path, append(data, '\n'), 0o644); err != nil {
+		return customerror.NewFailedToError("write cassette", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+//////
+// Helpers.
+//////
+
+// replay returns the response of the first unused interaction matching the
+// request.
+func (r *Recorder) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, interaction := range r.cassette.Interactions {
+		if r.used[i] || !matches(interaction.Request, recorded) {
+			continue
+		}
+
+		r.used[i] = true
+
+		return &http.Response{
+			Body:          io.NopCloser(strings.NewReader(interaction.Response.Body)),
+			ContentLength: int64(len(interaction.Response.Body)),
+			Header:        interaction.Response.Headers.Clone(),
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Request:       req,
+			Status:        fmt.Sprintf("%d %s", interaction.Response.StatusCode, http.StatusText(interaction.Response.StatusCode)),
+			StatusCode:    interaction.Response.StatusCode,
+		}, nil
+	}
+
+	return nil, customerror.NewMissingError(
+		fmt.Sprintf("interaction for %s %s in cassette %s", recorded.Method, recorded.URL, r.path),
+	)
+}
+
+// scrubHeader returns a copy of the header, with the credentials redacted.
+func (r *Recorder) scrubHeader(header http.Header) http.Header {
+	scrubbed := header.Clone()
+
+	for _, name := range r.scrubHeaders {
+		if scrubbed.Get(name) != "" {
+			scrubbed.Set(name, Redacted)
+		}
+	}
+
+	return scrubbed
+}
+
+// scrubURL returns the URL, with the credentials redacted.
+func (r *Recorder) scrubURL(u *url.URL) string {
+	scrubbed := *u
+	query := scrubbed.Query()
+
+	for _, name := range r.scrubQueryParams {
+		if query.Has(name) {
+			query.Set(name, Redacted)
+		}
+	}
+
+	scrubbed.RawQuery = query.Encode()
+	scrubbed.User = nil
+
+	return scrubbed.String()
+}
+
+// load loads the cassette.
+func (r *Recorder) load() error {
+	data, err := os.ReadFile(r.path)
+	if err != nil {
+		return customerror.NewFailedToError("read cassette", customerror.WithError(err))
+	}
+
+	if err := json.Unmarshal(data, &r.cassette); err != nil {
+		return customerror.NewFailedToError("unmarshal cassette", customerror.WithError(err))
+	}
+
+	r.used = make([]bool, len(r.cassette.Interactions))
+
+	return nil
+}
+
+// readBody reads the request body, and restores it.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+
+	if err != nil {
+		return nil, customerror.NewFailedToError("read request body", customerror.WithError(err))
+	}
+
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+// matches returns true if the requests have the same method, URL, and body.
+// JSON bodies are compared semantically.
+func matches(recorded, req Request) bool {
+	if recorded.Method != req.Method || recorded.URL != req.URL {
+		return false
+	}
+
+	if recorded.Body == req.Body {
+		return true
+	}
+
+	var a, b any
+
+	if json.Unmarshal([]byte(recorded.Body), &a) != nil || json.Unmarshal([]byte(req.Body), &b) != nil {
+		return false
+	}
+
+	aData, _ := json.Marshal(a)
+	bData, _ := json.Marshal(b)
+
+	return bytes.Equal(aData, bData)
+}
+
+//////
+// Factory.
+//////
+
+// New returns a recorder of the cassette at `path`, e.g.:
+// `testdata/cassettes/completion.json`. Replaying loads the cassette.
+func New(path string, mode Mode, options ...Func) (*Recorder, error) {
+	// Enforces http.RoundTripper interface implementation.
+	var _ http.RoundTripper = (*Recorder)(nil)
+
+	if path == "" {
+		return nil, customerror.NewRequiredError("path")
+	}
+
+	r := &Recorder{
+		mode:      mode,
+		path:      path,
+		transport: http.DefaultTransport,
+
+		scrubHeaders:     append([]string(nil), DefaultScrubHeaders...),
+		scrubQueryParams: append([]string(nil), DefaultScrubQueryParams...),
+	}
+
+	for _, option := range options {
+		if err := option(r); err != nil {
+			return nil, err
+		}
+	}
+
+	switch mode {
+	case ModeOff, ModeRecord:
+	case ModeAuto:
+		r.mode = ModeReplay
+
+		if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+			r.mode = ModeRecord
+		}
+	case ModeReplay:
+	default:
+		return nil, customerror.NewInvalidError("mode " + string(mode))
+	}
+
+	if r.mode == ModeReplay {
+		if err := r.load(); err != nil {
+			return nil, err
+		}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,97 @@
+package cassette
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecorder(t *testing.T) {
+	calls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+
+		body, _ := io.ReadAll(r.Body)
+
+		w.Header().Set("Set-Cookie", "session=secret")
+		_, _ = w.Write([]byte("echo: " + string(body)))
+	}))
+	defer server.Close()
+
+	path := filepath.Join(t.TempDir(), "cassettes", "echo.json")
+
+	do := func(r *Recorder, body string) (string, error) {
+		req, _ := http.NewRequest(http.MethodPost, server.URL+"/v1/echo?key=secret", strings.NewReader(body))
+		req.Header.Set("Authorization", "Bearer secret")
+
+		resp, err := (&http.Client{Transport: r}).Do(req)
+		if err != nil {
+			return "", err
+		}
+
+		defer resp.Body.Close()
+
+		respBody, _ := io.ReadAll(resp.Body)
+
+		return string(respBody), nil
+	}
+
+	// Replaying a missing cassette fails.
+	_, err := New(path, ModeReplay)
+	assert.Error(t, err)
+
+	// Auto records a missing cassette.
+	r, err := New(path, ModeAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, ModeRecord, r.Mode())
+
+	response, err := do(r, `{"a": 1, "b": 2}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `echo: {"a": 1, "b": 2}`, response)
+	assert.NoError(t, r.Save())
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(data), "secret")
+	assert.Contains(t, string(data), Redacted)
+
+	// Auto replays an existing cassette, without reaching the server.
+	r, err = New(path, ModeAuto)
+	assert.NoError(t, err)
+	assert.Equal(t, ModeReplay, r.Mode())
+
+	response, err = do(r, `{"b":2,"a":1}`)
+	assert.NoError(t, err)
+	assert.Equal(t, `echo: {"a": 1, "b": 2}`, response)
+	assert.Equal(t, 1, calls)
+
+	// Each interaction is replayed once.
+	_, err = do(r, `{"a": 1, "b": 2}`)
+	assert.Error(t, err)
+
+	// Unknown requests fail.
+	r, err = New(path, ModeReplay)
+	assert.NoError(t, err)
+
+	_, err = do(r, `{"a": 2}`)
+	assert.Error(t, err)
+
+	// Off passes through.
+	r, err = New(path, ModeOff)
+	assert.NoError(t, err)
+
+	_, err = do(r, `{}`)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+	assert.Empty(t, r.Interactions())
+
+	_, err = New(path, "rewind")
+	assert.Error(t, err)
+}
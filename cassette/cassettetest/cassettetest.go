@@ -0,0 +1,36 @@
+// Package cassettetest provides test helpers for the cassette package, apart
+// from it, so importers of cassette don't link the testing package.
+package cassettetest
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/thalesfsp/inference/cassette"
+	"github.com/thalesfsp/inference/internal/config"
+)
+
+// ForTest returns a recorder of the named cassette, stored in the configured
+// directory, in the configured mode, defaulting to replay. Recorded cassettes
+// are saved when the test ends.
+func ForTest(t testing.TB, name string, options ...cassette.Func) *cassette.Recorder {
+	t.Helper()
+
+	mode := cassette.Mode(config.Get().CassetteMode)
+	if mode == "" {
+		mode = cassette.ModeReplay
+	}
+
+	r, err := cassette.New(filepath.Join(config.Get().CassetteDir, name+".json"), mode, options...)
+	if err != nil {
+		t.Fatalf("cassette %s: %v", name, err)
+	}
+
+	t.Cleanup(func() {
+		if err := r.Save(); err != nil {
+			t.Errorf("cassette %s: %v", name, err)
+		}
+	})
+
+	return r
+}
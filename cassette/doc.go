@@ -0,0 +1,17 @@
+// Package cassette records HTTP exchanges to fixture files, and replays them
+// offline, making tests against provider APIs deterministic.
+//
+// Pass a Recorder as the transport of a provider, e.g.: in tests, through
+// cassettetest:
+//
+//	r := cassettetest.ForTest(t, "completion")
+//
+//	p, err := openai.NewDefault(provider.WithTransport(r))
+//
+// The mode is set through `CASSETTE_MODE` (`off`, `record`, `replay`, or
+// `auto`), and the fixtures directory through `CASSETTE_DIR`. Credentials are
+// scrubbed from the recorded headers, and query parameters.
+//
+// Cassettes written by hand, not recorded, say so in their Note, e.g.: the
+// ones of the built-in providers, which are synthetic.
+package cassette
@@ -0,0 +1,327 @@
+package catalog
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/validation"
+	"gopkg.in/yaml.v3"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// DateLayout is the layout of dates in the catalog.
+const DateLayout = "2006-01-02"
+
+// Supported formats.
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// Built-in models.
+//
+//go:embed models.json
+var builtIn []byte
+
+// variantSuffix matches the suffixes of the variants of a model, e.g.:
+// dated snapshots (`-2024-08-06`, `-20241022`, `-0613`), aliases (`-latest`,
+// `-preview`), or Ollama tags (`:3b`), not other versions.
+var variantSuffix = regexp.MustCompile(`^((-(latest|preview|\d{4}-\d{2}-\d{2}|\d{8}|\d{4}))+|:.+)$`)
+
+// Singleton.
+var (
+	once      sync.Once
+	singleton *Catalog
+)
+
+// Model describes a known model.
+type Model struct {
+	// CacheReadPrice is the price, in USD, per million input tokens read from
+	// the prompt cache. Default to InputPrice.
+	CacheReadPrice float64 `json:"cacheReadPrice,omitempty" yaml:"cacheReadPrice,omitempty" validate:"gte=0"`
+
+	// CacheWritePrice is the price, in USD, per million input tokens written
+	// to the prompt cache. Default to InputPrice.
+	CacheWritePrice float64 `json:"cacheWritePrice,omitempty" yaml:"cacheWritePrice,omitempty" validate:"gte=0"`
+
+	// ContextWindow is the maximum amount of tokens (input + output). Zero
+	// means unknown.
+	ContextWindow int `json:"contextWindow,omitempty" yaml:"contextWindow,omitempty" validate:"gte=0"`
+
+	// DeprecatedAt is the date (YYYY-MM-DD) when the model is, or was
+	// deprecated.
+	DeprecatedAt string `json:"deprecatedAt,omitempty" yaml:"deprecatedAt,omitempty"`
+
+	// InputPrice is the price, in USD, per million input tokens.
+	InputPrice float64 `json:"inputPrice,omitempty" yaml:"inputPrice,omitempty" validate:"gte=0"`
+
+	// MaxOutputTokens is the maximum amount of tokens the model can output.
+	// Zero means unknown.
+	MaxOutputTokens int `json:"maxOutputTokens,omitempty" yaml:"maxOutputTokens,omitempty" validate:"gte=0"`
+
+	// Modalities are the input modalities, e.g.: "text", "image".
+	Modalities []string `json:"modalities,omitempty" yaml:"modalities,omitempty"`
+
+	// Name of the model. Its dated, aliased, or tagged variants, e.g.:
+	// `gpt-4o-2024-08-06`, `gpt-4.5-preview`, or `llama3.2:3b`, match it too.
+	Name string `json:"name" yaml:"name" validate:"required"`
+
+	// OutputPrice is the price, in USD, per million output tokens.
+	OutputPrice float64 `json:"outputPrice,omitempty" yaml:"outputPrice,omitempty" validate:"gte=0"`
+
+	// Provider name, e.g.: "openai".
+	Provider string `json:"provider" yaml:"provider" validate:"required"`
+}
+
+// File is the format of catalog files.
+type File struct {
+	Models []Model `json:"models" yaml:"models" validate:"dive"`
+}
+
+// Catalog of known models, safe for concurrent use.
+type Catalog struct {
+	mu     sync.RWMutex
+	models map[string]Model
+}
+
+//////
+// Model methods.
+//////
+
+// Cost returns the cost, in USD, of the amount of input, and output tokens.
+func (m Model) Cost(inputTokens, outputTokens int) float64 {
+	return (float64(inputTokens)*m.InputPrice + float64(outputTokens)*m.OutputPrice) / 1_000_000
+}
+
+// CacheCost returns the cost, in USD, of the amount of input tokens written
+// to, and read from the prompt cache.
+func (m Model) CacheCost(writeTokens, readTokens int) float64 {
+	writePrice, readPrice := m.CacheWritePrice, m.CacheReadPrice
+
+	if writePrice == 0 {
+		writePrice = m.InputPrice
+	}
+
+	if readPrice == 0 {
+		readPrice = m.InputPrice
+	}
+
+	return (float64(writeTokens)*writePrice + float64(readTokens)*readPrice) / 1_000_000
+}
+
+// IsDeprecated returns true if the model is deprecated at `t`.
+func (m Model) IsDeprecated(t time.Time) bool {
+	if m.DeprecatedAt == "" {
+		return false
+	}
+
+	deprecatedAt, err := time.Parse(DateLayout, m.DeprecatedAt)
+	if err != nil {
+		return false
+	}
+
+	return !t.Before(deprecatedAt)
+}
+
+//////
+// Catalog methods.
+//////
+
+// Register adds, or replaces models.
+func (c *Catalog) Register(models ...Model) error {
+	for _, m := range models {
+		if err := validation.Validate(&m); err != nil {
+			return err
+		}
+
+		if m.DeprecatedAt != "" {
+			if _, err := time.Parse(DateLayout, m.DeprecatedAt); err != nil {
+				return customerror.NewInvalidError("deprecatedAt", customerror.WithError(err))
+			}
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, m := range models {
+		c.models[key(m.Provider, m.Name)] = m
+	}
+
+	return nil
+}
+
+// Lookup finds the model of the provider. Exact names win, otherwise the
+// longest name the model is a variant of, e.g.: `gpt-4o-2024-08-06` of
+// `gpt-4o`, but not `gpt-4.5-preview` of `gpt-4`.
+func (c *Catalog) Lookup(provider, model string) (Model, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if m, ok := c.models[key(provider, model)]; ok {
+		return m, true
+	}
+
+	var (
+		found Model
+		ok    bool
+	)
+
+	for _, m := range c.models {
+		suffix, isPrefix := strings.CutPrefix(model, m.Name)
+		if m.Provider != provider || !isPrefix || !variantSuffix.MatchString(suffix) {
+			continue
+		}
+
+		if len(m.Name) > len(found.Name) {
+			found = m
+			ok = true
+		}
+	}
+
+	return found, ok
+}
+
+// Has returns true if the catalog knows any model of the provider.
+func (c *Catalog) Has(provider string) bool {
+	return len(c.Models(provider)) > 0
+}
+
+// Models returns the models of the provider, sorted by name. If provider is
+// empty, all models are returned.
+func (c *Catalog) Models(provider string) []Model {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	models := []Model{}
+
+	for _, m := range c.models {
+		if provider == "" || m.Provider == provider {
+			models = append(models, m)
+		}
+	}
+
+	sort.Slice(models, func(i, j int) bool {
+		return key(models[i].Provider, models[i].Name) < key(models[j].Provider, models[j].Name)
+	})
+
+	return models
+}
+
+// Load adds, or replaces the models in `data` of the given format.
+func (c *Catalog) Load(data []byte, format string) error {
+	var f File
+
+	switch format {
+	case JSON:
+		if err := json.Unmarshal(data, &f); err != nil {
+			return customerror.NewFailedToError("unmarshal catalog", customerror.WithError(err))
+		}
+	case YAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+		decoder.KnownFields(true)
+
+		if err := decoder.Decode(&f); err != nil {
+			return customerror.NewFailedToError("unmarshal catalog", customerror.WithError(err))
+		}
+	default:
+		return customerror.NewInvalidError("format " + format)
+	}
+
+	return c.Register(f.Models...)
+}
+
+// LoadFile adds, or replaces the models in the file. Format is inferred from
+// the extension (`.json`, `.yaml`, or `.yml`).
+func (c *Catalog) LoadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return customerror.NewFailedToError("read catalog", customerror.WithError(err))
+	}
+
+	format := JSON
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = YAML
+	}
+
+	return c.Load(data, format)
+}
+
+//////
+// Helpers.
+//////
+
+// key builds the catalog key.
+func key(provider, model string) string {
+	return provider + "/" + model
+}
+
+//////
+// Factory.
+//////
+
+// New returns an empty catalog, optionally with models.
+func New(models ...Model) (*Catalog, error) {
+	c := &Catalog{models: make(map[string]Model)}
+
+	if err := c.Register(models...); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// NewDefault returns a catalog with the built-in models.
+func NewDefault() (*Catalog, error) {
+	c, err := New()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.Load(builtIn, JSON); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Get returns the default catalog, initialized with the built-in models.
+// Changes to it affect the whole application.
+func Get() *Catalog {
+	once.Do(func() {
+		c, err := NewDefault()
+		if err != nil {
+			panic(err)
+		}
+
+		singleton = c
+	})
+
+	return singleton
+}
+
+// Set sets the default catalog.
+func Set(c *Catalog) {
+	once.Do(func() {})
+
+	singleton = c
+}
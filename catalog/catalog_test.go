@@ -0,0 +1,94 @@
+package catalog
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalog(t *testing.T) {
+	c, err := NewDefault()
+	assert.NoError(t, err)
+
+	t.Run("Lookup", func(t *testing.T) {
+		m, ok := c.Lookup("openai", "gpt-4o-2024-08-06")
+		assert.True(t, ok)
+		assert.Equal(t, "gpt-4o", m.Name)
+
+		m, ok = c.Lookup("openai", "gpt-4o-mini")
+		assert.True(t, ok)
+		assert.Equal(t, "gpt-4o-mini", m.Name)
+
+		m, ok = c.Lookup("ollama", "llama3.2:3b")
+		assert.True(t, ok)
+		assert.Equal(t, 131072, m.ContextWindow)
+
+		_, ok = c.Lookup("anthropic", "gpt-4o")
+		assert.False(t, ok)
+
+		// Variants, not other versions.
+		m, ok = c.Lookup("openai", "gpt-4.5-preview")
+		assert.True(t, ok)
+		assert.Equal(t, "gpt-4.5", m.Name)
+
+		m, ok = c.Lookup("openai", "gpt-4-0613")
+		assert.True(t, ok)
+		assert.Equal(t, "gpt-4", m.Name)
+
+		_, ok = c.Lookup("openai", "gpt-4-32k")
+		assert.False(t, ok)
+
+		_, ok = c.Lookup("ollama", "llama3.2-vision")
+		assert.False(t, ok)
+	})
+
+	t.Run("Cost", func(t *testing.T) {
+		m, _ := c.Lookup("openai", "gpt-4o")
+		assert.InDelta(t, 0.0125, m.Cost(1000, 1000), 1e-9)
+
+		// Without cache prices, cache tokens are priced as input tokens.
+		assert.InDelta(t, 0.005, m.CacheCost(1000, 1000), 1e-9)
+
+		m, _ = c.Lookup("anthropic", "claude-3-5-sonnet-20241022")
+		assert.InDelta(t, 0.00405, m.CacheCost(1000, 1000), 1e-9)
+	})
+
+	t.Run("IsDeprecated", func(t *testing.T) {
+		m, _ := c.Lookup("anthropic", "claude-3-sonnet-20240229")
+		assert.False(t, m.IsDeprecated(time.Date(2025, 7, 20, 0, 0, 0, 0, time.UTC)))
+		assert.True(t, m.IsDeprecated(time.Date(2025, 7, 21, 0, 0, 0, 0, time.UTC)))
+	})
+
+	t.Run("LoadFile", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "models.yaml")
+
+		assert.NoError(t, os.WriteFile(path, []byte(`
+models:
+  - provider: openai
+    name: gpt-4o
+    contextWindow: 128000
+    maxOutputTokens: 16384
+    inputPrice: 1
+    outputPrice: 2
+  - provider: custom
+    name: my-model
+    contextWindow: 4096
+`), 0o600))
+
+		assert.NoError(t, c.LoadFile(path))
+
+		m, _ := c.Lookup("openai", "gpt-4o")
+		assert.InDelta(t, 1.0, m.InputPrice, 1e-9)
+		assert.True(t, c.Has("custom"))
+		assert.Len(t, c.Models("custom"), 1)
+	})
+
+	t.Run("Invalid", func(t *testing.T) {
+		assert.Error(t, c.Load([]byte(`{"models":[{"provider":"openai"}]}`), JSON))
+		assert.Error(t, c.Load([]byte(`{"models":[{"provider":"openai","name":"x","deprecatedAt":"soon"}]}`), JSON))
+		assert.Error(t, c.Load([]byte(`models: []`), "toml"))
+	})
+}
@@ -0,0 +1,2 @@
+// Package catalog provides a catalog of known models.
+package catalog
@@ -0,0 +1,183 @@
+// Command inference-gateway serves the configured providers through an
+// OpenAI-compatible HTTP API, and their metrics in the Prometheus text format.
+//
+// Providers are configured, in order of precedence, by the `-config` file,
+// the `INFERENCE_` environment variables, see the registry package, or from
+// the environment, see `internal/config`: OpenAI, Anthropic, and HuggingFace
+// if their API key is set, and Ollama if `-ollama` is set.
+//
+//	OPENAI_API_KEY=... ANTHROPIC_API_KEY=... inference-gateway -addr :8080
+//
+//	inference-gateway -config providers.yaml
+//
+//	curl localhost:8080/v1/chat/completions -d '{
+//		"model": "claude-3-5-haiku-latest",
+//		"messages": [{"role": "user", "content": "Hi"}]
+//	}'
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/anthropic"
+	"github.com/thalesfsp/inference/gateway"
+	"github.com/thalesfsp/inference/huggingface"
+	"github.com/thalesfsp/inference/internal/config"
+	"github.com/thalesfsp/inference/ollama"
+	"github.com/thalesfsp/inference/openai"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/registry"
+)
+
+// PathMetrics is where the metrics are served.
+const PathMetrics = "/metrics"
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	configPath := flag.String("config", "", "providers configuration file, YAML, or JSON")
+	defaultProvider := flag.String("default-provider", "", "provider of the models no other rule routes")
+	maxTokens := flag.Int("max-tokens", 0, "default maximum amount of tokens to generate")
+	withOllama := flag.Bool("ollama", false, "serve the Ollama models, from OLLAMA_ENDPOINT")
+
+	var routes []gateway.Func
+
+	flag.Func("route", "route a `model=provider`, repeatable, e.g.: gpt-*=openai", func(value string) error {
+		model, key, ok := strings.Cut(value, "=")
+		if !ok {
+			return customerror.NewInvalidError(fmt.Sprintf("route %q, expected model=provider", value))
+		}
+
+		routes = append(routes, gateway.WithRoute(model, key))
+
+		return nil
+	})
+
+	flag.Parse()
+
+	providers, err := newProviders(*configPath, *withOllama)
+	if err != nil {
+		log.Fatalln("Failed to create the providers:", err)
+	}
+
+	g, err := gateway.New(
+		providers,
+		append(
+			routes,
+			gateway.WithDefaultProvider(*defaultProvider),
+			gateway.WithOptions(provider.WithMaxToken(*maxTokens)),
+		)...,
+	)
+	if err != nil {
+		log.Fatalln("Failed to create the gateway:", err)
+	}
+
+	mux := http.NewServeMux()
+
+	mux.Handle("/", g)
+	mux.Handle(PathMetrics, provider.MetricsHandler())
+
+	server := &http.Server{
+		Addr:              *addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	go func() {
+		<-ctx.Done()
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), config.Get().TimeoutLong)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Println("Failed to shutdown:", err)
+		}
+	}()
+
+	log.Printf("Serving %s on %s", providers, *addr)
+
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		log.Fatalln("Failed to serve:", err)
+	}
+}
+
+// newProviders creates the providers of the configuration file, or the
+// environment.
+func newProviders(configPath string, withOllama bool) (provider.Map, error) {
+	if configPath != "" {
+		c, err := registry.LoadFile(configPath)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.Build()
+	}
+
+	if os.Getenv(registry.DefaultEnvPrefix+"_PROVIDERS") != "" {
+		c, err := registry.LoadEnv(registry.DefaultEnvPrefix)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.Build()
+	}
+
+	cfg := config.Get()
+
+	providers := provider.Map{}
+
+	if cfg.OpenAIToken != "" {
+		p, err := openai.NewDefault()
+		if err != nil {
+			return nil, err
+		}
+
+		providers[openai.Name] = p
+	}
+
+	if cfg.AnthropicToken != "" {
+		p, err := anthropic.NewDefault()
+		if err != nil {
+			return nil, err
+		}
+
+		providers[anthropic.Name] = p
+	}
+
+	if cfg.HuggingFaceToken != "" {
+		p, err := huggingface.NewDefault()
+		if err != nil {
+			return nil, err
+		}
+
+		providers[huggingface.Name] = p
+	}
+
+	if withOllama {
+		p, err := ollama.NewDefault()
+		if err != nil {
+			return nil, err
+		}
+
+		providers[ollama.Name] = p
+	}
+
+	if len(providers) == 0 {
+		return nil, customerror.NewMissingError("provider, set an API key, or -ollama")
+	}
+
+	return providers, nil
+}
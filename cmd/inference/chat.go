@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Commands of the chat.
+const (
+	chatExit  = "/exit"
+	chatReset = "/reset"
+)
+
+// maxLineSize is the maximum size, in bytes, of a chat message.
+const maxLineSize = 1 << 20
+
+//////
+// Commands.
+//////
+
+// chat runs an interactive conversation, a message per line of stdin, keeping
+// the history. Failed turns aren't kept, thus can be retried.
+func chat(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var (
+		f  flags
+		cf clientFlags
+	)
+
+	fs := newFlagSet("chat", "", stderr)
+
+	f.register(fs)
+	f.registerModel(fs)
+	cf.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	name, err := defaultProvider(cf.provider)
+	if err != nil {
+		return err
+	}
+
+	p, err := newProvider(name, cf.clientOptions()...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := f.context(ctx)
+	defer cancel()
+
+	if !f.json {
+		fmt.Fprintf(stderr, "Chatting with %s (%s), %s to start over, %s, or Ctrl-D to quit.\n",
+			name, modelOf(f.model, p), chatReset, chatExit)
+	}
+
+	history := []message.Message{}
+
+	scanner := bufio.NewScanner(stdin)
+
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	for {
+		if !f.json {
+			fmt.Fprint(stderr, "> ")
+		}
+
+		if !scanner.Scan() {
+			break
+		}
+
+		line := strings.TrimSpace(scanner.Text())
+
+		switch line {
+		case "":
+			continue
+		case chatExit:
+			return nil
+		case chatReset:
+			history = history[:0]
+
+			continue
+		}
+
+		out := Output{Model: modelOf(f.model, p), Provider: name}
+
+		now := time.Now()
+
+		response, err := p.Completion(ctx, slices.Concat(
+			f.options(),
+			f.streamTo(stdout),
+			[]provider.Func{
+				provider.WithHistory(history...),
+				provider.WithUserMessages(line),
+				provider.WithUsage(&out.Usage),
+			},
+		)...)
+
+		out.Duration = time.Since(now)
+		out.Response = response
+
+		if err != nil {
+			out.Error = err.Error()
+
+			// Done, e.g.: interrupted, or timed out.
+			if ctx.Err() != nil {
+				return err
+			}
+		} else {
+			history = append(history,
+				message.Message{Content: line, Role: message.User},
+				message.Message{Content: response, Role: message.Assistant},
+			)
+		}
+
+		switch {
+		case f.json:
+			if err := writeJSON(stdout, out); err != nil {
+				return err
+			}
+		case err != nil:
+			fmt.Fprintln(stderr, err)
+		case f.stream:
+			fmt.Fprintln(stdout)
+		default:
+			fmt.Fprintln(stdout, response)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return customerror.NewFailedToError("read the message", customerror.WithError(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// columnSeparator separates the columns.
+const columnSeparator = " | "
+
+// minColumnWidth is the minimum width of a column.
+const minColumnWidth = 20
+
+//////
+// Commands.
+//////
+
+// compare runs the prompt against several providers, concurrently, and prints
+// the responses side by side. It fails if all providers fail.
+func compare(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var f flags
+
+	fs := newFlagSet("compare", "[prompt]", stderr)
+
+	f.register(fs)
+
+	providers := fs.String(
+		"providers",
+		"",
+		"comma-separated `provider[=model]`, e.g.: openai=gpt-4o-mini,anthropic, default to the configured ones",
+	)
+	width := fs.Int("width", 160, "width of the output, in columns")
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	prompt, err := readPrompt(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	m, models, err := newMap(*providers)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := f.context(ctx)
+	defer cancel()
+
+	// Responses are printed once all done.
+	f.stream = false
+
+	results := m.CompletionEach(ctx, append(f.options(), provider.WithUserMessages(prompt))...)
+
+	names := make([]string, 0, len(m))
+
+	for name := range m {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	outputs := make([]Output, 0, len(names))
+	failed := 0
+
+	for _, name := range names {
+		r, ok := results[name]
+		if !ok {
+			r.Error = "not called"
+		}
+
+		if r.Error != "" {
+			failed++
+		}
+
+		outputs = append(outputs, Output{Model: models[name], Provider: name, Result: r})
+	}
+
+	if f.json {
+		if err := writeJSON(stdout, outputs); err != nil {
+			return err
+		}
+	} else {
+		sideBySide(stdout, *width, outputs)
+	}
+
+	if failed == len(outputs) {
+		return customerror.NewFailedToError("complete, all providers failed")
+	}
+
+	return nil
+}
+
+//////
+// Helpers.
+//////
+
+// newMap creates the providers of the comma-separated `provider[=model]`
+// list, or the configured ones, and returns them with their model.
+func newMap(list string) (provider.Map, map[string]string, error) {
+	specs := configured()
+
+	if list != "" {
+		specs = strings.Split(list, ",")
+	}
+
+	if len(specs) == 0 {
+		return nil, nil, customerror.NewMissingError("providers, set -providers, or an API key")
+	}
+
+	m := provider.Map{}
+	models := make(map[string]string)
+
+	for _, spec := range specs {
+		name, model, _ := strings.Cut(strings.TrimSpace(spec), "=")
+
+		if _, ok := m[name]; ok {
+			return nil, nil, customerror.NewInvalidError(fmt.Sprintf("providers, %s is repeated", name))
+		}
+
+		options := []provider.ClientFunc{}
+
+		if model != "" {
+			options = append(options, provider.WithDefaulModel(model))
+		}
+
+		p, err := newProvider(name, options...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		m[name] = p
+		models[name] = modelOf(model, p)
+	}
+
+	return m, models, nil
+}
+
+// sideBySide prints the outputs in columns: a header with the provider, and
+// the model, the response, or the error, and the usage.
+func sideBySide(w io.Writer, width int, outputs []Output) {
+	separators := utf8.RuneCountInString(columnSeparator) * (len(outputs) - 1)
+	columnWidth := max(minColumnWidth, (width-separators)/len(outputs))
+
+	columns := make([][]string, 0, len(outputs))
+	rows := 0
+
+	for _, o := range outputs {
+		header := o.Provider
+		if o.Model != "" {
+			header += " (" + o.Model + ")"
+		}
+
+		text := o.Response
+		if o.Error != "" {
+			text = "Error: " + o.Error
+		}
+
+		footer := fmt.Sprintf(
+			"%s, %d in, %d out, $%.6f",
+			o.Duration.Round(time.Millisecond), o.Usage.InputTokens, o.Usage.OutputTokens, o.Usage.Cost,
+		)
+
+		column := wrap(header, columnWidth)
+		column = append(column, strings.Repeat("-", columnWidth))
+		column = append(column, wrap(text, columnWidth)...)
+		column = append(column, strings.Repeat("-", columnWidth))
+		column = append(column, wrap(footer, columnWidth)...)
+
+		columns = append(columns, column)
+		rows = max(rows, len(column))
+	}
+
+	for i := range rows {
+		// Up to the last column with a line.
+		last := 0
+
+		for j, column := range columns {
+			if i < len(column) {
+				last = j
+			}
+		}
+
+		cells := make([]string, last+1)
+
+		for j := range cells {
+			if i < len(columns[j]) {
+				cells[j] = columns[j][i]
+			}
+
+			cells[j] = fmt.Sprintf("%-*s", columnWidth, cells[j])
+		}
+
+		fmt.Fprintln(w, strings.TrimRight(strings.Join(cells, columnSeparator), " "))
+	}
+}
+
+// wrap wraps the text to lines of up to `width` runes, breaking words longer
+// than that.
+func wrap(text string, width int) []string {
+	lines := []string{}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		line := ""
+
+		for _, word := range strings.Fields(paragraph) {
+			for utf8.RuneCountInString(word) > width {
+				if line != "" {
+					lines = append(lines, line)
+					line = ""
+				}
+
+				runes := []rune(word)
+
+				lines = append(lines, string(runes[:width]))
+				word = string(runes[width:])
+			}
+
+			switch {
+			case line == "":
+				line = word
+			case utf8.RuneCountInString(line)+1+utf8.RuneCountInString(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Commands.
+//////
+
+// complete runs a one-shot completion of the prompt, the arguments, or stdin
+// if none, or `-`.
+func complete(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	var (
+		f  flags
+		cf clientFlags
+	)
+
+	fs := newFlagSet("complete", "[prompt]", stderr)
+
+	f.register(fs)
+	f.registerModel(fs)
+	cf.register(fs)
+
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	prompt, err := readPrompt(fs.Args(), stdin)
+	if err != nil {
+		return err
+	}
+
+	name, err := defaultProvider(cf.provider)
+	if err != nil {
+		return err
+	}
+
+	p, err := newProvider(name, cf.clientOptions()...)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := f.context(ctx)
+	defer cancel()
+
+	out := Output{Model: modelOf(f.model, p), Provider: name}
+
+	now := time.Now()
+
+	response, err := p.Completion(ctx, slices.Concat(
+		f.options(),
+		f.streamTo(stdout),
+		[]provider.Func{
+			provider.WithUserMessages(prompt),
+			provider.WithUsage(&out.Usage),
+		},
+	)...)
+
+	out.Duration = time.Since(now)
+	out.Response = response
+
+	if err != nil {
+		out.Error = err.Error()
+	}
+
+	switch {
+	case f.json:
+		if err := writeJSON(stdout, out); err != nil {
+			return err
+		}
+	case f.stream:
+		fmt.Fprintln(stdout)
+	case err == nil:
+		fmt.Fprintln(stdout, response)
+	}
+
+	return err
+}
+
+//////
+// Helpers.
+//////
+
+// readPrompt returns the prompt: the arguments, joined, or stdin if none, or
+// `-`.
+func readPrompt(args []string, stdin io.Reader) (string, error) {
+	prompt := strings.Join(args, " ")
+
+	if len(args) == 0 || prompt == "-" {
+		b, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", customerror.NewFailedToError("read the prompt", customerror.WithError(err))
+		}
+
+		prompt = strings.TrimSpace(string(b))
+	}
+
+	if prompt == "" {
+		return "", customerror.NewRequiredError("prompt")
+	}
+
+	return prompt, nil
+}
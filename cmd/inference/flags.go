@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// list is a repeatable string flag.
+type list []string
+
+// flags are the flags common to all commands, mapping to the completion
+// options.
+type flags struct {
+	budget       float64
+	contextLimit int
+	json         bool
+	jsonResponse bool
+	maxTokens    int
+	model        string
+	seed         int
+	stop         list
+	stream       bool
+	strict       bool
+	system       list
+	temperature  float64
+	timeout      time.Duration
+	topK         int
+	topP         float64
+	truncate     bool
+}
+
+// clientFlags are the flags of the commands using a single provider.
+type clientFlags struct {
+	endpoint string
+	provider string
+	token    string
+}
+
+//////
+// Methods.
+//////
+
+// String implements the flag.Value interface.
+func (l *list) String() string {
+	return strings.Join(*l, ", ")
+}
+
+// Set implements the flag.Value interface.
+func (l *list) Set(value string) error {
+	*l = append(*l, value)
+
+	return nil
+}
+
+// register registers the flags in the set.
+func (f *flags) register(fs *flag.FlagSet) {
+	fs.Float64Var(&f.budget, "budget", 0, "maximum cost, in USD, failing requests which would exceed it")
+	fs.IntVar(&f.contextLimit, "context-limit", 0, "context window, in tokens, overriding the model's")
+	fs.BoolVar(&f.json, "json", false, "output JSON, for scripting")
+	fs.BoolVar(&f.jsonResponse, "json-response", false, "fail if the response isn't valid JSON")
+	fs.IntVar(&f.maxTokens, "max-tokens", 0, "maximum amount of tokens to generate")
+	fs.IntVar(&f.seed, "seed", 0, "seed, for deterministic sampling")
+	fs.Var(&f.stop, "stop", "stop sequence, repeatable")
+	fs.BoolVar(&f.stream, "stream", false, "print the response as it's generated")
+	fs.BoolVar(&f.strict, "strict", false, "fail on options the provider doesn't support, instead of dropping them")
+	fs.Var(&f.system, "system", "system message, repeatable")
+	fs.Float64Var(&f.temperature, "temperature", 0, "sampling temperature")
+	fs.DurationVar(&f.timeout, "timeout", 0, "timeout of the command, e.g.: 30s")
+	fs.IntVar(&f.topK, "top-k", 0, "top K sampling")
+	fs.Float64Var(&f.topP, "top-p", 0, "top P (nucleus) sampling")
+	fs.BoolVar(&f.truncate, "truncate", true, "truncate the conversation to fit the context window")
+}
+
+// registerModel registers the model flag in the set.
+func (f *flags) registerModel(fs *flag.FlagSet) {
+	fs.StringVar(&f.model, "model", "", "model, default to the provider's")
+}
+
+// register registers the flags in the set.
+func (f *clientFlags) register(fs *flag.FlagSet) {
+	fs.StringVar(&f.endpoint, "endpoint", "", "endpoint, default to the configured one")
+	fs.StringVar(&f.provider, "provider", "", "provider: "+strings.Join(Providers, ", ")+", default to the first configured")
+	fs.StringVar(&f.token, "token", "", "token, default to the configured one")
+}
+
+// options returns the completion options set by the flags.
+func (f *flags) options() []provider.Func {
+	options := []provider.Func{
+		provider.WithContextLimit(f.contextLimit),
+		provider.WithMaxToken(f.maxTokens),
+		provider.WithModel(f.model),
+		provider.WithSeed(f.seed),
+		provider.WithStop(f.stop...),
+		provider.WithStrict(f.strict),
+		provider.WithTemperature(f.temperature),
+		provider.WithTopK(f.topK),
+		provider.WithTopP(f.topP),
+		provider.WithTruncation(f.truncate),
+	}
+
+	if len(f.system) > 0 {
+		options = append(options, provider.WithSystemMessages(f.system...))
+	}
+
+	// A target per call, as compare's run concurrently.
+	if f.jsonResponse {
+		options = append(options, func(o *provider.Options) error {
+			var v any
+
+			return provider.WithResponseBody(&v)(o)
+		})
+	}
+
+	return options
+}
+
+// streamTo streams the response to `w`, if asked, and not outputting JSON.
+func (f *flags) streamTo(w io.Writer) []provider.Func {
+	if !f.stream || f.json {
+		return nil
+	}
+
+	return []provider.Func{provider.WithStreamHandler(func(chunk string) error {
+		_, err := io.WriteString(w, chunk)
+
+		return err
+	})}
+}
+
+// context returns the context, with the timeout, and the budget, if set.
+func (f *flags) context(ctx context.Context) (context.Context, context.CancelFunc) {
+	if f.budget > 0 {
+		ctx = provider.ContextWithBudget(ctx, provider.NewBudget(f.budget))
+	}
+
+	if f.timeout > 0 {
+		return context.WithTimeout(ctx, f.timeout)
+	}
+
+	return context.WithCancel(ctx)
+}
+
+// clientOptions returns the client options set by the flags.
+func (f *clientFlags) clientOptions() []provider.ClientFunc {
+	options := []provider.ClientFunc{}
+
+	if f.endpoint != "" {
+		options = append(options, provider.WithEndpoint(f.endpoint))
+	}
+
+	if f.token != "" {
+		options = append(options, provider.WithToken(f.token))
+	}
+
+	return options
+}
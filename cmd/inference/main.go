@@ -0,0 +1,139 @@
+// Command inference runs ad-hoc completions against the providers, configured
+// from the environment, see `internal/config`.
+//
+// Usage:
+//
+//	inference complete [flags] [prompt]  one-shot, the prompt default to stdin
+//	inference chat [flags]               interactive conversation
+//	inference compare [flags] [prompt]   same prompt, several providers
+//
+// Examples:
+//
+//	inference complete -provider anthropic -max-tokens 64 "Why is the sky blue?"
+//	git diff | inference complete -system "Write a commit message" -json
+//	inference compare -providers openai=gpt-4o-mini,anthropic "Hi"
+//
+// Run `inference <command> -h` for the flags of a command.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// usage of the command.
+const usage = `Usage: inference <command> [flags] [prompt]
+
+Commands:
+  complete  one-shot completion, the prompt default to stdin
+  chat      interactive conversation, with history
+  compare   same prompt, several providers, side by side
+
+Run "inference <command> -h" for the flags of a command.
+`
+
+// Output is the JSON output of a completion.
+type Output struct {
+	provider.Result
+
+	// Model used, if known.
+	Model string `json:"model,omitempty"`
+
+	// Provider used.
+	Provider string `json:"provider"`
+}
+
+//////
+// Entrypoint.
+//////
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+
+	err := run(ctx, os.Args[1:], os.Stdin, os.Stdout, os.Stderr)
+
+	stop()
+
+	if err != nil && !errors.Is(err, flag.ErrHelp) {
+		fmt.Fprintln(os.Stderr, err)
+
+		os.Exit(1)
+	}
+}
+
+// run runs the command of the arguments.
+func run(ctx context.Context, args []string, stdin io.Reader, stdout, stderr io.Writer) error {
+	if len(args) == 0 {
+		fmt.Fprint(stderr, usage)
+
+		return customerror.NewRequiredError("command")
+	}
+
+	switch args[0] {
+	case "complete":
+		return complete(ctx, args[1:], stdin, stdout, stderr)
+	case "chat":
+		return chat(ctx, args[1:], stdin, stdout, stderr)
+	case "compare":
+		return compare(ctx, args[1:], stdin, stdout, stderr)
+	case "-h", "-help", "--help", "help":
+		fmt.Fprint(stdout, usage)
+
+		return nil
+	default:
+		fmt.Fprint(stderr, usage)
+
+		return customerror.NewInvalidError(fmt.Sprintf("command %s", args[0]))
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// newFlagSet returns a flag set of the command.
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+
+	fs.SetOutput(stderr)
+
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: inference %s [flags] %s\n\nFlags:\n", name, args)
+
+		fs.PrintDefaults()
+	}
+
+	return fs
+}
+
+// writeJSON writes `v` as a JSON line.
+func writeJSON(w io.Writer, v any) error {
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		return customerror.NewFailedToError("write JSON", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// modelOf returns the model, or the default one of the provider.
+func modelOf(model string, p provider.IProvider) string {
+	if model != "" {
+		return model
+	}
+
+	return provider.DefaultModel(p)
+}
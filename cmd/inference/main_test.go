@@ -0,0 +1,208 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/providertest"
+)
+
+// withMocks replaces the provider factory with one returning a mock per
+// provider, named after it, responding in order across runs.
+func withMocks(t *testing.T, responses map[string][]providertest.Response) {
+	t.Helper()
+
+	original := newProvider
+
+	t.Cleanup(func() { newProvider = original })
+
+	mocks := make(map[string]*providertest.Mock)
+
+	newProvider = func(name string, _ ...provider.ClientFunc) (provider.IProvider, error) {
+		if m, ok := mocks[name]; ok {
+			return m, nil
+		}
+
+		m, err := providertest.New(
+			providertest.WithName(name),
+			providertest.WithModel(name+"-model"),
+			providertest.WithResponses(responses[name]...),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		mocks[name] = m
+
+		return m, nil
+	}
+}
+
+func TestRun_complete(t *testing.T) {
+	withMocks(t, map[string][]providertest.Response{
+		"openai": {
+			{
+				Content: "pong",
+				Expect: providertest.ExpectAll(
+					providertest.ExpectSystemMessages("be brief"),
+					providertest.ExpectUserMessages("ping pong"),
+				),
+			},
+			{
+				Chunks: []string{"po", "ng"},
+				Expect: providertest.ExpectUserMessages("from stdin"),
+				Usage:  provider.Usage{InputTokens: 3, OutputTokens: 1},
+			},
+			{Err: errors.New("down")},
+		},
+	})
+
+	var stdout bytes.Buffer
+
+	err := run(context.Background(), []string{
+		"complete", "-provider", "openai", "-system", "be brief", "ping", "pong",
+	}, nil, &stdout, &stdout)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong\n", stdout.String())
+
+	stdout.Reset()
+
+	err = run(context.Background(), []string{
+		"complete", "-provider", "openai", "-json", "-stream",
+	}, strings.NewReader("from stdin\n"), &stdout, &stdout)
+	assert.NoError(t, err)
+
+	var out Output
+
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &out))
+	assert.Equal(t, "openai", out.Provider)
+	assert.Equal(t, "openai-model", out.Model)
+	assert.Equal(t, "pong", out.Response)
+	assert.Equal(t, provider.Usage{InputTokens: 3, OutputTokens: 1}, out.Usage)
+
+	stdout.Reset()
+
+	err = run(context.Background(), []string{"complete", "-provider", "openai", "-json", "ping"}, nil, &stdout, &stdout)
+	assert.EqualError(t, err, "down")
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &out))
+	assert.Equal(t, "down", out.Error)
+
+	err = run(context.Background(), []string{"complete", "-provider", "openai"}, strings.NewReader(" "), &stdout, &stdout)
+	assert.ErrorContains(t, err, "prompt required")
+
+	err = run(context.Background(), []string{"unknown"}, nil, &stdout, &stdout)
+	assert.ErrorContains(t, err, "invalid command unknown")
+}
+
+func TestRun_chat(t *testing.T) {
+	withMocks(t, map[string][]providertest.Response{
+		"anthropic": {
+			{Content: "hello", Expect: providertest.ExpectUserMessages("hi")},
+			{Err: errors.New("down")},
+			{
+				Content: "fine",
+				Expect: providertest.ExpectAll(
+					providertest.ExpectHistory(
+						message.Message{Content: "hi", Role: message.User},
+						message.Message{Content: "hello", Role: message.Assistant},
+					),
+					providertest.ExpectUserMessages("how are you?"),
+				),
+			},
+			{Content: "hi again", Expect: providertest.ExpectHistory()},
+		},
+	})
+
+	var stdout, stderr bytes.Buffer
+
+	err := run(
+		context.Background(),
+		[]string{"chat", "-provider", "anthropic"},
+		strings.NewReader("hi\n\nhow are you?\nhow are you?\n/reset\nhi\n/exit\nignored\n"),
+		&stdout,
+		&stderr,
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "hello\nfine\nhi again\n", stdout.String())
+	assert.Contains(t, stderr.String(), "down")
+}
+
+func TestRun_compare(t *testing.T) {
+	withMocks(t, map[string][]providertest.Response{
+		"anthropic": {{Content: "pong from anthropic", Expect: providertest.ExpectUserMessages("ping")}},
+		"openai":    {{Err: errors.New("down")}},
+	})
+
+	var stdout bytes.Buffer
+
+	err := run(context.Background(), []string{
+		"compare", "-providers", "openai=gpt-4o-mini,anthropic", "-json", "ping",
+	}, nil, &stdout, &stdout)
+	assert.NoError(t, err)
+
+	var outputs []Output
+
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &outputs))
+	assert.Len(t, outputs, 2)
+
+	assert.Equal(t, "anthropic", outputs[0].Provider)
+	assert.Equal(t, "anthropic-model", outputs[0].Model)
+	assert.Equal(t, "pong from anthropic", outputs[0].Response)
+
+	assert.Equal(t, "openai", outputs[1].Provider)
+	assert.Equal(t, "down", outputs[1].Error)
+
+	// Each response is unmarshaled on its own.
+	withMocks(t, map[string][]providertest.Response{
+		"anthropic": {{Content: `{"from": "anthropic"}`}},
+		"ollama":    {{Content: "not JSON"}},
+		"openai":    {{Content: `{"from": "openai"}`}},
+	})
+
+	stdout.Reset()
+
+	err = run(context.Background(), []string{
+		"compare", "-providers", "openai,anthropic,ollama", "-json", "-json-response", "ping",
+	}, nil, &stdout, &stdout)
+	assert.NoError(t, err)
+
+	assert.NoError(t, json.Unmarshal(stdout.Bytes(), &outputs))
+	assert.Len(t, outputs, 3)
+	assert.Equal(t, `{"from": "anthropic"}`, outputs[0].Response)
+	assert.NotEmpty(t, outputs[1].Error)
+	assert.Equal(t, `{"from": "openai"}`, outputs[2].Response)
+
+	err = run(context.Background(), []string{"compare", "-providers", "openai,openai", "ping"}, nil, &stdout, &stdout)
+	assert.ErrorContains(t, err, "openai is repeated")
+}
+
+func TestSideBySide(t *testing.T) {
+	var stdout bytes.Buffer
+
+	sideBySide(&stdout, 43, []Output{
+		{Provider: "a", Result: provider.Result{Response: "the quick brown fox jumps"}},
+		{Provider: "b", Result: provider.Result{Error: "down"}},
+	})
+
+	assert.Equal(t, strings.Join([]string{
+		"a                    | b",
+		"-------------------- | --------------------",
+		"the quick brown fox  | Error: down",
+		"jumps                | --------------------",
+		"-------------------- | 0s, 0 in, 0 out,",
+		"0s, 0 in, 0 out,     | $0.000000",
+		"$0.000000",
+		"",
+	}, "\n"), stdout.String())
+}
+
+func TestWrap(t *testing.T) {
+	assert.Equal(t, []string{"abcd", "ef g", "", "h"}, wrap("abcdef g\n\nh", 4))
+}
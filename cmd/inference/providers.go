@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/anthropic"
+	"github.com/thalesfsp/inference/huggingface"
+	"github.com/thalesfsp/inference/internal/config"
+	"github.com/thalesfsp/inference/ollama"
+	"github.com/thalesfsp/inference/openai"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Providers are the available providers, in order of preference.
+var Providers = []string{openai.Name, anthropic.Name, huggingface.Name, ollama.Name}
+
+// newProvider creates the provider, configured from the environment, then
+// the options. Replaced in tests.
+var newProvider = func(name string, options ...provider.ClientFunc) (provider.IProvider, error) {
+	switch name {
+	case anthropic.Name:
+		return asProvider(anthropic.NewDefault(options...))
+	case huggingface.Name:
+		return asProvider(huggingface.NewDefault(options...))
+	case ollama.Name:
+		return asProvider(ollama.NewDefault(options...))
+	case openai.Name:
+		return asProvider(openai.NewDefault(options...))
+	default:
+		return nil, customerror.NewInvalidError(fmt.Sprintf("provider %s", name))
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// asProvider returns `p` as a provider, nil if `err`.
+func asProvider[T provider.IProvider](p T, err error) (provider.IProvider, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// configured returns the providers with a configured API key, in order of
+// preference. Ollama doesn't need one, thus it's never configured.
+func configured() []string {
+	cfg := config.Get()
+
+	tokens := map[string]string{
+		anthropic.Name:   cfg.AnthropicToken,
+		huggingface.Name: cfg.HuggingFaceToken,
+		openai.Name:      cfg.OpenAIToken,
+	}
+
+	names := []string{}
+
+	for _, name := range Providers {
+		if tokens[name] != "" {
+			names = append(names, name)
+		}
+	}
+
+	return names
+}
+
+// defaultProvider returns `name`, or the first configured provider.
+func defaultProvider(name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+
+	names := configured()
+	if len(names) == 0 {
+		return "", customerror.NewMissingError("provider, set -provider, or an API key")
+	}
+
+	return names[0], nil
+}
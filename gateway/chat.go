@@ -0,0 +1,270 @@
+package gateway
+
+import (
+	"cmp"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Handlers.
+//////
+
+// chatCompletions serves the chat completions API.
+func (g *Gateway) chatCompletions(w http.ResponseWriter, r *http.Request) {
+	var req ChatCompletionRequest
+
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, MaxBodySize)).Decode(&req); err != nil {
+		writeError(w, customerror.NewInvalidError("request body", customerror.WithError(err)))
+
+		return
+	}
+
+	if req.Model == "" {
+		writeError(w, customerror.NewRequiredError("model"))
+
+		return
+	}
+
+	_, p, model, err := g.Route(req.Model)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	options, err := ToOptions(req)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	var usage provider.Usage
+
+	options = slices.Concat(g.options, options, []provider.Func{
+		provider.WithModel(model),
+		provider.WithUsage(&usage),
+	})
+
+	completion := ChatCompletion{
+		Created: time.Now().Unix(),
+		ID:      newID(),
+		Model:   req.Model,
+		Object:  ObjectChatCompletion,
+	}
+
+	if req.Stream {
+		g.stream(w, r, p, completion, req.StreamOptions, options, &usage)
+
+		return
+	}
+
+	response, err := p.Completion(r.Context(), options...)
+	if err != nil {
+		writeError(w, err)
+
+		return
+	}
+
+	completion.Choices = []Choice{{
+		FinishReason: cmp.Or(usage.FinishReason, FinishReasonStop),
+		Message:      ResponseMessage{Content: response, Role: message.Assistant},
+	}}
+	completion.Usage = toUsage(usage)
+
+	writeJSON(w, http.StatusOK, completion)
+}
+
+// stream streams the completion as server-sent events: a chunk with the
+// role, a chunk per provider chunk, a last chunk with the finish reason, and
+// the usage, if asked, then `[DONE]`. Errors before the first chunk are
+// regular error responses, after, an error event ending the stream.
+func (g *Gateway) stream(
+	w http.ResponseWriter,
+	r *http.Request,
+	p provider.IProvider,
+	completion ChatCompletion,
+	streamOptions *StreamOptions,
+	options []provider.Func,
+	usage *provider.Usage,
+) {
+	rc := http.NewResponseController(w)
+
+	started := false
+
+	send := func(v any) error {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return customerror.NewFailedToError("marshal chunk", customerror.WithError(err))
+		}
+
+		if _, err := fmt.Fprintf(w, "data: %s\n\n", data); err != nil {
+			return customerror.NewFailedToError("write chunk", customerror.WithError(err))
+		}
+
+		return rc.Flush()
+	}
+
+	chunk := func(delta Delta, finishReason *string) ChatCompletionChunk {
+		return ChatCompletionChunk{
+			Choices: []ChunkChoice{{Delta: delta, FinishReason: finishReason}},
+			Created: completion.Created,
+			ID:      completion.ID,
+			Model:   completion.Model,
+			Object:  ObjectChatCompletionChunk,
+		}
+	}
+
+	start := func() error {
+		if started {
+			return nil
+		}
+
+		started = true
+
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+
+		return send(chunk(Delta{Role: message.Assistant}, nil))
+	}
+
+	options = append(options, provider.WithStreamHandler(func(content string) error {
+		if err := start(); err != nil {
+			return err
+		}
+
+		return send(chunk(Delta{Content: content}, nil))
+	}))
+
+	if _, err := p.Completion(r.Context(), options...); err != nil {
+		if !started {
+			writeError(w, err)
+
+			return
+		}
+
+		_ = send(ErrorResponse{Error: toError(err, StatusCode(err))})
+
+		return
+	}
+
+	if err := start(); err != nil {
+		return
+	}
+
+	finishReason := cmp.Or(usage.FinishReason, FinishReasonStop)
+
+	last := chunk(Delta{}, &finishReason)
+
+	if streamOptions != nil && streamOptions.IncludeUsage {
+		u := toUsage(*usage)
+
+		last.Usage = &u
+	}
+
+	if err := send(last); err != nil {
+		return
+	}
+
+	_, _ = fmt.Fprint(w, "data: [DONE]\n\n")
+	_ = rc.Flush()
+}
+
+//////
+// Exported functionalities.
+//////
+
+// ToOptions converts the request to completion options, except the model.
+// System, and developer messages are the system messages, in order, the
+// trailing user messages the user messages, and the others the history.
+func ToOptions(req ChatCompletionRequest) ([]provider.Func, error) {
+	systemMessages := []string{}
+	conversation := []message.Message{}
+
+	for _, m := range req.Messages {
+		switch m.Role {
+		case message.System, RoleDeveloper:
+			systemMessages = append(systemMessages, string(m.Content))
+		case message.User, message.Assistant:
+			conversation = append(conversation, message.Message{Content: string(m.Content), Role: m.Role})
+		default:
+			return nil, customerror.NewInvalidError(fmt.Sprintf("message role %s", m.Role))
+		}
+	}
+
+	i := len(conversation)
+
+	for i > 0 && conversation[i-1].Role == message.User {
+		i--
+	}
+
+	if i == len(conversation) {
+		return nil, customerror.NewRequiredError("user message, last")
+	}
+
+	userMessages := make([]string, 0, len(conversation)-i)
+
+	for _, m := range conversation[i:] {
+		userMessages = append(userMessages, m.Content)
+	}
+
+	options := []provider.Func{
+		provider.WithUserMessages(userMessages...),
+		provider.WithTemperature(req.Temperature),
+		provider.WithTopP(req.TopP),
+		provider.WithSeed(req.Seed),
+		provider.WithStop(req.Stop...),
+	}
+
+	// Set only if any, not to replace the ones of the gateway's options.
+	if len(systemMessages) > 0 {
+		options = append(options, provider.WithSystemMessages(systemMessages...))
+	}
+
+	if i > 0 {
+		options = append(options, provider.WithHistory(conversation[:i]...))
+	}
+
+	// Prefers the newer parameter, deprecating max_tokens.
+	if req.MaxCompletionTokens > 0 {
+		options = append(options, provider.WithMaxToken(req.MaxCompletionTokens))
+	} else {
+		options = append(options, provider.WithMaxToken(req.MaxTokens))
+	}
+
+	return options, nil
+}
+
+//////
+// Helpers.
+//////
+
+// newID returns a random completion ID.
+func newID() string {
+	b := make([]byte, 12)
+
+	_, _ = rand.Read(b)
+
+	return "chatcmpl-" + hex.EncodeToString(b)
+}
+
+// toUsage converts the usage.
+func toUsage(u provider.Usage) Usage {
+	return Usage{
+		CompletionTokens: u.OutputTokens,
+		PromptTokens:     u.InputTokens,
+		TotalTokens:      u.InputTokens + u.OutputTokens,
+	}
+}
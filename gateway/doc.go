@@ -0,0 +1,15 @@
+// Package gateway exposes providers through an OpenAI-compatible HTTP API, so
+// services in any language can use their routing, retries, budgets, and
+// metrics with the de-facto standard clients, and SDKs.
+//
+// Gateway is an http.Handler serving:
+//
+//   - `POST /v1/chat/completions`, streamed as server-sent events if asked.
+//   - `GET /v1/models`, the models it routes.
+//
+// Requests are routed to a provider by model name, see Gateway.Route:
+//
+//	g, err := gateway.New(provider.Map{"openai": oai, "anthropic": ant})
+//
+//	http.ListenAndServe(":8080", g)
+package gateway
@@ -0,0 +1,271 @@
+package gateway
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/catalog"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Paths served by the gateway.
+const (
+	PathChatCompletions = "/v1/chat/completions"
+	PathModels          = "/v1/models"
+)
+
+// MaxBodySize is the maximum size, in bytes, of request bodies.
+const MaxBodySize = 8 << 20
+
+// Func allows to set options.
+type Func func(g *Gateway) error
+
+// Gateway is an http.Handler exposing the providers through an
+// OpenAI-compatible API.
+type Gateway struct {
+	defaultProvider string
+	mux             *http.ServeMux
+	options         []provider.Func
+	providers       provider.Map
+	routes          map[string]string
+}
+
+//////
+// Exported options.
+//////
+
+// WithDefaultProvider routes the models no other rule matches to the provider
+// of the given key. Default to none: unknown models are rejected.
+func WithDefaultProvider(key string) Func {
+	return func(g *Gateway) error {
+		g.defaultProvider = key
+
+		return nil
+	}
+}
+
+// WithOptions sets the options of every completion, e.g.: MaxTokens. Requests
+// override them.
+func WithOptions(options ...provider.Func) Func {
+	return func(g *Gateway) error {
+		g.options = options
+
+		return nil
+	}
+}
+
+// WithRoute routes the model to the provider of the given key. A trailing `*`
+// matches by prefix, e.g.: `gpt-*`, the longest prefix wins.
+func WithRoute(model, key string) Func {
+	return func(g *Gateway) error {
+		if model == "" || model == "*" {
+			return customerror.NewInvalidError(fmt.Sprintf("route %q", model))
+		}
+
+		g.routes[model] = key
+
+		return nil
+	}
+}
+
+//////
+// Methods.
+//////
+
+// ServeHTTP implements the http.Handler interface.
+func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	g.mux.ServeHTTP(w, r)
+}
+
+// Route returns the key, and the provider serving the model, and the model
+// name sent to it. Rules, in order:
+//
+//  1. Routes set through WithRoute.
+//  2. `key/model`, e.g.: `anthropic/claude-3-5-haiku-latest`.
+//  3. The first provider, sorted by key, whose catalog knows the model.
+//  4. The first provider, sorted by key, whose default model it is.
+//  5. The default provider, set through WithDefaultProvider.
+func (g *Gateway) Route(model string) (string, provider.IProvider, string, error) {
+	if key, ok := g.route(model); ok {
+		return g.lookup(key, model)
+	}
+
+	if key, name, ok := strings.Cut(model, "/"); ok {
+		if _, exists := g.providers[key]; exists {
+			return g.lookup(key, name)
+		}
+	}
+
+	keys := g.keys()
+
+	for _, key := range keys {
+		if _, ok := catalog.Get().Lookup(g.providers[key].GetName(), model); ok {
+			return g.lookup(key, model)
+		}
+	}
+
+	for _, key := range keys {
+		if provider.DefaultModel(g.providers[key]) == model {
+			return g.lookup(key, model)
+		}
+	}
+
+	if g.defaultProvider != "" {
+		return g.lookup(g.defaultProvider, model)
+	}
+
+	return "", nil, "", customerror.NewNotFoundError(fmt.Sprintf("model %s", model))
+}
+
+// route returns the key of the route set for the model, if any.
+func (g *Gateway) route(model string) (string, bool) {
+	if key, ok := g.routes[model]; ok {
+		return key, true
+	}
+
+	var (
+		found  string
+		prefix string
+	)
+
+	for pattern, key := range g.routes {
+		p, ok := strings.CutSuffix(pattern, "*")
+		if !ok || !strings.HasPrefix(model, p) {
+			continue
+		}
+
+		if found == "" || len(p) > len(prefix) {
+			found = key
+			prefix = p
+		}
+	}
+
+	return found, found != ""
+}
+
+// lookup returns the provider of the key.
+func (g *Gateway) lookup(key, model string) (string, provider.IProvider, string, error) {
+	p, ok := g.providers[key]
+	if !ok {
+		return "", nil, "", customerror.NewNotFoundError(fmt.Sprintf("provider %s", key))
+	}
+
+	return key, p, model, nil
+}
+
+// keys returns the keys of the providers, sorted.
+func (g *Gateway) keys() []string {
+	keys := make([]string, 0, len(g.providers))
+
+	for key := range g.providers {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+//////
+// Helpers.
+//////
+
+// writeJSON writes `v` as the JSON response body.
+func writeJSON(w http.ResponseWriter, statusCode int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes the error as an OpenAI-shaped response body.
+func writeError(w http.ResponseWriter, err error) {
+	statusCode := StatusCode(err)
+
+	writeJSON(w, statusCode, ErrorResponse{Error: toError(err, statusCode)})
+}
+
+// toError converts the error.
+func toError(err error, statusCode int) Error {
+	e := Error{Message: err.Error(), Type: ErrorTypeAPI}
+
+	switch {
+	case statusCode == http.StatusNotFound:
+		code := "model_not_found"
+
+		e.Code = &code
+		e.Type = ErrorTypeInvalidRequest
+	case statusCode == http.StatusTooManyRequests:
+		e.Type = ErrorTypeRateLimit
+	case statusCode < http.StatusInternalServerError:
+		e.Type = ErrorTypeInvalidRequest
+	}
+
+	return e
+}
+
+// StatusCode returns the HTTP status code of a completion error: client
+// errors keep theirs, rate limits, and exceeded budgets are `429`, timeouts
+// `504`, and upstream failures, including authentication, `502`.
+func StatusCode(err error) int {
+	switch provider.ErrorClass(err) {
+	case provider.ErrorClassClient:
+		cE, _ := customerror.To(err)
+
+		return cE.StatusCode
+	case provider.ErrorClassBudgetExceeded, provider.ErrorClassRateLimited:
+		return http.StatusTooManyRequests
+	case provider.ErrorClassTimeout:
+		return http.StatusGatewayTimeout
+	case provider.ErrorClassCanceled:
+		return http.StatusRequestTimeout
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+//////
+// Factory.
+//////
+
+// New returns a gateway to the providers, keyed by name, e.g.: `openai`.
+func New(providers provider.Map, options ...Func) (*Gateway, error) {
+	if len(providers) == 0 {
+		return nil, customerror.NewRequiredError("providers")
+	}
+
+	g := &Gateway{
+		mux:       http.NewServeMux(),
+		providers: providers,
+		routes:    make(map[string]string),
+	}
+
+	for _, option := range options {
+		if err := option(g); err != nil {
+			return nil, err
+		}
+	}
+
+	for model, key := range g.routes {
+		if _, ok := providers[key]; !ok {
+			return nil, customerror.NewNotFoundError(fmt.Sprintf("provider %s of route %s", key, model))
+		}
+	}
+
+	if _, ok := providers[g.defaultProvider]; g.defaultProvider != "" && !ok {
+		return nil, customerror.NewNotFoundError(fmt.Sprintf("default provider %s", g.defaultProvider))
+	}
+
+	g.mux.HandleFunc("POST "+PathChatCompletions, g.chatCompletions)
+	g.mux.HandleFunc("GET "+PathModels, g.models)
+
+	return g, nil
+}
@@ -0,0 +1,397 @@
+package gateway_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/anthropic"
+	"github.com/thalesfsp/inference/gateway"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/providertest"
+)
+
+func newMock(t *testing.T, name, model string) *providertest.Mock {
+	t.Helper()
+
+	m, err := providertest.New(providertest.WithName(name), providertest.WithModel(model))
+	assert.NoError(t, err)
+
+	return m
+}
+
+// post sends the request body to the gateway.
+func post(g http.Handler, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodPost, gateway.PathChatCompletions, strings.NewReader(body)))
+
+	return w
+}
+
+// events returns the data of the server-sent events.
+func events(t *testing.T, body string) []string {
+	t.Helper()
+
+	data := []string{}
+
+	scanner := bufio.NewScanner(strings.NewReader(body))
+
+	for scanner.Scan() {
+		if d, ok := strings.CutPrefix(scanner.Text(), "data: "); ok {
+			data = append(data, d)
+		}
+	}
+
+	return data
+}
+
+func TestGateway_Route(t *testing.T) {
+	a := newMock(t, "a", "a-model")
+	b := newMock(t, "b", "b-model")
+
+	g, err := gateway.New(
+		provider.Map{"a": a, "b": b},
+		gateway.WithRoute("gpt-*", "a"),
+		gateway.WithRoute("gpt-4o*", "b"),
+		gateway.WithRoute("exact", "b"),
+	)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		model     string
+		wantKey   string
+		wantModel string
+	}{
+		{model: "exact", wantKey: "b", wantModel: "exact"},
+		{model: "gpt-3.5-turbo", wantKey: "a", wantModel: "gpt-3.5-turbo"},
+		{model: "gpt-4o-mini", wantKey: "b", wantModel: "gpt-4o-mini"},
+		{model: "b/meta-llama/Llama-3.1-8B", wantKey: "b", wantModel: "meta-llama/Llama-3.1-8B"},
+		{model: "a-model", wantKey: "a", wantModel: "a-model"},
+		{model: "b-model", wantKey: "b", wantModel: "b-model"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			key, p, model, err := g.Route(tt.model)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantKey, key)
+			assert.Equal(t, tt.wantKey, p.GetName())
+			assert.Equal(t, tt.wantModel, model)
+		})
+	}
+
+	_, _, _, err = g.Route("unknown")
+	assert.ErrorContains(t, err, "model unknown not found")
+
+	g, err = gateway.New(provider.Map{"a": a, "b": b}, gateway.WithDefaultProvider("b"))
+	assert.NoError(t, err)
+
+	key, _, _, err := g.Route("unknown")
+	assert.NoError(t, err)
+	assert.Equal(t, "b", key)
+
+	_, err = gateway.New(provider.Map{"a": a}, gateway.WithRoute("x", "missing"))
+	assert.Error(t, err)
+
+	_, err = gateway.New(provider.Map{"a": a}, gateway.WithDefaultProvider("missing"))
+	assert.Error(t, err)
+
+	_, err = gateway.New(nil)
+	assert.Error(t, err)
+}
+
+func TestGateway_chatCompletions(t *testing.T) {
+	m := newMock(t, "a", "a-model")
+
+	g, err := gateway.New(provider.Map{"a": m})
+	assert.NoError(t, err)
+
+	t.Run("completion", func(t *testing.T) {
+		m.Enqueue(providertest.Response{
+			Content: "pong",
+			Expect: providertest.ExpectAll(
+				providertest.ExpectModel("a-model"),
+				providertest.ExpectSystemMessages("be brief", "be nice"),
+				providertest.ExpectHistory(
+					message.Message{Content: "hi", Role: message.User},
+					message.Message{Content: "hello", Role: message.Assistant},
+				),
+				providertest.ExpectUserMessages("ping", "pong?"),
+			),
+			Usage: provider.Usage{InputTokens: 3, OutputTokens: 1},
+		})
+
+		w := post(g, `{
+			"model": "a-model",
+			"max_tokens": 16,
+			"stop": "\n",
+			"messages": [
+				{"role": "system", "content": "be brief"},
+				{"role": "developer", "content": [{"type": "text", "text": "be nice"}]},
+				{"role": "user", "content": "hi"},
+				{"role": "assistant", "content": "hello"},
+				{"role": "user", "content": "ping"},
+				{"role": "user", "content": "pong?"}
+			]
+		}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+
+		var completion gateway.ChatCompletion
+
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &completion))
+		assert.Equal(t, gateway.ObjectChatCompletion, completion.Object)
+		assert.True(t, strings.HasPrefix(completion.ID, "chatcmpl-"))
+		assert.Equal(t, "a-model", completion.Model)
+		assert.Equal(t, []gateway.Choice{{
+			FinishReason: gateway.FinishReasonStop,
+			Message:      gateway.ResponseMessage{Content: "pong", Role: message.Assistant},
+		}}, completion.Choices)
+		assert.Equal(t, gateway.Usage{CompletionTokens: 1, PromptTokens: 3, TotalTokens: 4}, completion.Usage)
+
+		call, ok := m.LastCall()
+		assert.True(t, ok)
+		assert.Equal(t, 16, call.Options.MaxTokens)
+		assert.Equal(t, []string{"\n"}, call.Options.Stop)
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		m.Enqueue(providertest.Response{
+			Chunks: []string{"po", "ng"},
+			Usage:  provider.Usage{InputTokens: 3, OutputTokens: 1},
+		})
+
+		w := post(g, `{
+			"model": "a-model",
+			"stream": true,
+			"stream_options": {"include_usage": true},
+			"messages": [{"role": "user", "content": "ping"}]
+		}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+
+		data := events(t, w.Body.String())
+		assert.Len(t, data, 5)
+		assert.Equal(t, "[DONE]", data[len(data)-1])
+
+		chunks := make([]gateway.ChatCompletionChunk, len(data)-1)
+
+		for i := range chunks {
+			assert.NoError(t, json.Unmarshal([]byte(data[i]), &chunks[i]))
+			assert.Equal(t, gateway.ObjectChatCompletionChunk, chunks[i].Object)
+			assert.Equal(t, chunks[0].ID, chunks[i].ID)
+		}
+
+		assert.Equal(t, message.Assistant, chunks[0].Choices[0].Delta.Role)
+		assert.Equal(t, "po", chunks[1].Choices[0].Delta.Content)
+		assert.Equal(t, "ng", chunks[2].Choices[0].Delta.Content)
+		assert.Nil(t, chunks[2].Choices[0].FinishReason)
+		assert.Equal(t, gateway.FinishReasonStop, *chunks[3].Choices[0].FinishReason)
+		assert.Equal(t, &gateway.Usage{CompletionTokens: 1, PromptTokens: 3, TotalTokens: 4}, chunks[3].Usage)
+	})
+
+	t.Run("finish reason", func(t *testing.T) {
+		m.Enqueue(providertest.Response{
+			Content: "po",
+			Usage:   provider.Usage{FinishReason: provider.FinishReasonLength, InputTokens: 3, OutputTokens: 1},
+		})
+
+		w := post(g, `{"model": "a-model", "max_tokens": 1, "messages": [{"role": "user", "content": "ping"}]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var completion gateway.ChatCompletion
+
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &completion))
+		assert.Equal(t, provider.FinishReasonLength, completion.Choices[0].FinishReason)
+
+		m.Enqueue(providertest.Response{
+			Chunks: []string{"po"},
+			Usage:  provider.Usage{FinishReason: provider.FinishReasonLength, InputTokens: 3, OutputTokens: 1},
+		})
+
+		w = post(g, `{"model": "a-model", "stream": true, "messages": [{"role": "user", "content": "ping"}]}`)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		data := events(t, w.Body.String())
+		assert.Len(t, data, 4)
+
+		var last gateway.ChatCompletionChunk
+
+		assert.NoError(t, json.Unmarshal([]byte(data[len(data)-2]), &last))
+		assert.Equal(t, provider.FinishReasonLength, *last.Choices[0].FinishReason)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		tests := []struct {
+			name       string
+			body       string
+			response   *providertest.Response
+			wantStatus int
+			wantType   string
+			wantCode   string
+		}{
+			{
+				name:       "body",
+				body:       `{`,
+				wantStatus: http.StatusBadRequest,
+				wantType:   gateway.ErrorTypeInvalidRequest,
+			},
+			{
+				name:       "model required",
+				body:       `{"messages": [{"role": "user", "content": "ping"}]}`,
+				wantStatus: http.StatusBadRequest,
+				wantType:   gateway.ErrorTypeInvalidRequest,
+			},
+			{
+				name:       "model not found",
+				body:       `{"model": "unknown", "messages": [{"role": "user", "content": "ping"}]}`,
+				wantStatus: http.StatusNotFound,
+				wantType:   gateway.ErrorTypeInvalidRequest,
+				wantCode:   "model_not_found",
+			},
+			{
+				name:       "role",
+				body:       `{"model": "a-model", "messages": [{"role": "tool", "content": "42"}]}`,
+				wantStatus: http.StatusBadRequest,
+				wantType:   gateway.ErrorTypeInvalidRequest,
+			},
+			{
+				name:       "last message",
+				body:       `{"model": "a-model", "messages": [{"role": "assistant", "content": "hi"}]}`,
+				wantStatus: http.StatusBadRequest,
+				wantType:   gateway.ErrorTypeInvalidRequest,
+			},
+			{
+				name: "rate limited",
+				body: `{"model": "a-model", "stream": true, "messages": [{"role": "user", "content": "ping"}]}`,
+				response: &providertest.Response{
+					Err: customerror.New("slow down", customerror.WithStatusCode(http.StatusTooManyRequests)),
+				},
+				wantStatus: http.StatusTooManyRequests,
+				wantType:   gateway.ErrorTypeRateLimit,
+			},
+			{
+				name: "upstream",
+				body: `{"model": "a-model", "messages": [{"role": "user", "content": "ping"}]}`,
+				response: &providertest.Response{
+					Err: customerror.New("down", customerror.WithStatusCode(http.StatusServiceUnavailable)),
+				},
+				wantStatus: http.StatusBadGateway,
+				wantType:   gateway.ErrorTypeAPI,
+			},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				if tt.response != nil {
+					m.Enqueue(*tt.response)
+				}
+
+				w := post(g, tt.body)
+				assert.Equal(t, tt.wantStatus, w.Code)
+
+				var errResp gateway.ErrorResponse
+
+				assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+				assert.Equal(t, tt.wantType, errResp.Error.Type)
+				assert.NotEmpty(t, errResp.Error.Message)
+
+				if tt.wantCode != "" {
+					assert.Equal(t, tt.wantCode, *errResp.Error.Code)
+				}
+			})
+		}
+
+		assert.Equal(t, 0, m.Pending())
+	})
+}
+
+func TestGateway_stream(t *testing.T) {
+	s := providertest.NewServer(
+		providertest.Anthropic,
+		providertest.Reply{Chunks: []string{"po", "ng"}, Usage: provider.Usage{InputTokens: 3, OutputTokens: 1}},
+	)
+	defer s.Close()
+
+	p, err := anthropic.New(
+		provider.WithEndpoint(s.Endpoint()),
+		provider.WithToken("test"),
+		provider.WithDefaulModel("claude-3-5-haiku-latest"),
+	)
+	assert.NoError(t, err)
+
+	g, err := gateway.New(provider.Map{"anthropic": p}, gateway.WithOptions(provider.WithMaxToken(64)))
+	assert.NoError(t, err)
+
+	srv := httptest.NewServer(g)
+	defer srv.Close()
+
+	resp, err := http.Post(
+		srv.URL+gateway.PathChatCompletions,
+		"application/json",
+		strings.NewReader(`{"model": "claude-3-5-haiku-latest", "stream": true, "messages": [{"role": "user", "content": "ping"}]}`),
+	)
+	assert.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	content := ""
+
+	scanner := bufio.NewScanner(resp.Body)
+
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+
+		var chunk gateway.ChatCompletionChunk
+
+		assert.NoError(t, json.Unmarshal([]byte(data), &chunk))
+
+		content += chunk.Choices[0].Delta.Content
+	}
+
+	assert.Equal(t, "pong", content)
+
+	var reqBody anthropic.RequestBody
+
+	assert.NoError(t, s.Requests()[0].Decode(&reqBody))
+	assert.True(t, reqBody.Stream)
+	assert.Equal(t, 64, reqBody.MaxTokens)
+}
+
+func TestGateway_models(t *testing.T) {
+	g, err := gateway.New(
+		provider.Map{"a": newMock(t, "a", "a-model"), "b": newMock(t, "b", "b-model")},
+		gateway.WithRoute("alias", "a"),
+		gateway.WithRoute("gpt-*", "b"),
+	)
+	assert.NoError(t, err)
+
+	w := httptest.NewRecorder()
+
+	g.ServeHTTP(w, httptest.NewRequest(http.MethodGet, gateway.PathModels, nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var list gateway.ModelList
+
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	assert.Equal(t, gateway.ModelList{
+		Data: []gateway.Model{
+			{ID: "a-model", Object: gateway.ObjectModel, OwnedBy: "a"},
+			{ID: "alias", Object: gateway.ObjectModel, OwnedBy: "a"},
+			{ID: "b-model", Object: gateway.ObjectModel, OwnedBy: "b"},
+		},
+		Object: gateway.ObjectList,
+	}, list)
+}
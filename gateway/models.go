@@ -0,0 +1,63 @@
+package gateway
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/thalesfsp/inference/catalog"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Handlers.
+//////
+
+// models serves the models API: the models of the catalog of each provider,
+// their default models, and the exact routes.
+func (g *Gateway) models(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, http.StatusOK, ModelList{Data: g.Models(), Object: ObjectList})
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Models returns the routed models, sorted by ID, owned by the key of their
+// provider.
+func (g *Gateway) Models() []Model {
+	owners := make(map[string]string)
+
+	add := func(id, key string) {
+		if _, ok := owners[id]; !ok && id != "" {
+			owners[id] = key
+		}
+	}
+
+	for model, key := range g.routes {
+		if !strings.HasSuffix(model, "*") {
+			add(model, key)
+		}
+	}
+
+	// Same precedence as Route.
+	for _, key := range g.keys() {
+		for _, m := range catalog.Get().Models(g.providers[key].GetName()) {
+			add(m.Name, key)
+		}
+	}
+
+	for _, key := range g.keys() {
+		add(provider.DefaultModel(g.providers[key]), key)
+	}
+
+	models := make([]Model, 0, len(owners))
+
+	for id, key := range owners {
+		models = append(models, Model{ID: id, Object: ObjectModel, OwnedBy: key})
+	}
+
+	sort.Slice(models, func(i, j int) bool { return models[i].ID < models[j].ID })
+
+	return models
+}
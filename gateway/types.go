@@ -0,0 +1,212 @@
+package gateway
+
+import (
+	"encoding/json"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, types.
+//////
+
+// Object types.
+const (
+	ObjectChatCompletion      = "chat.completion"
+	ObjectChatCompletionChunk = "chat.completion.chunk"
+	ObjectList                = "list"
+	ObjectModel               = "model"
+)
+
+// Error types.
+const (
+	ErrorTypeAPI            = "api_error"
+	ErrorTypeInvalidRequest = "invalid_request_error"
+	ErrorTypeRateLimit      = "rate_limit_error"
+)
+
+// Roles of messages, in addition to the ones of the message package.
+const (
+	// RoleDeveloper is treated as the system role.
+	RoleDeveloper = "developer"
+)
+
+// FinishReasonStop is the finish reason of completions whose provider didn't
+// report any.
+const FinishReasonStop = "stop"
+
+//////
+// Request body.
+
+// ContentPart is a part of the content of a message.
+type ContentPart struct {
+	Text string `json:"text"`
+	Type string `json:"type"`
+}
+
+// Content of a message, either a string, or text parts, concatenated.
+type Content string
+
+// Stop sequences, either a string, or a list of strings.
+type Stop []string
+
+// ChatMessage is a message of the conversation.
+type ChatMessage struct {
+	Content Content `json:"content"`
+	Role    string  `json:"role"`
+}
+
+// StreamOptions of the request.
+type StreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+// ChatCompletionRequest represents the request body of the chat completions
+// API.
+type ChatCompletionRequest struct {
+	MaxCompletionTokens int            `json:"max_completion_tokens,omitempty"`
+	MaxTokens           int            `json:"max_tokens,omitempty"`
+	Messages            []ChatMessage  `json:"messages"`
+	Model               string         `json:"model"`
+	Seed                int            `json:"seed,omitempty"`
+	Stop                Stop           `json:"stop,omitempty"`
+	Stream              bool           `json:"stream,omitempty"`
+	StreamOptions       *StreamOptions `json:"stream_options,omitempty"`
+	Temperature         float64        `json:"temperature,omitempty"`
+	TopP                float64        `json:"top_p,omitempty"`
+}
+
+//////
+// Response body.
+
+// Usage of a completion.
+type Usage struct {
+	CompletionTokens int `json:"completion_tokens"`
+	PromptTokens     int `json:"prompt_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ResponseMessage is the message of a choice.
+type ResponseMessage struct {
+	Content string `json:"content"`
+	Role    string `json:"role"`
+}
+
+// Choice of a completion.
+type Choice struct {
+	FinishReason string          `json:"finish_reason"`
+	Index        int             `json:"index"`
+	Message      ResponseMessage `json:"message"`
+}
+
+// ChatCompletion represents the response body of the chat completions API.
+type ChatCompletion struct {
+	Choices []Choice `json:"choices"`
+	Created int64    `json:"created"`
+	ID      string   `json:"id"`
+	Model   string   `json:"model"`
+	Object  string   `json:"object"`
+	Usage   Usage    `json:"usage"`
+}
+
+// Delta of a chunk.
+type Delta struct {
+	Content string `json:"content,omitempty"`
+	Role    string `json:"role,omitempty"`
+}
+
+// ChunkChoice is a choice of a chunk.
+type ChunkChoice struct {
+	Delta        Delta   `json:"delta"`
+	FinishReason *string `json:"finish_reason"`
+	Index        int     `json:"index"`
+}
+
+// ChatCompletionChunk is a chunk of a streamed completion.
+type ChatCompletionChunk struct {
+	Choices []ChunkChoice `json:"choices"`
+	Created int64         `json:"created"`
+	ID      string        `json:"id"`
+	Model   string        `json:"model"`
+	Object  string        `json:"object"`
+	Usage   *Usage        `json:"usage,omitempty"`
+}
+
+// Model is a routed model.
+type Model struct {
+	Created int64  `json:"created"`
+	ID      string `json:"id"`
+	Object  string `json:"object"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// ModelList represents the response body of the models API.
+type ModelList struct {
+	Data   []Model `json:"data"`
+	Object string  `json:"object"`
+}
+
+// Error of a request.
+type Error struct {
+	Code    *string `json:"code"`
+	Message string  `json:"message"`
+	Param   *string `json:"param"`
+	Type    string  `json:"type"`
+}
+
+// ErrorResponse represents the response body of failed requests.
+type ErrorResponse struct {
+	Error Error `json:"error"`
+}
+
+//////
+// Methods.
+//////
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (c *Content) UnmarshalJSON(data []byte) error {
+	var text string
+
+	if err := json.Unmarshal(data, &text); err == nil {
+		*c = Content(text)
+
+		return nil
+	}
+
+	var parts []ContentPart
+
+	if err := json.Unmarshal(data, &parts); err != nil {
+		return customerror.NewInvalidError("message content, expected a string, or content parts")
+	}
+
+	for _, part := range parts {
+		if part.Type != "text" {
+			return customerror.NewInvalidError("message content part " + part.Type + ", only text is supported")
+		}
+
+		*c += Content(part.Text)
+	}
+
+	return nil
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (s *Stop) UnmarshalJSON(data []byte) error {
+	var stop string
+
+	if err := json.Unmarshal(data, &stop); err == nil {
+		*s = Stop{stop}
+
+		return nil
+	}
+
+	var stops []string
+
+	if err := json.Unmarshal(data, &stops); err != nil {
+		return customerror.NewInvalidError("stop, expected a string, or a list of strings")
+	}
+
+	*s = stops
+
+	return nil
+}
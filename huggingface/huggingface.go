@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/internal/builtin"
 	"github.com/thalesfsp/inference/internal/config"
 	"github.com/thalesfsp/inference/message"
 	"github.com/thalesfsp/inference/provider"
@@ -27,6 +28,16 @@ const Name = "huggingface"
 // Singleton.
 var singleton provider.IProvider
 
+// capabilities of the provider, and its models.
+//
+// NOTE: Context length depends on the model being served.
+var capabilities = provider.Capabilities{
+	JSONMode:  true,
+	Seed:      true,
+	Streaming: true,
+	Tools:     true,
+}
+
 // HuggingFace provider definition.
 type HuggingFace struct {
 	*provider.Provider
@@ -51,46 +62,41 @@ type HuggingFace struct {
 // NOTE: Not all options are available for all providers.
 func (p *HuggingFace) Completion(ctx context.Context, options ...provider.Func) (string, error) {
 	//////
-	// Options initialization.
+	// Tracing.
 	//////
 
-	// Prepend the default model to the options.
-	options = append(
-		[]provider.Func{
-			provider.WithModel(p.DefaultModel),
-		},
-		options...,
-	)
-
-	processedOptions, err := provider.NewOptionsFrom(options...)
-	if err != nil {
-		return "", err
-	}
+	// Started first, so invalid requests are traced, and measured too.
+	ctx, span := p.StartSpan(ctx)
+	defer span.End()
 
 	//////
-	// Messages processing.
+	// Request.
 	//////
 
-	finalMessages := message.NewMessages(
-		processedOptions.SystemMessages,
-		processedOptions.UserMessages,
-	)
+	reqBody, processedOptions, err := p.BuildRequestBody(ctx, options...)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	span.SetRequest(processedOptions)
 
 	//////
-	// Request body formation.
+	// Budget, and usage accounting.
 	//////
 
-	reqBody := &RequestBody{
-		Messages: finalMessages,
-		Model:    processedOptions.Model,
-		Stream:   processedOptions.Stream,
+	settle, err := p.Reserve(ctx, processedOptions)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
 
-		MaxTokens:   processedOptions.MaxTokens,
-		Seed:        processedOptions.Seed,
-		Temperature: processedOptions.Temperature,
-		TopP:        processedOptions.TopP,
+		return "", span.Fail(err)
 	}
 
+	var usage provider.Usage
+
+	defer func() { settle(usage) }()
+
 	//////
 	// Call LLM provider.
 	//////
@@ -100,27 +106,30 @@ func (p *HuggingFace) Completion(ctx context.Context, options ...provider.Func)
 	// Track performance.
 	now := time.Now()
 
-	resp, err := p.client.Post(
-		ctx,
-		p.Endpoint,
-		httpclient.WithBearerAuthToken(p.Token),
-		httpclient.WithReqBody(reqBody),
-		httpclient.WithRespBody(&respBody),
-	)
+	if processedOptions.StreamHandler != nil {
+		respBody, err = p.stream(ctx, reqBody, processedOptions.StreamHandler)
+	} else {
+		err = p.post(ctx, reqBody, &respBody)
+	}
+
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
 	}
 
-	defer resp.Body.Close()
+	usage = respBody.Usage.ToUsage()
+	usage.FinishReason = respBody.FinishReason()
+
+	span.SetResponse(respBody.ID, respBody.Model, respBody.FinishReasons()...)
+	span.SetUsage(usage)
 
 	// Response processing.
 	response, err := ProcessResponse(respBody)
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
 	}
 
 	// Optional response body processing.
@@ -129,7 +138,7 @@ func (p *HuggingFace) Completion(ctx context.Context, options ...provider.Func)
 			[]byte(response),
 			processedOptions.ResponseBody,
 		); err != nil {
-			return "", err
+			return "", span.Fail(err)
 		}
 	}
 
@@ -142,6 +151,8 @@ func (p *HuggingFace) Completion(ctx context.Context, options ...provider.Func)
 		level.Debug,
 		fmt.Sprintf("Completion %s", status.Created.String()),
 		sypl.WithField("duration", time.Since(now)),
+		sypl.WithField("inputTokens", usage.InputTokens),
+		sypl.WithField("outputTokens", usage.OutputTokens),
 	)
 
 	// Metrics.
@@ -150,6 +161,77 @@ func (p *HuggingFace) Completion(ctx context.Context, options ...provider.Func)
 	return response, nil
 }
 
+// BuildRequestBody processes the options, and builds the request body sent to
+// the provider API.
+func (p *HuggingFace) BuildRequestBody(ctx context.Context, options ...provider.Func) (*RequestBody, *provider.Options, error) {
+	//////
+	// Options initialization.
+	//////
+
+	// Prepend the provider, its default model, and capabilities to the
+	// options.
+	options = append(
+		[]provider.Func{
+			provider.WithProvider(p.GetName()),
+			provider.WithModel(p.DefaultModel),
+			provider.WithCapabilities(p.GetCapabilities()),
+		},
+		options...,
+	)
+
+	processedOptions, err := provider.NewOptionsFrom(options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Context window.
+	if err := provider.Truncate(ctx, processedOptions); err != nil {
+		return nil, nil, err
+	}
+
+	//////
+	// Messages processing.
+	//////
+
+	finalMessages := message.NewConversation(
+		processedOptions.SystemMessages,
+		processedOptions.History,
+		processedOptions.UserMessages,
+	)
+
+	//////
+	// Request body formation.
+	//////
+
+	reqBody := &RequestBody{
+		Messages: finalMessages,
+		Model:    processedOptions.Model,
+		Stream:   processedOptions.Stream,
+
+		MaxTokens:   processedOptions.MaxTokens,
+		Seed:        processedOptions.Seed,
+		Stop:        processedOptions.Stop,
+		Temperature: processedOptions.Temperature,
+		TopP:        processedOptions.TopP,
+	}
+
+	if processedOptions.JSONMode {
+		reqBody.ResponseFormat = &ResponseFormat{Type: ResponseFormatJSONObject}
+	}
+
+	// HuggingFace-specific options.
+	extra, ok, err := provider.GetExtra[Extra](processedOptions, Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok {
+		extra.apply(reqBody)
+	}
+
+	return reqBody, processedOptions, nil
+}
+
 // GetClient returns the client.
 func (p *HuggingFace) GetClient() any {
 	return p.client
@@ -159,8 +241,20 @@ func (p *HuggingFace) GetClient() any {
 // Factory.
 //////
 
-// New creates a new HuggingFace provider.
-func New(
+// New creates a new HuggingFace provider, and sets it as the one Get returns.
+func New(options ...provider.ClientFunc) (*HuggingFace, error) {
+	p, err := newProvider(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	singleton = p
+
+	return p, nil
+}
+
+// newProvider creates a new HuggingFace provider, without setting the singleton.
+func newProvider(
 	options ...provider.ClientFunc,
 ) (*HuggingFace, error) {
 	// Enforces IProvider interface implementation.
@@ -171,9 +265,9 @@ func New(
 		return nil, err
 	}
 
-	client, err := httpclient.NewDefault(
-		httpclient.WithClientName(Name),
-	)
+	p.Capabilities = Capabilities()
+
+	client, err := p.NewHTTPClient()
 	if err != nil {
 		return nil, err
 	}
@@ -191,8 +285,6 @@ func New(
 		return nil, err
 	}
 
-	singleton = provider
-
 	return provider, nil
 }
 
@@ -208,10 +300,28 @@ func NewDefault(options ...provider.ClientFunc) (*HuggingFace, error) {
 	return New(opts...)
 }
 
+// Registers the constructor, for the registry.
+func init() {
+	builtin.Register(Name, func(options ...provider.ClientFunc) (provider.IProvider, error) {
+		p, err := newProvider(options...)
+		if err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	})
+}
+
 //////
 // Exported functionalities.
 //////
 
+// Capabilities returns a copy of the capabilities of the provider, and its
+// models.
+func Capabilities() provider.Capabilities {
+	return capabilities.Clone()
+}
+
 // Get returns a setup MongoDB, or set it up.
 func Get() provider.IProvider {
 	if singleton == nil {
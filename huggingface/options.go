@@ -0,0 +1,44 @@
+package huggingface
+
+import "github.com/thalesfsp/inference/provider"
+
+//////
+// Const, vars, and types.
+//////
+
+// Extra are the HuggingFace-specific options. Zero values are not sent, so
+// the API defaults apply.
+type Extra struct {
+	// FrequencyPenalty between -2.0 and 2.0. Positive values penalize new
+	// tokens based on their existing frequency in the text so far.
+	FrequencyPenalty float64 `json:"frequencyPenalty,omitempty"`
+
+	// PresencePenalty between -2.0 and 2.0. Positive values penalize new
+	// tokens based on whether they appear in the text so far.
+	PresencePenalty float64 `json:"presencePenalty,omitempty"`
+
+	// RepetitionPenalty is the TGI parameter for repetition penalty. 1.0 means
+	// no penalty.
+	RepetitionPenalty float64 `json:"repetitionPenalty,omitempty"`
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithExtra sets the HuggingFace-specific options. Other providers ignore
+// them.
+func WithExtra(extra Extra) provider.Func {
+	return provider.WithExtra(Name, extra)
+}
+
+//////
+// Helpers.
+//////
+
+// apply merges the options into the request body.
+func (e Extra) apply(reqBody *RequestBody) {
+	reqBody.FrequencyPenalty = e.FrequencyPenalty
+	reqBody.PresencePenalty = e.PresencePenalty
+	reqBody.RepetitionPenalty = e.RepetitionPenalty
+}
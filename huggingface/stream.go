@@ -0,0 +1,71 @@
+package huggingface
+
+import (
+	"context"
+
+	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Helpers.
+//////
+
+// post posts the request body, and decodes the response body.
+func (p *HuggingFace) post(ctx context.Context, reqBody *RequestBody, respBody *ResponseBody) error {
+	resp, err := p.client.Post(
+		ctx,
+		p.Endpoint,
+		httpclient.WithBearerAuthToken(p.Token),
+		httpclient.WithReqBody(reqBody),
+		httpclient.WithRespBody(respBody),
+	)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// stream posts the request body, calls the handler with each chunk of the
+// streamed response, as server-sent events, and returns it assembled.
+func (p *HuggingFace) stream(
+	ctx context.Context,
+	reqBody *RequestBody,
+	handler provider.StreamFunc,
+) (ResponseBody, error) {
+	respBody := ResponseBody{}
+
+	resp, err := provider.PostStream(
+		ctx,
+		p.client,
+		p.Endpoint,
+		map[string]string{"Authorization": "Bearer " + p.Token},
+		reqBody,
+	)
+	if err != nil {
+		return respBody, err
+	}
+
+	defer resp.Body.Close()
+
+	stream, err := provider.ReadChatCompletionStream(resp.Body, handler)
+	if err != nil {
+		return respBody, err
+	}
+
+	respBody.Choices = []Choice{{
+		FinishReason: stream.FinishReason,
+		Message:      message.Message{Content: stream.Content, Role: message.Assistant},
+	}}
+	respBody.Created = stream.Created
+	respBody.ID = stream.ID
+	respBody.Model = stream.Model
+
+	if stream.Usage != nil {
+		respBody.Usage = Usage(*stream.Usage)
+	}
+
+	return respBody, nil
+}
@@ -6,6 +6,9 @@ import "github.com/thalesfsp/inference/message"
 // Const, vars, types.
 //////
 
+// ResponseFormatJSONObject constrains the response to a valid JSON object.
+const ResponseFormatJSONObject = "json_object"
+
 //////
 // Request body.
 
@@ -15,10 +18,23 @@ type RequestBody struct {
 	Model    string            `json:"model"`
 	Stream   bool              `json:"stream"`
 
-	MaxTokens   int     `json:"max_tokens,omitempty"`
-	Seed        int     `json:"seed,omitempty"`
-	Temperature float64 `json:"temperature,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_tokens,omitempty"`
+	Seed        int      `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Temperature float64  `json:"temperature,omitempty"`
+	TopP        float64  `json:"top_p,omitempty"`
+
+	FrequencyPenalty  float64 `json:"frequency_penalty,omitempty"`
+	PresencePenalty   float64 `json:"presence_penalty,omitempty"`
+	RepetitionPenalty float64 `json:"repetition_penalty,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+}
+
+// ResponseFormat HuggingFace API definition.
+type ResponseFormat struct {
+	// Type of the response, e.g.: ResponseFormatJSONObject.
+	Type string `json:"type"`
 }
 
 //////
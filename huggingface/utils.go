@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
 )
 
 // ProcessResponse processes the response from the API.
@@ -20,3 +21,31 @@ func ProcessResponse(resp ResponseBody) (string, error) {
 		customerror.WithStatusCode(http.StatusNoContent),
 	)
 }
+
+// ToUsage converts the API usage to the provider usage.
+func (u Usage) ToUsage() provider.Usage {
+	return provider.Usage{
+		InputTokens:  u.PromptTokens,
+		OutputTokens: u.CompletionTokens,
+	}
+}
+
+// FinishReason returns the finish reason of the first choice, if any.
+func (r ResponseBody) FinishReason() string {
+	if len(r.Choices) == 0 {
+		return ""
+	}
+
+	return r.Choices[0].FinishReason
+}
+
+// FinishReasons returns the finish reason of each choice.
+func (r ResponseBody) FinishReasons() []string {
+	finishReasons := make([]string, 0, len(r.Choices))
+
+	for _, choice := range r.Choices {
+		finishReasons = append(finishReasons, choice.FinishReason)
+	}
+
+	return finishReasons
+}
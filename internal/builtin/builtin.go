@@ -0,0 +1,38 @@
+// Package builtin exposes, to the registry, the constructors of the built-in
+// providers which don't set their package's singleton: a configuration may
+// have several instances of a type.
+package builtin
+
+import (
+	"sync"
+
+	"github.com/thalesfsp/inference/provider"
+)
+
+// Constructor creates a provider, without setting its package's singleton.
+type Constructor func(options ...provider.ClientFunc) (provider.IProvider, error)
+
+// Registered constructors, by provider name.
+var (
+	mu           sync.RWMutex
+	constructors = make(map[string]Constructor)
+)
+
+// Register registers the constructor of the provider, in the `init` of its
+// package.
+func Register(name string, constructor Constructor) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	constructors[name] = constructor
+}
+
+// Get returns the constructor of the provider, if registered.
+func Get(name string) (Constructor, bool) {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	constructor, ok := constructors[name]
+
+	return constructor, ok
+}
@@ -45,13 +45,25 @@ type Config struct {
 	// Ollama.
 	OllamaEndpoint string `default:"http://localhost:11434/api/chat" env:"OLLAMA_ENDPOINT" json:"ollamaBaseURL" validate:"omitempty,gt=0"`
 
+	//////
+	// Cassettes, recorded HTTP exchanges replayed in tests.
+	//////
+
+	// CassetteDir is where cassettes are stored, relative to the test's
+	// package.
+	CassetteDir string `default:"testdata/cassettes" env:"CASSETTE_DIR" json:"cassetteDir" validate:"omitempty,gt=0"`
+
+	// CassetteMode is one of `off`, `record`, `replay`, or `auto`. Tests
+	// default to `replay`.
+	CassetteMode string `env:"CASSETTE_MODE" json:"cassetteMode" validate:"omitempty,oneof=off record replay auto"`
+
 	//////
 	// Common timeouts.
 	//////
 
-	TimeoutLong   time.Duration `default:"30s" env:"TIMEOUT_LONG"  json:"timeoutLong"  validate:"omitempty,gt=0"`
-	TimeoutMedium time.Duration `default:"10s" env:"TIMEOUT_SHORT" json:"timeoutShort" validate:"omitempty,gt=0"`
-	TimeoutShort  time.Duration `default:"3s"  env:"TIMEOUT_SHORT" json:"timeoutShort" validate:"omitempty,gt=0"`
+	TimeoutLong   time.Duration `default:"30s" env:"TIMEOUT_LONG"   json:"timeoutLong"   validate:"omitempty,gt=0"`
+	TimeoutMedium time.Duration `default:"10s" env:"TIMEOUT_MEDIUM" json:"timeoutMedium" validate:"omitempty,gt=0"`
+	TimeoutShort  time.Duration `default:"3s"  env:"TIMEOUT_SHORT"  json:"timeoutShort"  validate:"omitempty,gt=0"`
 }
 
 //////
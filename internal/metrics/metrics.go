@@ -3,42 +3,87 @@ package metrics
 import (
 	"expvar"
 	"fmt"
-	"time"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
 )
 
 // DefaultMetricCounterLabel is the default label for the metric counter.
 const DefaultMetricCounterLabel = "counter"
 
-// NewInt creates and initializes a new expvar.Int.
+// mu guards the lookup, and creation of expvars.
+var mu sync.Mutex
+
+// NewInt creates, or reuses, if already published under the same name, an
+// expvar.Int. Names are stable, e.g.: `provider.openai.completion.counter`,
+// thus shared by entities with the same type, and name. It errors if the name
+// is published with another type.
 func NewInt(
 	entityType,
 	entityName,
 	status,
 	metricType string,
-) *expvar.Int {
-	timeInUnix := time.Now().Unix()
+) (*expvar.Int, error) {
+	return newVar(
+		fmt.Sprintf("%s.%s.%s.%s", entityType, entityName, status, metricType),
+		expvar.NewInt,
+	)
+}
 
-	counter := expvar.NewInt(fmt.Sprintf(
-		"%s.%s.%d.%s.%s",
+// NewIntCounter creates and initializes a new expvar.Int counter.
+func NewIntCounter(entityType, entityName, name string) (*expvar.Int, error) {
+	return NewInt(
 		entityType,
 		entityName,
-		timeInUnix,
-		status,
-		metricType,
-	),
+		name,
+		DefaultMetricCounterLabel,
 	)
+}
 
-	counter.Set(0)
-
-	return counter
+// NewFloat creates, or reuses, if already published under the same name, an
+// expvar.Float. Names are stable, e.g.: `provider.openai.cost.counter`, thus
+// shared by entities with the same type, and name. It errors if the name is
+// published with another type.
+func NewFloat(
+	entityType,
+	entityName,
+	status,
+	metricType string,
+) (*expvar.Float, error) {
+	return newVar(
+		fmt.Sprintf("%s.%s.%s.%s", entityType, entityName, status, metricType),
+		expvar.NewFloat,
+	)
 }
 
-// NewIntCounter creates and initializes a new expvar.Int counter.
-func NewIntCounter(entityType, entityName, name string) *expvar.Int {
-	return NewInt(
+// NewFloatCounter creates and initializes a new expvar.Float counter.
+func NewFloatCounter(entityType, entityName, name string) (*expvar.Float, error) {
+	return NewFloat(
 		entityType,
 		entityName,
 		name,
 		DefaultMetricCounterLabel,
 	)
 }
+
+// newVar returns the expvar published under the name, or publishes a new one.
+// Unlike expvar, it errors, instead of panicking, if the name is published
+// with another type.
+func newVar[T expvar.Var](name string, newFn func(name string) T) (T, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	existing := expvar.Get(name)
+	if existing == nil {
+		return newFn(name), nil
+	}
+
+	v, ok := existing.(T)
+	if !ok {
+		return v, customerror.NewInvalidError(
+			fmt.Sprintf("metric %s, already published as %T", name, existing),
+		)
+	}
+
+	return v, nil
+}
@@ -0,0 +1,35 @@
+package metrics
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewInt(t *testing.T) {
+	c, err := NewIntCounter("test", "a", "requests")
+	assert.NoError(t, err)
+
+	// Reused under the same name.
+	reused, err := NewIntCounter("test", "a", "requests")
+	assert.NoError(t, err)
+	assert.Same(t, c, reused)
+
+	other, err := NewIntCounter("test", "b", "requests")
+	assert.NoError(t, err)
+	assert.NotSame(t, c, other)
+
+	// Errors, instead of panicking, if published with another type.
+	_, err = NewFloatCounter("test", "a", "requests")
+	assert.ErrorContains(t, err, "test.a.requests.counter, already published as *expvar.Int")
+
+	f, err := NewFloatCounter("test", "a", "cost")
+	assert.NoError(t, err)
+
+	_, err = NewIntCounter("test", "a", "cost")
+	assert.ErrorContains(t, err, "already published as *expvar.Float")
+
+	reusedFloat, err := NewFloatCounter("test", "a", "cost")
+	assert.NoError(t, err)
+	assert.Same(t, f, reusedFloat)
+}
@@ -0,0 +1,357 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// ContentTypePrometheus is the content type of the Prometheus text format.
+const ContentTypePrometheus = "text/plain; version=0.0.4; charset=utf-8"
+
+// labelSeparator separates label values in series keys.
+const labelSeparator = "\xff"
+
+// labelValueReplacer escapes label values.
+var labelValueReplacer = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Default registry.
+var Default = NewRegistry()
+
+// collector writes its series in the Prometheus text format.
+type collector interface {
+	write(w io.Writer) error
+}
+
+// Registry of labeled metrics, safe for concurrent use. Registering a metric
+// twice returns the existing one.
+type Registry struct {
+	mu         sync.RWMutex
+	collectors map[string]collector
+}
+
+// desc describes a labeled metric.
+type desc struct {
+	help   string
+	labels []string
+	name   string
+}
+
+// CounterVec is a counter partitioned by labels.
+type CounterVec struct {
+	desc
+
+	mu     sync.RWMutex
+	series map[string]float64
+}
+
+// histogram is a single histogram series.
+type histogram struct {
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+// HistogramVec is a histogram partitioned by labels.
+type HistogramVec struct {
+	desc
+
+	buckets []float64
+
+	mu     sync.RWMutex
+	series map[string]*histogram
+}
+
+//////
+// Registry methods.
+//////
+
+// NewCounterVec registers, or returns the already registered counter.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if c, ok := r.collectors[name].(*CounterVec); ok {
+		return c
+	}
+
+	c := &CounterVec{
+		desc:   desc{help: help, labels: labels, name: name},
+		series: make(map[string]float64),
+	}
+
+	r.collectors[name] = c
+
+	return c
+}
+
+// NewHistogramVec registers, or returns the already registered histogram.
+// Buckets are the upper bounds, in increasing order.
+func (r *Registry) NewHistogramVec(
+	name, help string,
+	buckets []float64,
+	labels ...string,
+) *HistogramVec {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if h, ok := r.collectors[name].(*HistogramVec); ok {
+		return h
+	}
+
+	h := &HistogramVec{
+		buckets: buckets,
+		desc:    desc{help: help, labels: labels, name: name},
+		series:  make(map[string]*histogram),
+	}
+
+	r.collectors[name] = h
+
+	return h
+}
+
+// WritePrometheus writes all metrics in the Prometheus text format, sorted by
+// name.
+func (r *Registry) WritePrometheus(w io.Writer) error {
+	r.mu.RLock()
+
+	names := make([]string, 0, len(r.collectors))
+
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+
+	collectors := make([]collector, 0, len(names))
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		collectors = append(collectors, r.collectors[name])
+	}
+
+	r.mu.RUnlock()
+
+	bw := bufio.NewWriter(w)
+
+	for _, c := range collectors {
+		if err := c.write(bw); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+// Handler returns an HTTP handler exposing the metrics in the Prometheus text
+// format.
+func (r *Registry) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", ContentTypePrometheus)
+
+		if err := r.WritePrometheus(w); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+}
+
+//////
+// CounterVec methods.
+//////
+
+// Add adds v to the series identified by the label values.
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	key := c.key(labelValues)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.series[key] += v
+}
+
+// Value returns the value of the series identified by the label values.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.series[c.key(labelValues)]
+}
+
+// write implements the collector interface.
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(c.series) {
+		if _, err := fmt.Fprintf(w, "%s%s %s\n", c.name, c.labelPairs(key, ""), formatFloat(c.series[key])); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//////
+// HistogramVec methods.
+//////
+
+// Observe adds v to the series identified by the label values.
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := h.key(labelValues)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.series[key]
+	if !ok {
+		s = &histogram{counts: make([]uint64, len(h.buckets))}
+
+		h.series[key] = s
+	}
+
+	for i, upperBound := range h.buckets {
+		if v <= upperBound {
+			s.counts[i]++
+		}
+	}
+
+	s.count++
+	s.sum += v
+}
+
+// Count returns the amount of observations of the series identified by the
+// label values.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if s, ok := h.series[h.key(labelValues)]; ok {
+		return s.count
+	}
+
+	return 0
+}
+
+// write implements the collector interface.
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+
+	for _, key := range sortedKeys(h.series) {
+		s := h.series[key]
+
+		for i, upperBound := range h.buckets {
+			if _, err := fmt.Fprintf(
+				w,
+				"%s_bucket%s %d\n",
+				h.name,
+				h.labelPairs(key, formatFloat(upperBound)),
+				s.counts[i],
+			); err != nil {
+				return err
+			}
+		}
+
+		if _, err := fmt.Fprintf(
+			w,
+			"%s_bucket%s %d\n%s_sum%s %s\n%s_count%s %d\n",
+			h.name, h.labelPairs(key, "+Inf"), s.count,
+			h.name, h.labelPairs(key, ""), formatFloat(s.sum),
+			h.name, h.labelPairs(key, ""), s.count,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//////
+// Helpers.
+//////
+
+// key builds the series key from the label values. Missing values are empty.
+func (d desc) key(labelValues []string) string {
+	values := make([]string, len(d.labels))
+
+	copy(values, labelValues)
+
+	return strings.Join(values, labelSeparator)
+}
+
+// labelPairs formats the series labels, optionally with the `le` label.
+func (d desc) labelPairs(key, le string) string {
+	pairs := []string{}
+
+	if len(d.labels) > 0 {
+		for i, value := range strings.Split(key, labelSeparator) {
+			pairs = append(pairs, d.labels[i]+`="`+escape(value)+`"`)
+		}
+	}
+
+	if le != "" {
+		pairs = append(pairs, `le="`+le+`"`)
+	}
+
+	if len(pairs) == 0 {
+		return ""
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+// escape escapes backslashes, quotes, and new lines of label values.
+func escape(value string) string {
+	return labelValueReplacer.Replace(value)
+}
+
+// formatFloat formats a float in the Prometheus text format.
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	default:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	}
+}
+
+// sortedKeys returns the map keys, sorted.
+func sortedKeys[T any](m map[string]T) []string {
+	keys := make([]string, 0, len(m))
+
+	for k := range m {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
+
+//////
+// Factory.
+//////
+
+// NewRegistry returns a new, empty registry.
+func NewRegistry() *Registry {
+	return &Registry{collectors: make(map[string]collector)}
+}
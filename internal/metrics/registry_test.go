@@ -0,0 +1,52 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	c := r.NewCounterVec("requests_total", "Amount of requests.", "provider", "status")
+	assert.Same(t, c, r.NewCounterVec("requests_total", "Amount of requests.", "provider", "status"))
+
+	c.Add(1, "openai", "succeeded")
+	c.Add(2, "openai", "succeeded")
+	c.Add(1, `we"ird`, "failed")
+
+	assert.Equal(t, 3.0, c.Value("openai", "succeeded"))
+
+	h := r.NewHistogramVec("duration_seconds", "Duration.", []float64{1, 5}, "provider")
+	h.Observe(0.5, "openai")
+	h.Observe(3, "openai")
+	h.Observe(10, "openai")
+
+	assert.Equal(t, uint64(3), h.Count("openai"))
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Equal(t, ContentTypePrometheus, rec.Header().Get("Content-Type"))
+
+	expected := strings.Join([]string{
+		"# HELP duration_seconds Duration.",
+		"# TYPE duration_seconds histogram",
+		`duration_seconds_bucket{provider="openai",le="1"} 1`,
+		`duration_seconds_bucket{provider="openai",le="5"} 2`,
+		`duration_seconds_bucket{provider="openai",le="+Inf"} 3`,
+		`duration_seconds_sum{provider="openai"} 13.5`,
+		`duration_seconds_count{provider="openai"} 3`,
+		"# HELP requests_total Amount of requests.",
+		"# TYPE requests_total counter",
+		`requests_total{provider="openai",status="succeeded"} 3`,
+		`requests_total{provider="we\"ird",status="failed"} 1`,
+		"",
+	}, "\n")
+
+	assert.Equal(t, expected, rec.Body.String())
+}
@@ -0,0 +1,21 @@
+// Package memory persists per-session conversation history, and holds
+// conversations with any provider.
+//
+// Memory loads the session, sends its history along with the new user turn,
+// and stores both the user turn, and the assistant reply once answered:
+//
+//	m, err := memory.New(memory.NewMemoryStore())
+//
+//	reply, err := m.Chat(ctx, p, "session-id", "What's the capital of France?")
+//
+// Long-running conversations can be summarized: once the history is above a
+// token threshold, a cheaper provider folds the older turns into a running
+// summary, sent as a system message, while the latest turns are kept verbatim:
+//
+//	m, err := memory.New(store, memory.WithSummarization(cheap, 2000, 4))
+//
+//	summary, err := m.Summary(ctx, "session-id")
+//
+// Sessions are stored by a Store. MemoryStore, and FileStore are provided,
+// other backends, e.g.: SQLite, implement the Store interface.
+package memory
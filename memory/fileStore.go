@@ -0,0 +1,120 @@
+package memory
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// FileStore is an on-disk store, storing one JSON file per session in a
+// directory. It survives restarts.
+type FileStore struct {
+	dir string
+}
+
+//////
+// Methods.
+//////
+
+// Load returns the session. Unknown sessions are returned empty.
+func (f *FileStore) Load(_ context.Context, id string) (*Session, error) {
+	data, err := os.ReadFile(f.path(id))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &Session{ID: id}, nil
+		}
+
+		return nil, customerror.NewFailedToError("read session", customerror.WithError(err))
+	}
+
+	var session Session
+
+	if err := json.Unmarshal(data, &session); err != nil {
+		return nil, customerror.NewFailedToError("unmarshal session", customerror.WithError(err))
+	}
+
+	return &session, nil
+}
+
+// Save stores the session, replacing it. Writes are atomic.
+func (f *FileStore) Save(_ context.Context, session *Session) error {
+	data, err := json.Marshal(session)
+	if err != nil {
+		return customerror.NewFailedToError("marshal session", customerror.WithError(err))
+	}
+
+	tmp, err := os.CreateTemp(f.dir, ".tmp-*")
+	if err != nil {
+		return customerror.NewFailedToError("create session", customerror.WithError(err))
+	}
+
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+
+		return customerror.NewFailedToError("write session", customerror.WithError(err))
+	}
+
+	if err := tmp.Close(); err != nil {
+		return customerror.NewFailedToError("write session", customerror.WithError(err))
+	}
+
+	if err := os.Rename(tmp.Name(), f.path(session.ID)); err != nil {
+		return customerror.NewFailedToError("write session", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+// Delete removes the session, if any.
+func (f *FileStore) Delete(_ context.Context, id string) error {
+	if err := os.Remove(f.path(id)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return customerror.NewFailedToError("delete session", customerror.WithError(err))
+	}
+
+	return nil
+}
+
+//////
+// Helpers.
+//////
+
+// path returns the file path of the session. IDs are hashed, thus any ID is a
+// valid file name.
+func (f *FileStore) path(id string) string {
+	sum := sha256.Sum256([]byte(id))
+
+	return filepath.Join(f.dir, hex.EncodeToString(sum[:])+".json")
+}
+
+//////
+// Factory.
+//////
+
+// NewFileStore returns an on-disk store storing in `dir`, created if needed.
+func NewFileStore(dir string) (*FileStore, error) {
+	// Enforces Store interface implementation.
+	var _ Store = (*FileStore)(nil)
+
+	if dir == "" {
+		return nil, customerror.NewRequiredError("dir")
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, customerror.NewFailedToError("create sessions dir", customerror.WithError(err))
+	}
+
+	return &FileStore{dir: dir}, nil
+}
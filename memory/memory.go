@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Func allows to set options.
+type Func func(m *Memory) error
+
+// Memory holds conversations with providers, persisting them in a store. Turns
+// of the same session are serialized, sessions are independent. It's safe for
+// concurrent use.
+type Memory struct {
+	maxMessages int
+	store       Store
+	summarizer  *summarizer
+
+	mu    sync.Mutex
+	locks map[string]*sessionLock
+
+	// now allows to control time in tests.
+	now func() time.Time
+}
+
+// sessionLock serializes the turns of a session.
+type sessionLock struct {
+	mu   sync.Mutex
+	refs int
+}
+
+//////
+// Exported options.
+//////
+
+// WithMaxMessages sends only the latest `maxMessages` messages of the history.
+// All are stored. Default to all.
+func WithMaxMessages(maxMessages int) Func {
+	return func(m *Memory) error {
+		if maxMessages < 0 {
+			return customerror.NewInvalidError("maxMessages, must not be negative")
+		}
+
+		m.maxMessages = maxMessages
+
+		return nil
+	}
+}
+
+//////
+// Methods.
+//////
+
+// Chat loads the session, sends its history, and the user turn to the
+// provider, stores both the user turn, and the reply, and returns the reply.
+// Nothing is stored if the provider fails. Options are passed to the provider.
+//
+// With summarization, the older turns are first summarized if the history is
+// above the threshold, and the summary is sent as a system message.
+func (m *Memory) Chat(
+	ctx context.Context,
+	p provider.IProvider,
+	sessionID string,
+	content string,
+	options ...provider.Func,
+) (string, error) {
+	if sessionID == "" {
+		return "", customerror.NewRequiredError("sessionID")
+	}
+
+	if content == "" {
+		return "", customerror.NewRequiredError("content")
+	}
+
+	unlock := m.lock(sessionID)
+	defer unlock()
+
+	session, err := m.store.Load(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	if m.summarizer != nil {
+		// The model the turns are sent to, to count their tokens.
+		o, err := provider.NewOptionsFrom(append(
+			[]provider.Func{
+				provider.WithProvider(p.GetName()),
+				provider.WithModel(provider.DefaultModel(p)),
+				provider.WithUserMessages(content),
+			},
+			options...,
+		)...)
+		if err != nil {
+			return "", err
+		}
+
+		summarized, err := m.summarizer.summarize(ctx, o.Model, session)
+		if err != nil {
+			return "", err
+		}
+
+		if summarized {
+			session.UpdatedAt = m.now()
+
+			if err := m.store.Save(ctx, session); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	options = append(
+		[]provider.Func{
+			provider.WithHistory(m.history(session)...),
+			provider.WithUserMessages(content),
+		},
+		options...,
+	)
+
+	// Last, not to be replaced by the caller's system messages.
+	options = append(options, withSummary(session.Summary))
+
+	reply, err := p.Completion(ctx, options...)
+	if err != nil {
+		return "", err
+	}
+
+	session.Messages = append(
+		session.Messages,
+		message.Message{Content: content, Role: message.User},
+		message.Message{Content: reply, Role: message.Assistant},
+	)
+	session.UpdatedAt = m.now()
+
+	if err := m.store.Save(ctx, session); err != nil {
+		return "", err
+	}
+
+	return reply, nil
+}
+
+// Session returns the session.
+func (m *Memory) Session(ctx context.Context, sessionID string) (*Session, error) {
+	if sessionID == "" {
+		return nil, customerror.NewRequiredError("sessionID")
+	}
+
+	return m.store.Load(ctx, sessionID)
+}
+
+// Clear removes the session.
+func (m *Memory) Clear(ctx context.Context, sessionID string) error {
+	if sessionID == "" {
+		return customerror.NewRequiredError("sessionID")
+	}
+
+	unlock := m.lock(sessionID)
+	defer unlock()
+
+	return m.store.Delete(ctx, sessionID)
+}
+
+// GetStore returns the store.
+func (m *Memory) GetStore() Store {
+	return m.store
+}
+
+//////
+// Helpers.
+//////
+
+// history returns the messages of the session sent to the provider. It
+// starts with a user turn, as some providers require.
+func (m *Memory) history(session *Session) []message.Message {
+	messages := session.Messages
+
+	if m.maxMessages > 0 && len(messages) > m.maxMessages {
+		messages = messages[len(messages)-m.maxMessages:]
+	}
+
+	for len(messages) > 0 && messages[0].Role != message.User {
+		messages = messages[1:]
+	}
+
+	return messages
+}
+
+// lock locks the session, and returns the function unlocking it.
+func (m *Memory) lock(sessionID string) func() {
+	m.mu.Lock()
+
+	l, ok := m.locks[sessionID]
+	if !ok {
+		l = &sessionLock{}
+
+		m.locks[sessionID] = l
+	}
+
+	l.refs++
+
+	m.mu.Unlock()
+
+	l.mu.Lock()
+
+	return func() {
+		l.mu.Unlock()
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+
+		l.refs--
+
+		if l.refs == 0 {
+			delete(m.locks, sessionID)
+		}
+	}
+}
+
+//////
+// Factory.
+//////
+
+// New returns a memory persisting sessions in the store.
+func New(store Store, options ...Func) (*Memory, error) {
+	if store == nil {
+		return nil, customerror.NewRequiredError("store")
+	}
+
+	m := &Memory{
+		locks: make(map[string]*sessionLock),
+		now:   time.Now,
+		store: store,
+	}
+
+	for _, option := range options {
+		if err := option(m); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
@@ -0,0 +1,71 @@
+package memory
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// MemoryStore is an in-memory store. Sessions are lost on restart.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]Session
+}
+
+//////
+// Methods.
+//////
+
+// Load returns the session. Unknown sessions are returned empty.
+func (s *MemoryStore) Load(_ context.Context, id string) (*Session, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions[id]
+	if !ok {
+		return &Session{ID: id}, nil
+	}
+
+	session.Messages = slices.Clone(session.Messages)
+
+	return &session, nil
+}
+
+// Save stores the session, replacing it.
+func (s *MemoryStore) Save(_ context.Context, session *Session) error {
+	stored := *session
+	stored.Messages = slices.Clone(session.Messages)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sessions[session.ID] = stored
+
+	return nil
+}
+
+// Delete removes the session, if any.
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+
+	return nil
+}
+
+//////
+// Factory.
+//////
+
+// NewMemoryStore returns an empty, in-memory store.
+func NewMemoryStore() *MemoryStore {
+	// Enforces Store interface implementation.
+	var _ Store = (*MemoryStore)(nil)
+
+	return &MemoryStore{sessions: make(map[string]Session)}
+}
@@ -0,0 +1,250 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/providertest"
+)
+
+func TestChat(t *testing.T) {
+	ctx := context.Background()
+
+	user := func(content string) message.Message { return message.Message{Content: content, Role: message.User} }
+	assistant := func(content string) message.Message {
+		return message.Message{Content: content, Role: message.Assistant}
+	}
+
+	p, err := providertest.New(providertest.WithResponses(
+		providertest.Response{
+			Content: "Hi Ana!",
+			Expect: providertest.ExpectAll(
+				providertest.ExpectHistory(),
+				providertest.ExpectUserMessages("Hi, I'm Ana"),
+			),
+		},
+		providertest.Response{
+			Content: "Your name is Ana.",
+			Expect: providertest.ExpectAll(
+				providertest.ExpectHistory(user("Hi, I'm Ana"), assistant("Hi Ana!")),
+				providertest.ExpectUserMessages("What's my name?"),
+			),
+		},
+		providertest.Response{Err: errors.New("provider is unavailable")},
+	))
+	assert.NoError(t, err)
+
+	m, err := New(NewMemoryStore())
+	assert.NoError(t, err)
+
+	reply, err := m.Chat(ctx, p, "s1", "Hi, I'm Ana")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hi Ana!", reply)
+
+	reply, err = m.Chat(ctx, p, "s1", "What's my name?", provider.WithTemperature(0.1))
+	assert.NoError(t, err)
+	assert.Equal(t, "Your name is Ana.", reply)
+
+	last, _ := p.LastCall()
+	assert.Equal(t, 0.1, last.Options.Temperature)
+
+	// Failures aren't stored.
+	_, err = m.Chat(ctx, p, "s1", "Are you there?")
+	assert.Error(t, err)
+
+	session, err := m.Session(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		user("Hi, I'm Ana"),
+		assistant("Hi Ana!"),
+		user("What's my name?"),
+		assistant("Your name is Ana."),
+	}, session.Messages)
+	assert.False(t, session.UpdatedAt.IsZero())
+
+	// Sessions are independent.
+	session, err = m.Session(ctx, "s2")
+	assert.NoError(t, err)
+	assert.Empty(t, session.Messages)
+
+	assert.NoError(t, m.Clear(ctx, "s1"))
+
+	session, err = m.Session(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Empty(t, session.Messages)
+
+	// Errors.
+	_, err = m.Chat(ctx, p, "", "hi")
+	assert.Error(t, err)
+
+	_, err = m.Chat(ctx, p, "s1", "")
+	assert.Error(t, err)
+
+	_, err = New(nil)
+	assert.Error(t, err)
+}
+
+func TestChat_maxMessages(t *testing.T) {
+	ctx := context.Background()
+
+	p, err := providertest.New(providertest.WithFallback(providertest.Response{Content: "ok"}))
+	assert.NoError(t, err)
+
+	m, err := New(NewMemoryStore(), WithMaxMessages(3))
+	assert.NoError(t, err)
+
+	for i := range 3 {
+		_, err := m.Chat(ctx, p, "s1", fmt.Sprint(i))
+		assert.NoError(t, err)
+	}
+
+	// The latest 3 would start with an assistant turn, thus only 2 are sent.
+	last, _ := p.LastCall()
+	assert.Equal(t, []message.Message{
+		{Content: "1", Role: message.User},
+		{Content: "ok", Role: message.Assistant},
+	}, last.Options.History)
+
+	// All are stored.
+	session, err := m.Session(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Len(t, session.Messages, 6)
+
+	_, err = New(NewMemoryStore(), WithMaxMessages(-1))
+	assert.Error(t, err)
+}
+
+func TestChat_concurrent(t *testing.T) {
+	ctx := context.Background()
+
+	p, err := providertest.New(providertest.WithFallback(providertest.Response{Content: "ok"}))
+	assert.NoError(t, err)
+
+	m, err := New(NewMemoryStore())
+	assert.NoError(t, err)
+
+	var wg sync.WaitGroup
+
+	for i := range 20 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			_, err := m.Chat(ctx, p, fmt.Sprint("s", i%2), "hi")
+			assert.NoError(t, err)
+		}()
+	}
+
+	wg.Wait()
+
+	// Turns of the same session are serialized, none is lost.
+	for _, id := range []string{"s0", "s1"} {
+		session, err := m.Session(ctx, id)
+		assert.NoError(t, err)
+		assert.Len(t, session.Messages, 20)
+	}
+
+	assert.Empty(t, m.locks)
+}
+
+func TestChat_summarization(t *testing.T) {
+	ctx := context.Background()
+
+	p, err := providertest.New(providertest.WithFallback(providertest.Response{Content: "ok"}))
+	assert.NoError(t, err)
+
+	cheap, err := providertest.New(providertest.WithResponses(
+		providertest.Response{
+			Content: " S1 ",
+			Expect: providertest.ExpectAll(
+				providertest.ExpectModel("cheap-model"),
+				providertest.ExpectSystemMessages(DefaultSummaryPrompt),
+				providertest.ExpectUserMessages("New turns:\nuser: turn 0\nassistant: ok\nuser: turn 1\nassistant: ok\n"),
+			),
+		},
+		providertest.Response{
+			Content: "S2",
+			Expect:  providertest.ExpectUserMessages("Current summary:\nS1\n\nNew turns:\nuser: turn 2\nassistant: ok\n"),
+		},
+	))
+	assert.NoError(t, err)
+
+	// Each turn is 11 tokens, the summary 14.
+	m, err := New(NewMemoryStore(), WithSummarization(cheap, 30, 1, provider.WithModel("cheap-model")))
+	assert.NoError(t, err)
+
+	for i := range 5 {
+		_, err := m.Chat(ctx, p, "s1", fmt.Sprint("turn ", i), provider.WithSystemMessages("be brief"))
+		assert.NoError(t, err)
+
+		if i == 3 {
+			last, _ := p.LastCall()
+			assert.Equal(t, []string{"be brief", provider.SummaryPrefix + "S1"}, last.Options.SystemMessages)
+			assert.Equal(t, []message.Message{
+				{Content: "turn 2", Role: message.User},
+				{Content: "ok", Role: message.Assistant},
+			}, last.Options.History)
+		}
+	}
+
+	assert.Len(t, cheap.Calls(), 2)
+
+	summary, err := m.Summary(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, "S2", summary)
+
+	session, err := m.Session(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{Content: "turn 3", Role: message.User},
+		{Content: "ok", Role: message.Assistant},
+		{Content: "turn 4", Role: message.User},
+		{Content: "ok", Role: message.Assistant},
+	}, session.Messages)
+
+	// Summarization failures fail the turn, nothing is stored.
+	cheap.Enqueue(providertest.Response{Err: errors.New("provider is unavailable")})
+
+	_, err = m.Chat(ctx, p, "s1", "turn 5")
+	assert.Error(t, err)
+
+	session, err = m.Session(ctx, "s1")
+	assert.NoError(t, err)
+	assert.Len(t, session.Messages, 4)
+
+	// Tokens are counted for the model of the turn.
+	defer provider.SetTokenCounter(nil)
+
+	var counted string
+
+	provider.SetTokenCounter(func(model string, messages []message.Message) (int, error) {
+		counted = model
+
+		return provider.EstimateMessagesTokens(model, messages)
+	})
+
+	_, err = m.Chat(ctx, p, "s2", "turn 0", provider.WithModel("other-model"))
+	assert.NoError(t, err)
+	assert.Equal(t, "other-model", counted)
+
+	// Options.
+	_, err = New(NewMemoryStore(), WithSummarization(nil, 30, 1))
+	assert.Error(t, err)
+
+	_, err = New(NewMemoryStore(), WithSummarization(cheap, 0, 1))
+	assert.Error(t, err)
+
+	_, err = New(NewMemoryStore(), WithSummaryPrompt("summarize"))
+	assert.Error(t, err)
+
+	m, err = New(NewMemoryStore(), WithSummarization(cheap, 30, 1), WithSummaryPrompt("summarize"))
+	assert.NoError(t, err)
+	assert.Equal(t, "summarize", m.summarizer.prompt)
+}
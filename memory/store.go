@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/thalesfsp/inference/message"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Session is a conversation.
+type Session struct {
+	// ID of the session.
+	ID string `json:"id"`
+
+	// Messages of the conversation, user, and assistant turns, oldest first.
+	// With summarization, only the turns not summarized yet.
+	Messages []message.Message `json:"messages"`
+
+	// Summary of the older turns, if summarized.
+	Summary string `json:"summary,omitempty"`
+
+	// UpdatedAt is when the session was last saved.
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// Store persists sessions. Implementations must be safe for concurrent use.
+type Store interface {
+	// Load returns the session. Unknown sessions are returned empty.
+	Load(ctx context.Context, id string) (*Session, error)
+
+	// Save stores the session, replacing it.
+	Save(ctx context.Context, session *Session) error
+
+	// Delete removes the session, if any.
+	Delete(ctx context.Context, id string) error
+}
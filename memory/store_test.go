@@ -0,0 +1,52 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+)
+
+func TestStores(t *testing.T) {
+	fileStore, err := NewFileStore(t.TempDir())
+	assert.NoError(t, err)
+
+	_, err = NewFileStore("")
+	assert.Error(t, err)
+
+	for name, store := range map[string]Store{
+		"Memory": NewMemoryStore(),
+		"File":   fileStore,
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx := context.Background()
+
+			// Unknown sessions are empty.
+			session, err := store.Load(ctx, "../session/1")
+			assert.NoError(t, err)
+			assert.Equal(t, &Session{ID: "../session/1"}, session)
+
+			session.Messages = append(session.Messages, message.Message{Content: "hi", Role: message.User})
+			session.UpdatedAt = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+			assert.NoError(t, store.Save(ctx, session))
+
+			// Changes after saving aren't stored.
+			session.Messages[0].Content = "changed"
+
+			loaded, err := store.Load(ctx, "../session/1")
+			assert.NoError(t, err)
+			assert.Equal(t, "hi", loaded.Messages[0].Content)
+			assert.True(t, session.UpdatedAt.Equal(loaded.UpdatedAt))
+
+			assert.NoError(t, store.Delete(ctx, "../session/1"))
+			assert.NoError(t, store.Delete(ctx, "../session/1"))
+
+			loaded, err = store.Load(ctx, "../session/1")
+			assert.NoError(t, err)
+			assert.Empty(t, loaded.Messages)
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// DefaultSummaryPrompt is the system prompt of the summarizer.
+const DefaultSummaryPrompt = "You maintain the running summary of a conversation between a user, and an " +
+	"assistant. Update the current summary with the new turns. Keep facts, names, decisions, preferences, " +
+	"and open questions. Be concise. Reply with the updated summary only."
+
+// summarizer summarizes the older turns of sessions.
+type summarizer struct {
+	keepTurns int
+	options   []provider.Func
+	prompt    string
+	provider  provider.IProvider
+	threshold int
+}
+
+//////
+// Exported options.
+//////
+
+// WithSummarization summarizes the older turns of a session into a running
+// summary, once its history is above `threshold` tokens, keeping the latest
+// `keepTurns` turns (a user message, and its reply) verbatim. The summary is
+// sent as a system message. Use a cheaper provider, or model through `p`, and
+// `options`.
+func WithSummarization(p provider.IProvider, threshold, keepTurns int, options ...provider.Func) Func {
+	return func(m *Memory) error {
+		if p == nil {
+			return customerror.NewRequiredError("summarization provider")
+		}
+
+		if threshold <= 0 || keepTurns < 0 {
+			return customerror.NewInvalidError("summarization, threshold must be positive, and keepTurns not negative")
+		}
+
+		m.summarizer = &summarizer{
+			keepTurns: keepTurns,
+			options:   options,
+			prompt:    DefaultSummaryPrompt,
+			provider:  p,
+			threshold: threshold,
+		}
+
+		return nil
+	}
+}
+
+// WithSummaryPrompt sets the system prompt of the summarizer. Default to
+// DefaultSummaryPrompt. Set it after WithSummarization.
+func WithSummaryPrompt(prompt string) Func {
+	return func(m *Memory) error {
+		if m.summarizer == nil {
+			return customerror.NewRequiredError("summarization, set WithSummarization first")
+		}
+
+		if prompt != "" {
+			m.summarizer.prompt = prompt
+		}
+
+		return nil
+	}
+}
+
+//////
+// Methods.
+//////
+
+// Summary returns the running summary of the session, if any.
+func (m *Memory) Summary(ctx context.Context, sessionID string) (string, error) {
+	session, err := m.Session(ctx, sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	return session.Summary, nil
+}
+
+//////
+// Helpers.
+//////
+
+// summarize folds the older turns of the session into its summary, if its
+// history is above the threshold. It returns true if it did.
+func (s *summarizer) summarize(ctx context.Context, model string, session *Session) (bool, error) {
+	tokens, err := provider.GetTokenCounter()(model, append(summaryMessages(session.Summary), session.Messages...))
+	if err != nil {
+		return false, customerror.NewFailedToError("count tokens", customerror.WithError(err))
+	}
+
+	if tokens <= s.threshold {
+		return false, nil
+	}
+
+	// Keep the latest turns, starting with a user message.
+	split := max(0, len(session.Messages)-s.keepTurns*2)
+
+	for split > 0 && split < len(session.Messages) && session.Messages[split].Role != message.User {
+		split--
+	}
+
+	if split == 0 {
+		return false, nil
+	}
+
+	summary, err := s.provider.Completion(ctx, append(
+		[]provider.Func{
+			provider.WithSystemMessages(s.prompt),
+			provider.WithUserMessages(transcript(session.Summary, session.Messages[:split])),
+		},
+		s.options...,
+	)...)
+	if err != nil {
+		return false, customerror.NewFailedToError("summarize the conversation", customerror.WithError(err))
+	}
+
+	session.Summary = strings.TrimSpace(summary)
+	session.Messages = session.Messages[split:]
+
+	return true, nil
+}
+
+// transcript formats the current summary, and the turns to summarize.
+func transcript(summary string, messages []message.Message) string {
+	var b strings.Builder
+
+	if summary != "" {
+		fmt.Fprintf(&b, "Current summary:\n%s\n\n", summary)
+	}
+
+	b.WriteString("New turns:\n")
+
+	for _, m := range messages {
+		fmt.Fprintf(&b, "%s: %s\n", m.Role, m.Content)
+	}
+
+	return b.String()
+}
+
+// summaryMessages returns the summary as system messages, if any.
+func summaryMessages(summary string) []message.Message {
+	if summary == "" {
+		return nil
+	}
+
+	return message.NewMessages([]string{provider.SummaryPrefix + summary}, nil)
+}
+
+// withSummary appends the summary to the system messages, if any.
+func withSummary(summary string) provider.Func {
+	return func(o *provider.Options) error {
+		if summary != "" {
+			o.SystemMessages = append(o.SystemMessages, provider.SummaryPrefix+summary)
+		}
+
+		return nil
+	}
+}
@@ -11,6 +11,17 @@ type Message struct {
 func NewMessages(
 	systemMessages []string,
 	userMessages []string,
+) []Message {
+	return NewConversation(systemMessages, nil, userMessages)
+}
+
+// NewConversation creates a new list of messages where system messages come
+// first, then the history (previous user, and assistant turns) in order, and
+// user messages last.
+func NewConversation(
+	systemMessages []string,
+	history []Message,
+	userMessages []string,
 ) []Message {
 	// Stores the final messages.
 	finalMessages := []Message{}
@@ -22,6 +33,8 @@ func NewMessages(
 		})
 	}
 
+	finalMessages = append(finalMessages, history...)
+
 	for _, userMessage := range userMessages {
 		finalMessages = append(finalMessages, Message{
 			Content: userMessage,
@@ -4,6 +4,9 @@ package message
 type Role = string
 
 const (
+	// Assistant role.
+	Assistant Role = "assistant"
+
 	// User role.
 	User Role = "user"
 
@@ -0,0 +1,49 @@
+package ollama
+
+import (
+	"context"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+)
+
+//////
+// Embeddings.
+//////
+
+// Embed returns the embedding of each input, in order, using the embedding
+// model, e.g.: `nomic-embed-text`.
+func (p *Ollama) Embed(ctx context.Context, model string, input ...string) ([][]float64, error) {
+	if model == "" {
+		return nil, customerror.NewRequiredError("model")
+	}
+
+	if len(input) == 0 {
+		return nil, customerror.NewRequiredError("input")
+	}
+
+	u, err := apiURL(p.Endpoint, "embed")
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody EmbedResponseBody
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithReqBody(EmbedRequestBody{Input: input, Model: model}),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if len(respBody.Embeddings) != len(input) {
+		return nil, customerror.NewInvalidError("embed response, embeddings don't match the input")
+	}
+
+	return respBody.Embeddings, nil
+}
@@ -0,0 +1,308 @@
+package ollama
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/status"
+	"github.com/thalesfsp/sypl"
+	"github.com/thalesfsp/sypl/level"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// KeepAliveUnload is the keep alive value which unloads a model from memory
+// immediately.
+const KeepAliveUnload = "0"
+
+// pullStatusSuccess is the status sent by the pull model API once done.
+const pullStatusSuccess = "success"
+
+// PullProgressFunc is called for every progress update streamed while pulling
+// a model. Returning an error aborts the pull.
+type PullProgressFunc func(progress PullProgress) error
+
+//////
+// Model management.
+//////
+
+// ListModels lists the models available locally.
+func (p *Ollama) ListModels(ctx context.Context) ([]Model, error) {
+	u, err := apiURL(p.Endpoint, "tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody ListModelsResponseBody
+
+	resp, err := p.client.Get(ctx, u, httpclient.WithRespBody(&respBody))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return respBody.Models, nil
+}
+
+// ListRunningModels lists the models currently loaded into memory.
+func (p *Ollama) ListRunningModels(ctx context.Context) ([]RunningModel, error) {
+	u, err := apiURL(p.Endpoint, "ps")
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody ListRunningModelsResponseBody
+
+	resp, err := p.client.Get(ctx, u, httpclient.WithRespBody(&respBody))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return respBody.Models, nil
+}
+
+// HasModel returns true if the model is available locally.
+func (p *Ollama) HasModel(ctx context.Context, model string) (bool, error) {
+	models, err := p.ListModels(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	model = NormalizeModelName(model)
+
+	for _, m := range models {
+		if NormalizeModelName(m.Name) == model || NormalizeModelName(m.Model) == model {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// PullModel downloads a model from the registry. Progress updates are streamed
+// to `progress`, if set.
+//
+// NOTE: Pulling may take a long time, so the client's timeout does not apply.
+// Use the context to control it.
+func (p *Ollama) PullModel(
+	ctx context.Context,
+	model string,
+	progress PullProgressFunc,
+) error {
+	if model == "" {
+		return customerror.NewRequiredError("model")
+	}
+
+	u, err := apiURL(p.Endpoint, "pull")
+	if err != nil {
+		return err
+	}
+
+	reqBody, err := json.Marshal(PullRequestBody{
+		Model:  model,
+		Stream: true,
+	})
+	if err != nil {
+		return customerror.NewFailedToError("marshal request body", customerror.WithError(err))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, u, bytes.NewReader(reqBody))
+	if err != nil {
+		return customerror.NewFailedToError("create request", customerror.WithError(err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	// Same transport as the provider's client, but without timeout.
+	client := &http.Client{Transport: p.client.GetClient().Transport}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return httpclient.HandleHTTPResponseError(resp, u, "pull model", err)
+	}
+
+	defer resp.Body.Close()
+
+	if !httpclient.IsRespSuccess(resp) {
+		return httpclient.HandleHTTPResponseError(resp, u, "pull model", nil)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var update PullProgress
+
+		if err := decoder.Decode(&update); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return customerror.NewFailedToError("decode pull progress", customerror.WithError(err))
+		}
+
+		if update.Error != "" {
+			return customerror.NewFailedToError(
+				"pull model",
+				customerror.WithField("model", model),
+				customerror.WithField("reason", update.Error),
+			)
+		}
+
+		if progress != nil {
+			if err := progress(update); err != nil {
+				return err
+			}
+		}
+
+		if update.Status == pullStatusSuccess {
+			p.GetLogger().PrintlnWithOptions(
+				level.Debug,
+				fmt.Sprintf("Model %s", status.Retrieved.String()),
+				sypl.WithField("model", model),
+			)
+
+			return nil
+		}
+	}
+
+	return customerror.NewFailedToError(
+		"pull model",
+		customerror.WithField("model", model),
+		customerror.WithField("reason", "stream ended before success"),
+	)
+}
+
+// ShowModel returns information about a model such as its context length,
+// parameters, and template.
+func (p *Ollama) ShowModel(ctx context.Context, model string) (*ModelInfo, error) {
+	if model == "" {
+		return nil, customerror.NewRequiredError("model")
+	}
+
+	u, err := apiURL(p.Endpoint, "show")
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody ModelInfo
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithReqBody(ModelRequestBody{Model: model}),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	respBody.ContextLength = ContextLengthFromModelInfo(respBody.ModelInfo)
+
+	return &respBody, nil
+}
+
+// DeleteModel deletes a model, and its data.
+func (p *Ollama) DeleteModel(ctx context.Context, model string) error {
+	if model == "" {
+		return customerror.NewRequiredError("model")
+	}
+
+	u, err := apiURL(p.Endpoint, "delete")
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.client.Delete(
+		ctx,
+		u,
+		httpclient.WithReqBody(ModelRequestBody{Model: model}),
+	)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	p.GetLogger().PrintlnWithOptions(
+		level.Debug,
+		fmt.Sprintf("Model %s", status.Deleted.String()),
+		sypl.WithField("model", model),
+	)
+
+	return nil
+}
+
+// LoadModel loads (warms up) a model into memory, keeping it loaded for
+// `keepAlive`, e.g.: "10m", or "-1m" to keep it loaded forever. An empty
+// `keepAlive` uses the server default.
+func (p *Ollama) LoadModel(ctx context.Context, model, keepAlive string) error {
+	if model == "" {
+		return customerror.NewRequiredError("model")
+	}
+
+	resp, err := p.client.Post(
+		ctx,
+		p.Endpoint,
+		httpclient.WithReqBody(LoadRequestBody{
+			KeepAlive: keepAlive,
+			Messages:  []message.Message{},
+			Model:     model,
+		}),
+		httpclient.WithRespBody(&ResponseBody{}),
+	)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	return nil
+}
+
+// UnloadModel unloads a model from memory.
+func (p *Ollama) UnloadModel(ctx context.Context, model string) error {
+	return p.LoadModel(ctx, model, KeepAliveUnload)
+}
+
+// EnsureModel makes sure the model is available locally, pulling it if not.
+// If `model` is empty, the provider's default model is used. Use it before
+// serving traffic to avoid failing on the first completion.
+func (p *Ollama) EnsureModel(
+	ctx context.Context,
+	model string,
+	progress PullProgressFunc,
+) error {
+	if model == "" {
+		model = p.DefaultModel
+	}
+
+	if model == "" {
+		return customerror.NewRequiredError("model")
+	}
+
+	ok, err := p.HasModel(ctx, model)
+	if err != nil {
+		return err
+	}
+
+	if ok {
+		return nil
+	}
+
+	return p.PullModel(ctx, model, progress)
+}
@@ -0,0 +1,139 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestModelManagement(t *testing.T) {
+	pulled := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/tags":
+			models := []Model{{Name: "llama3.2:3b", Model: "llama3.2:3b"}}
+
+			if pulled {
+				models = append(models, Model{Name: "qwen2.5:latest", Model: "qwen2.5:latest"})
+			}
+
+			_ = json.NewEncoder(w).Encode(ListModelsResponseBody{Models: models})
+		case "/api/pull":
+			var reqBody PullRequestBody
+
+			_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+			assert.Equal(t, "qwen2.5", reqBody.Model)
+
+			for _, s := range []string{"pulling manifest", "downloading", "success"} {
+				_ = json.NewEncoder(w).Encode(PullProgress{Status: s})
+			}
+
+			pulled = true
+		case "/api/show":
+			_, _ = w.Write([]byte(`{"template":"{{ .Prompt }}","parameters":"stop \"<|eot_id|>\"","model_info":{"general.architecture":"llama","llama.context_length":131072}}`))
+		case "/api/delete":
+			assert.Equal(t, http.MethodDelete, r.Method)
+		case "/api/chat":
+			var reqBody LoadRequestBody
+
+			_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+			assert.Equal(t, KeepAliveUnload, reqBody.KeepAlive)
+			assert.Empty(t, reqBody.Messages)
+
+			_, _ = w.Write([]byte(`{"model":"llama3.2:3b","done":true,"done_reason":"unload"}`))
+		case "/api/embed":
+			var reqBody EmbedRequestBody
+
+			_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+			assert.Equal(t, "nomic-embed-text", reqBody.Model)
+			assert.Equal(t, []string{"a", "b"}, reqBody.Input)
+
+			_ = json.NewEncoder(w).Encode(EmbedResponseBody{Embeddings: [][]float64{{1, 0}, {0, 1}}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	o, err := New(
+		provider.WithEndpoint(server.URL+"/api/chat"),
+		provider.WithDefaulModel("qwen2.5"),
+	)
+	assert.NoError(t, err)
+
+	ctx := context.Background()
+
+	t.Run("ListModels", func(t *testing.T) {
+		models, err := o.ListModels(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, models, 1)
+	})
+
+	t.Run("EnsureModel", func(t *testing.T) {
+		statuses := []string{}
+
+		err := o.EnsureModel(ctx, "", func(progress PullProgress) error {
+			statuses = append(statuses, progress.Status)
+
+			return nil
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"pulling manifest", "downloading", "success"}, statuses)
+
+		ok, err := o.HasModel(ctx, "qwen2.5")
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("ShowModel", func(t *testing.T) {
+		info, err := o.ShowModel(ctx, "llama3.2:3b")
+		assert.NoError(t, err)
+		assert.Equal(t, 131072, info.ContextLength)
+		assert.Equal(t, "{{ .Prompt }}", info.Template)
+	})
+
+	t.Run("DeleteModel", func(t *testing.T) {
+		assert.NoError(t, o.DeleteModel(ctx, "llama3.2:3b"))
+	})
+
+	t.Run("UnloadModel", func(t *testing.T) {
+		assert.NoError(t, o.UnloadModel(ctx, "llama3.2:3b"))
+	})
+
+	t.Run("Embed", func(t *testing.T) {
+		embeddings, err := o.Embed(ctx, "nomic-embed-text", "a", "b")
+		assert.NoError(t, err)
+		assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, embeddings)
+
+		_, err = o.Embed(ctx, "", "a")
+		assert.Error(t, err)
+	})
+}
+
+func TestAPIURL(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{endpoint: "http://localhost:11434/api/chat", want: "http://localhost:11434/api/tags"},
+		{endpoint: "http://localhost:11434/api/", want: "http://localhost:11434/api/tags"},
+		{endpoint: "http://localhost:11434", want: "http://localhost:11434/api/tags"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.endpoint, func(t *testing.T) {
+			got, err := apiURL(tt.endpoint, "tags")
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/internal/builtin"
 	"github.com/thalesfsp/inference/internal/config"
 	"github.com/thalesfsp/inference/message"
 	"github.com/thalesfsp/inference/provider"
@@ -26,6 +27,17 @@ const Name = "ollama"
 // Singleton.
 var singleton provider.IProvider
 
+// capabilities of the provider, and its models.
+//
+// NOTE: Context length, tools, and vision depend on the model being served,
+// use ShowModel to find out.
+var capabilities = provider.Capabilities{
+	JSONMode:  true,
+	Seed:      true,
+	Streaming: true,
+	TopK:      true,
+}
+
 // Ollama provider definition.
 type Ollama struct {
 	*provider.Provider
@@ -47,47 +59,41 @@ type Ollama struct {
 // NOTE: Not all options are available for all providers.
 func (p *Ollama) Completion(ctx context.Context, options ...provider.Func) (string, error) {
 	//////
-	// Options initialization.
+	// Tracing.
 	//////
 
-	// Prepend the default model to the options.
-	options = append(
-		[]provider.Func{
-			provider.WithModel(p.DefaultModel),
-		},
-		options...,
-	)
-
-	processedOptions, err := provider.NewOptionsFrom(options...)
-	if err != nil {
-		return "", err
-	}
+	// Started first, so invalid requests are traced, and measured too.
+	ctx, span := p.StartSpan(ctx)
+	defer span.End()
 
 	//////
-	// Messages processing.
+	// Request.
 	//////
 
-	finalMessages := message.NewMessages(
-		processedOptions.SystemMessages,
-		processedOptions.UserMessages,
-	)
+	reqBody, processedOptions, err := p.BuildRequestBody(ctx, options...)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	span.SetRequest(processedOptions)
 
 	//////
-	// Request body formation.
+	// Budget, and usage accounting.
 	//////
 
-	reqBody := &RequestBody{
-		Messages: finalMessages,
-		Model:    processedOptions.Model,
-		Stream:   processedOptions.Stream,
+	settle, err := p.Reserve(ctx, processedOptions)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
 
-		Options: RequestBodyOptions{
-			Temperature: processedOptions.Temperature,
-			TopK:        processedOptions.TopK,
-			TopP:        processedOptions.TopP,
-		},
+		return "", span.Fail(err)
 	}
 
+	var usage provider.Usage
+
+	defer func() { settle(usage) }()
+
 	//////
 	// Call LLM provider.
 	//////
@@ -98,26 +104,30 @@ func (p *Ollama) Completion(ctx context.Context, options ...provider.Func) (stri
 	now := time.Now()
 
 	// Actual call.
-	resp, err := p.client.Post(
-		ctx,
-		p.Endpoint,
-		httpclient.WithReqBody(reqBody),
-		httpclient.WithRespBody(&respBody),
-	)
+	if processedOptions.StreamHandler != nil {
+		respBody, err = p.stream(ctx, reqBody, processedOptions.StreamHandler)
+	} else {
+		err = p.post(ctx, reqBody, &respBody)
+	}
+
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
 	}
 
-	defer resp.Body.Close()
+	usage = respBody.ToUsage()
+	usage.FinishReason = respBody.DoneReason
+
+	span.SetResponse("", respBody.Model, respBody.DoneReason)
+	span.SetUsage(usage)
 
 	// Response processing.
 	response, err := ProcessResponse(respBody)
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
 	}
 
 	// Optional response body processing.
@@ -126,7 +136,7 @@ func (p *Ollama) Completion(ctx context.Context, options ...provider.Func) (stri
 			[]byte(response),
 			processedOptions.ResponseBody,
 		); err != nil {
-			return "", err
+			return "", span.Fail(err)
 		}
 	}
 
@@ -139,6 +149,8 @@ func (p *Ollama) Completion(ctx context.Context, options ...provider.Func) (stri
 		level.Debug,
 		fmt.Sprintf("Completion %s", status.Created.String()),
 		sypl.WithField("duration", time.Since(now)),
+		sypl.WithField("inputTokens", usage.InputTokens),
+		sypl.WithField("outputTokens", usage.OutputTokens),
 	)
 
 	// Metrics.
@@ -147,6 +159,85 @@ func (p *Ollama) Completion(ctx context.Context, options ...provider.Func) (stri
 	return response, nil
 }
 
+// BuildRequestBody processes the options, and builds the request body sent to
+// the provider API.
+func (p *Ollama) BuildRequestBody(ctx context.Context, options ...provider.Func) (*RequestBody, *provider.Options, error) {
+	//////
+	// Options initialization.
+	//////
+
+	// Prepend the provider, its default model, and capabilities to the
+	// options.
+	options = append(
+		[]provider.Func{
+			provider.WithProvider(p.GetName()),
+			provider.WithModel(p.DefaultModel),
+			provider.WithCapabilities(p.GetCapabilities()),
+		},
+		options...,
+	)
+
+	processedOptions, err := provider.NewOptionsFrom(options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Context window.
+	if err := provider.Truncate(ctx, processedOptions); err != nil {
+		return nil, nil, err
+	}
+
+	//////
+	// Messages processing.
+	//////
+
+	finalMessages := message.NewConversation(
+		processedOptions.SystemMessages,
+		processedOptions.History,
+		processedOptions.UserMessages,
+	)
+
+	//////
+	// Request body formation.
+	//////
+
+	reqBody := &RequestBody{
+		Messages: finalMessages,
+		Model:    processedOptions.Model,
+		Stream:   processedOptions.Stream,
+
+		Options: RequestBodyOptions{
+			Seed:        processedOptions.Seed,
+			Stop:        processedOptions.Stop,
+			Temperature: processedOptions.Temperature,
+			TopK:        processedOptions.TopK,
+			TopP:        processedOptions.TopP,
+		},
+	}
+
+	// Otherwise, the server's, or model's default applies.
+	if processedOptions.MaxTokensSet() {
+		reqBody.Options.NumPredict = processedOptions.MaxTokens
+	}
+
+	// Ollama-specific options.
+	extra, ok, err := provider.GetExtra[Extra](processedOptions, Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok {
+		extra.apply(reqBody)
+	}
+
+	// A format set through the extras, e.g.: a JSON schema, wins.
+	if processedOptions.JSONMode && reqBody.Format == nil {
+		reqBody.Format = FormatJSON
+	}
+
+	return reqBody, processedOptions, nil
+}
+
 // GetClient returns the client.
 func (p *Ollama) GetClient() any {
 	return p.client
@@ -156,8 +247,20 @@ func (p *Ollama) GetClient() any {
 // Factory.
 //////
 
-// New creates a new Ollama provider.
-func New(
+// New creates a new Ollama provider, and sets it as the one Get returns.
+func New(options ...provider.ClientFunc) (*Ollama, error) {
+	p, err := newProvider(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	singleton = p
+
+	return p, nil
+}
+
+// newProvider creates a new Ollama provider, without setting the singleton.
+func newProvider(
 	options ...provider.ClientFunc,
 ) (*Ollama, error) {
 	// Enforces IProvider interface implementation.
@@ -168,9 +271,9 @@ func New(
 		return nil, err
 	}
 
-	client, err := httpclient.NewDefault(
-		httpclient.WithClientName(Name),
-	)
+	p.Capabilities = Capabilities()
+
+	client, err := p.NewHTTPClient()
 	if err != nil {
 		return nil, err
 	}
@@ -187,8 +290,6 @@ func New(
 		return nil, err
 	}
 
-	singleton = provider
-
 	return provider, nil
 }
 
@@ -203,10 +304,28 @@ func NewDefault(options ...provider.ClientFunc) (*Ollama, error) {
 	return New(opts...)
 }
 
+// Registers the constructor, for the registry.
+func init() {
+	builtin.Register(Name, func(options ...provider.ClientFunc) (provider.IProvider, error) {
+		p, err := newProvider(options...)
+		if err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	})
+}
+
 //////
 // Exported functionalities.
 //////
 
+// Capabilities returns a copy of the capabilities of the provider, and its
+// models.
+func Capabilities() provider.Capabilities {
+	return capabilities.Clone()
+}
+
 // Get returns a setup MongoDB, or set it up.
 func Get() provider.IProvider {
 	if singleton == nil {
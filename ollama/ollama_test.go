@@ -2,10 +2,13 @@ package ollama
 
 import (
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/cassette/cassettetest"
 	"github.com/thalesfsp/inference/internal/config"
+	"github.com/thalesfsp/inference/message"
 	"github.com/thalesfsp/inference/provider"
 )
 
@@ -92,3 +95,97 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestBuildRequestBody(t *testing.T) {
+	o := &Ollama{Provider: &provider.Provider{DefaultModel: "llama3.2:3b"}}
+
+	reqBody, _, err := o.BuildRequestBody(
+		context.Background(),
+		provider.WithMaxToken(256),
+		provider.WithSeed(42),
+		provider.WithStop("\n\n", "END"),
+		provider.WithUserMessages("why is the sky blue"),
+		WithExtra(Extra{
+			Format:        FormatJSON,
+			KeepAlive:     "10m",
+			NumCtx:        8192,
+			RepeatPenalty: 1.1,
+		}),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "llama3.2:3b", reqBody.Model)
+	assert.Equal(t, FormatJSON, reqBody.Format)
+	assert.Equal(t, "10m", reqBody.KeepAlive)
+	assert.Equal(t, RequestBodyOptions{
+		NumCtx:        8192,
+		NumPredict:    256,
+		RepeatPenalty: 1.1,
+		Seed:          42,
+		Stop:          []string{"\n\n", "END"},
+		Temperature:   0.7,
+	}, reqBody.Options)
+
+	// JSON mode, unless a format is set through the extras.
+	reqBody, _, err = o.BuildRequestBody(
+		context.Background(),
+		provider.WithUserMessages("why is the sky blue"),
+		provider.WithJSONMode(true),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, FormatJSON, reqBody.Format)
+
+	// Without MaxTokens, the server's, or model's default applies.
+	assert.Zero(t, reqBody.Options.NumPredict)
+
+	// All system messages are sent, in order, before user messages.
+	reqBody, _, err = o.BuildRequestBody(
+		context.Background(),
+		provider.WithSystemMessages("be brief", "be kind"),
+		provider.WithUserMessages("why is the sky blue"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{Content: "be brief", Role: message.System},
+		{Content: "be kind", Role: message.System},
+		{Content: "why is the sky blue", Role: message.User},
+	}, reqBody.Messages)
+}
+
+func TestProcessResponse(t *testing.T) {
+	response, err := ProcessResponse(ResponseBody{Message: message.Message{Content: "blue"}})
+	assert.NoError(t, err)
+	assert.Equal(t, "blue", response)
+
+	_, err = ProcessResponse(ResponseBody{})
+	assert.Error(t, err)
+}
+
+func TestCompletion(t *testing.T) {
+	// Replays `testdata/cassettes/completion.json`. Set `CASSETTE_MODE` to
+	// `record` to record it again against the API.
+	//
+	// NOTE: The cassette is synthetic, written by hand, not recorded, thus its
+	// IDs, and response headers aren't the API's. Record it with `make record`.
+	r := cassettetest.ForTest(t, "completion")
+
+	p, err := NewDefault(
+		provider.WithTransport(r),
+	)
+	assert.NoError(t, err)
+
+	var usage provider.Usage
+
+	response, err := p.Completion(
+		context.Background(),
+		provider.WithModel("llama3.2:3b"),
+		provider.WithMaxToken(16),
+		provider.WithSystemMessages("Answer with a single word."),
+		provider.WithUserMessages("What is the color of a clear daytime sky?"),
+		provider.WithUsage(&usage),
+	)
+	assert.NoError(t, err)
+	assert.Contains(t, strings.ToLower(response), "blue")
+	assert.Positive(t, usage.InputTokens)
+	assert.Positive(t, usage.OutputTokens)
+}
@@ -0,0 +1,65 @@
+package ollama
+
+import "github.com/thalesfsp/inference/provider"
+
+//////
+// Const, vars, and types.
+//////
+
+// FormatJSON forces the model to respond with valid JSON.
+const FormatJSON = "json"
+
+// Extra are the Ollama-specific options. Zero values are not sent, so the
+// model defaults apply.
+type Extra struct {
+	// Format of the response, either FormatJSON or a JSON schema.
+	Format any `json:"format,omitempty"`
+
+	// KeepAlive controls how long the model stays loaded into memory after the
+	// request, e.g.: "10m", or "-1m" to keep it loaded forever.
+	KeepAlive string `json:"keepAlive,omitempty"`
+
+	// Mirostat enables Mirostat sampling for controlling perplexity (0 =
+	// disabled, 1 = Mirostat, 2 = Mirostat 2.0).
+	Mirostat int `json:"mirostat,omitempty"`
+
+	// MirostatEta influences how quickly the algorithm responds to feedback
+	// from the generated text.
+	MirostatEta float64 `json:"mirostatEta,omitempty"`
+
+	// MirostatTau controls the balance between coherence and diversity of the
+	// output.
+	MirostatTau float64 `json:"mirostatTau,omitempty"`
+
+	// NumCtx sets the size of the context window used to generate the next
+	// token.
+	NumCtx int `json:"numCtx,omitempty"`
+
+	// RepeatPenalty sets how strongly to penalize repetitions.
+	RepeatPenalty float64 `json:"repeatPenalty,omitempty"`
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithExtra sets the Ollama-specific options. Other providers ignore them.
+func WithExtra(extra Extra) provider.Func {
+	return provider.WithExtra(Name, extra)
+}
+
+//////
+// Helpers.
+//////
+
+// apply merges the options into the request body.
+func (e Extra) apply(reqBody *RequestBody) {
+	reqBody.Format = e.Format
+	reqBody.KeepAlive = e.KeepAlive
+
+	reqBody.Options.Mirostat = e.Mirostat
+	reqBody.Options.MirostatEta = e.MirostatEta
+	reqBody.Options.MirostatTau = e.MirostatTau
+	reqBody.Options.NumCtx = e.NumCtx
+	reqBody.Options.RepeatPenalty = e.RepeatPenalty
+}
@@ -0,0 +1,88 @@
+package ollama
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Helpers.
+//////
+
+// post posts the request body, and decodes the response body.
+func (p *Ollama) post(ctx context.Context, reqBody *RequestBody, respBody *ResponseBody) error {
+	resp, err := p.client.Post(
+		ctx,
+		p.Endpoint,
+		httpclient.WithReqBody(reqBody),
+		httpclient.WithRespBody(respBody),
+	)
+	if err != nil {
+		return err
+	}
+
+	return resp.Body.Close()
+}
+
+// stream posts the request body, calls the handler with each chunk of the
+// streamed response, as newline-delimited JSON, and returns it assembled.
+func (p *Ollama) stream(
+	ctx context.Context,
+	reqBody *RequestBody,
+	handler provider.StreamFunc,
+) (ResponseBody, error) {
+	respBody := ResponseBody{}
+
+	resp, err := provider.PostStream(ctx, p.client, p.Endpoint, nil, reqBody)
+	if err != nil {
+		return respBody, err
+	}
+
+	defer resp.Body.Close()
+
+	var content strings.Builder
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var chunk StreamChunk
+
+		if err := decoder.Decode(&chunk); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return respBody, customerror.NewFailedToError("decode stream chunk", customerror.WithError(err))
+		}
+
+		if chunk.Error != "" {
+			return respBody, customerror.New(chunk.Error)
+		}
+
+		respBody = chunk.ResponseBody
+
+		if chunk.Message.Content != "" {
+			content.WriteString(chunk.Message.Content)
+
+			if err := handler(chunk.Message.Content); err != nil {
+				return respBody, err
+			}
+		}
+
+		if chunk.Done {
+			break
+		}
+	}
+
+	respBody.Message = message.Message{Content: content.String(), Role: message.Assistant}
+
+	return respBody, nil
+}
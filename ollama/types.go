@@ -15,10 +15,17 @@ import (
 
 // RequestBodyOptions represents the options for the request body.
 type RequestBodyOptions struct {
-	Seed        int     `json:"seed,omitempty"`
-	Temperature float64 `json:"temperature,omitempty"`
-	TopK        int     `json:"top_k,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
+	Mirostat      int      `json:"mirostat,omitempty"`
+	MirostatEta   float64  `json:"mirostat_eta,omitempty"`
+	MirostatTau   float64  `json:"mirostat_tau,omitempty"`
+	NumCtx        int      `json:"num_ctx,omitempty"`
+	NumPredict    int      `json:"num_predict,omitempty"`
+	RepeatPenalty float64  `json:"repeat_penalty,omitempty"`
+	Seed          int      `json:"seed,omitempty"`
+	Stop          []string `json:"stop,omitempty"`
+	Temperature   float64  `json:"temperature,omitempty"`
+	TopK          int      `json:"top_k,omitempty"`
+	TopP          float64  `json:"top_p,omitempty"`
 }
 
 // RequestBody represents the request body for the Ollama API.
@@ -27,6 +34,9 @@ type RequestBody struct {
 	Model    string            `json:"model"`
 	Stream   bool              `json:"stream"`
 
+	Format    any    `json:"format,omitempty"`
+	KeepAlive string `json:"keep_alive,omitempty"`
+
 	Options RequestBodyOptions `json:"options,omitempty"`
 }
 
@@ -48,3 +58,115 @@ type ResponseBody struct {
 	PromptEvalDuration int             `json:"prompt_eval_duration"`
 	TotalDuration      int64           `json:"total_duration"`
 }
+
+// StreamChunk is a chunk of the streamed response.
+type StreamChunk struct {
+	ResponseBody
+
+	Error string `json:"error,omitempty"`
+}
+
+//////
+// Model management.
+
+// ModelDetails represents the details of a model.
+type ModelDetails struct {
+	Families          []string `json:"families,omitempty"`
+	Family            string   `json:"family,omitempty"`
+	Format            string   `json:"format,omitempty"`
+	ParameterSize     string   `json:"parameter_size,omitempty"`
+	ParentModel       string   `json:"parent_model,omitempty"`
+	QuantizationLevel string   `json:"quantization_level,omitempty"`
+}
+
+// Model represents a locally available model.
+type Model struct {
+	Details    ModelDetails `json:"details"`
+	Digest     string       `json:"digest"`
+	Model      string       `json:"model"`
+	ModifiedAt time.Time    `json:"modified_at"`
+	Name       string       `json:"name"`
+	Size       int64        `json:"size"`
+}
+
+// ListModelsResponseBody represents the response body of the list models API.
+type ListModelsResponseBody struct {
+	Models []Model `json:"models"`
+}
+
+// RunningModel represents a model currently loaded into memory.
+type RunningModel struct {
+	Details   ModelDetails `json:"details"`
+	Digest    string       `json:"digest"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	Model     string       `json:"model"`
+	Name      string       `json:"name"`
+	Size      int64        `json:"size"`
+	SizeVRAM  int64        `json:"size_vram"`
+}
+
+// ListRunningModelsResponseBody represents the response body of the list
+// running models API.
+type ListRunningModelsResponseBody struct {
+	Models []RunningModel `json:"models"`
+}
+
+// ModelRequestBody represents the request body of the APIs operating on a
+// single model (show, delete).
+type ModelRequestBody struct {
+	Model string `json:"model"`
+}
+
+// PullRequestBody represents the request body of the pull model API.
+type PullRequestBody struct {
+	Insecure bool   `json:"insecure,omitempty"`
+	Model    string `json:"model"`
+	Stream   bool   `json:"stream"`
+}
+
+// PullProgress represents a progress update streamed by the pull model API.
+type PullProgress struct {
+	Completed int64  `json:"completed,omitempty"`
+	Digest    string `json:"digest,omitempty"`
+	Error     string `json:"error,omitempty"`
+	Status    string `json:"status"`
+	Total     int64  `json:"total,omitempty"`
+}
+
+// ModelInfo represents the response body of the show model API.
+type ModelInfo struct {
+	Capabilities []string       `json:"capabilities,omitempty"`
+	Details      ModelDetails   `json:"details"`
+	License      string         `json:"license,omitempty"`
+	ModelInfo    map[string]any `json:"model_info,omitempty"`
+	ModelFile    string         `json:"modelfile,omitempty"`
+	ModifiedAt   time.Time      `json:"modified_at"`
+	Parameters   string         `json:"parameters,omitempty"`
+	Template     string         `json:"template,omitempty"`
+
+	// ContextLength is the maximum context length of the model, extracted from
+	// `model_info`. Zero if unknown.
+	ContextLength int `json:"-"`
+}
+
+// LoadRequestBody represents the request body used to load, or unload a
+// model into, or from memory.
+type LoadRequestBody struct {
+	KeepAlive string            `json:"keep_alive,omitempty"`
+	Messages  []message.Message `json:"messages"`
+	Model     string            `json:"model"`
+}
+
+// EmbedRequestBody represents the request body of the embed API.
+type EmbedRequestBody struct {
+	Input     []string `json:"input"`
+	KeepAlive string   `json:"keep_alive,omitempty"`
+	Model     string   `json:"model"`
+}
+
+// EmbedResponseBody represents the response body of the embed API.
+type EmbedResponseBody struct {
+	Embeddings      [][]float64 `json:"embeddings"`
+	Model           string      `json:"model"`
+	PromptEvalCount int         `json:"prompt_eval_count,omitempty"`
+}
@@ -1,16 +1,72 @@
 package ollama
 
 import (
+	"net/url"
 	"strings"
 
 	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
 )
 
 // ProcessResponse processes the response from the API.
 func ProcessResponse(response ResponseBody) (string, error) {
-	if len(strings.TrimSpace(response.Message.Content)) != 0 {
+	if len(strings.TrimSpace(response.Message.Content)) == 0 {
 		return "", customerror.NewMissingError("content")
 	}
 
 	return response.Message.Content, nil
 }
+
+// ToUsage converts the API usage to the provider usage.
+func (r ResponseBody) ToUsage() provider.Usage {
+	return provider.Usage{
+		InputTokens:  r.PromptEvalCount,
+		OutputTokens: r.EvalCount,
+	}
+}
+
+// ContextLengthFromModelInfo extracts the context length from the `model_info`
+// returned by the show model API. The key is prefixed by the model
+// architecture, e.g.: `llama.context_length`.
+func ContextLengthFromModelInfo(modelInfo map[string]any) int {
+	for k, v := range modelInfo {
+		if !strings.HasSuffix(k, ".context_length") {
+			continue
+		}
+
+		if f, ok := v.(float64); ok {
+			return int(f)
+		}
+	}
+
+	return 0
+}
+
+// NormalizeModelName adds the default `latest` tag to the model name if it
+// has none, matching how Ollama names models.
+func NormalizeModelName(model string) string {
+	if model == "" || strings.Contains(model, ":") {
+		return model
+	}
+
+	return model + ":latest"
+}
+
+// apiURL builds the URL of an Ollama API resource based on the configured
+// (chat) endpoint, e.g.: `http://localhost:11434/api/chat` -> `.../api/tags`.
+func apiURL(endpoint, resource string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", customerror.NewInvalidError("endpoint", customerror.WithError(err))
+	}
+
+	base := strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/chat")
+
+	if !strings.HasSuffix(base, "/api") {
+		base += "/api"
+	}
+
+	u.Path = base + "/" + resource
+
+	return u.String(), nil
+}
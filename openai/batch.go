@@ -0,0 +1,360 @@
+package openai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/batch"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// completionWindow is the time frame within which batches are processed.
+const completionWindow = "24h"
+
+// Enforces the batch.IBatcher interface implementation.
+var _ batch.IBatcher = (*OpenAI)(nil)
+
+// batchStatuses maps the API batch statuses.
+var batchStatuses = map[string]batch.Status{
+	"cancelled":   batch.StatusCanceled,
+	"cancelling":  batch.StatusInProgress,
+	"completed":   batch.StatusCompleted,
+	"expired":     batch.StatusExpired,
+	"failed":      batch.StatusFailed,
+	"finalizing":  batch.StatusInProgress,
+	"in_progress": batch.StatusInProgress,
+	"validating":  batch.StatusInProgress,
+}
+
+//////
+// Implements the batch.IBatcher interface.
+//////
+
+// SubmitBatch builds the requests as the batch input file, uploads it, and
+// creates the batch, processed through the chat completions API.
+func (p *OpenAI) SubmitBatch(ctx context.Context, requests []batch.Request) (*batch.Job, error) {
+	if err := batch.Validate(requests); err != nil {
+		return nil, err
+	}
+
+	endpoint, err := url.Parse(p.Endpoint)
+	if err != nil {
+		return nil, customerror.NewInvalidError("endpoint", customerror.WithError(err))
+	}
+
+	var input bytes.Buffer
+
+	encoder := json.NewEncoder(&input)
+
+	for _, r := range requests {
+		reqBody, _, err := p.BuildRequestBody(ctx, r.Options...)
+		if err != nil {
+			return nil, customerror.NewInvalidError(fmt.Sprintf("request %s", r.ID), customerror.WithError(err))
+		}
+
+		if reqBody.Stream {
+			return nil, customerror.NewInvalidError(fmt.Sprintf("request %s, streaming isn't supported in batches", r.ID))
+		}
+
+		if err := encoder.Encode(BatchLine{
+			Body:     reqBody,
+			CustomID: r.ID,
+			Method:   http.MethodPost,
+			URL:      endpoint.Path,
+		}); err != nil {
+			return nil, customerror.NewFailedToError("encode batch input", customerror.WithError(err))
+		}
+	}
+
+	file, err := p.uploadBatchInput(ctx, input.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	u, err := apiURL(p.Endpoint, "batches")
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody Batch
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithBearerAuthToken(p.Token),
+		httpclient.WithReqBody(BatchRequestBody{
+			CompletionWindow: completionWindow,
+			Endpoint:         endpoint.Path,
+			InputFileID:      file.ID,
+		}),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return respBody.toJob(), nil
+}
+
+// GetBatch returns the batch.
+func (p *OpenAI) GetBatch(ctx context.Context, id string) (*batch.Job, error) {
+	b, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return b.toJob(), nil
+}
+
+// CancelBatch cancels the batch. Requests processed before have results.
+func (p *OpenAI) CancelBatch(ctx context.Context, id string) (*batch.Job, error) {
+	if id == "" {
+		return nil, customerror.NewRequiredError("id")
+	}
+
+	u, err := apiURL(p.Endpoint, "batches", id, "cancel")
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody Batch
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithBearerAuthToken(p.Token),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return respBody.toJob(), nil
+}
+
+// BatchResults downloads the output, and error files of the batch, and
+// returns the result of each processed request. Costs are discounted.
+func (p *OpenAI) BatchResults(ctx context.Context, id string) ([]batch.Result, error) {
+	b, err := p.getBatch(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !b.status().Done() {
+		return nil, customerror.NewInvalidError(fmt.Sprintf("batch %s, not ended, status %s", id, b.Status))
+	}
+
+	results := []batch.Result{}
+
+	for _, fileID := range []string{b.OutputFileID, b.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+
+		fileResults, err := p.downloadBatchResults(ctx, fileID)
+		if err != nil {
+			return nil, err
+		}
+
+		results = append(results, fileResults...)
+	}
+
+	return results, nil
+}
+
+//////
+// Helpers.
+//////
+
+// getBatch returns the API batch.
+func (p *OpenAI) getBatch(ctx context.Context, id string) (*Batch, error) {
+	if id == "" {
+		return nil, customerror.NewRequiredError("id")
+	}
+
+	u, err := apiURL(p.Endpoint, "batches", id)
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody Batch
+
+	resp, err := p.client.Get(
+		ctx,
+		u,
+		httpclient.WithBearerAuthToken(p.Token),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return &respBody, nil
+}
+
+// uploadBatchInput uploads the batch input file.
+func (p *OpenAI) uploadBatchInput(ctx context.Context, input []byte) (*File, error) {
+	u, err := apiURL(p.Endpoint, "files")
+	if err != nil {
+		return nil, err
+	}
+
+	var form bytes.Buffer
+
+	w := multipart.NewWriter(&form)
+
+	if err := w.WriteField("purpose", "batch"); err != nil {
+		return nil, customerror.NewFailedToError("write batch input form", customerror.WithError(err))
+	}
+
+	part, err := w.CreateFormFile("file", "batch.jsonl")
+	if err != nil {
+		return nil, customerror.NewFailedToError("write batch input form", customerror.WithError(err))
+	}
+
+	if _, err := part.Write(input); err != nil {
+		return nil, customerror.NewFailedToError("write batch input form", customerror.WithError(err))
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, customerror.NewFailedToError("write batch input form", customerror.WithError(err))
+	}
+
+	var respBody File
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithBearerAuthToken(p.Token),
+		httpclient.WithHeader("Content-Type", w.FormDataContentType()),
+		httpclient.WithReqBody(&form),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	return &respBody, nil
+}
+
+// downloadBatchResults downloads, and parses the output, or error file.
+func (p *OpenAI) downloadBatchResults(ctx context.Context, fileID string) ([]batch.Result, error) {
+	u, err := apiURL(p.Endpoint, "files", fileID, "content")
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Get(ctx, u, httpclient.WithBearerAuthToken(p.Token))
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	results := []batch.Result{}
+
+	decoder := json.NewDecoder(resp.Body)
+
+	for {
+		var line BatchResultLine
+
+		if err := decoder.Decode(&line); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			return nil, customerror.NewFailedToError("decode batch results", customerror.WithError(err))
+		}
+
+		results = append(results, line.toResult())
+	}
+
+	return results, nil
+}
+
+// status returns the status of the batch. Unknown ones are in progress.
+func (b Batch) status() batch.Status {
+	status, ok := batchStatuses[b.Status]
+	if !ok {
+		return batch.StatusInProgress
+	}
+
+	return status
+}
+
+// toJob converts the API batch to a job.
+func (b Batch) toJob() *batch.Job {
+	return &batch.Job{
+		CreatedAt: time.Unix(b.CreatedAt, 0),
+		Counts: batch.Counts{
+			Failed:    b.RequestCounts.Failed,
+			Succeeded: b.RequestCounts.Completed,
+			Total:     b.RequestCounts.Total,
+		},
+		ID:       b.ID,
+		Provider: Name,
+		Status:   b.status(),
+	}
+}
+
+// toResult converts the result line to a result.
+func (l BatchResultLine) toResult() batch.Result {
+	result := batch.Result{ID: l.CustomID}
+
+	switch {
+	case l.Error != nil:
+		result.Error = l.Error.Message
+	case l.Response == nil:
+		result.Error = "no response"
+	case l.Response.StatusCode < http.StatusOK || l.Response.StatusCode >= http.StatusMultipleChoices:
+		result.Error = fmt.Sprintf("status code %d", l.Response.StatusCode)
+	default:
+		response, err := ProcessResponse(l.Response.Body)
+		if err != nil {
+			result.Error = err.Error()
+		}
+
+		result.Response = response
+		result.Usage = l.Response.Body.Usage.ToUsage()
+		result.Usage.Cost = provider.UsageCost(Name, l.Response.Body.Model, result.Usage) * batch.Discount
+	}
+
+	return result
+}
+
+// apiURL derives an API URL from the chat completions one, e.g.:
+// `https://api.openai.com/v1/chat/completions`, and `batches` ->
+// `https://api.openai.com/v1/batches`.
+func apiURL(endpoint string, elem ...string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", customerror.NewInvalidError("endpoint", customerror.WithError(err))
+	}
+
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/chat/completions")
+
+	return u.JoinPath(elem...).String(), nil
+}
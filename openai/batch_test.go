@@ -0,0 +1,155 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/batch"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestBatch(t *testing.T) {
+	var polls atomic.Int32
+
+	var input []BatchLine
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/files", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer test", r.Header.Get("Authorization"))
+		assert.Equal(t, "batch", r.FormValue("purpose"))
+
+		file, _, err := r.FormFile("file")
+		assert.NoError(t, err)
+
+		decoder := json.NewDecoder(file)
+
+		for decoder.More() {
+			var line BatchLine
+
+			assert.NoError(t, decoder.Decode(&line))
+
+			input = append(input, line)
+		}
+
+		_, _ = w.Write([]byte(`{"id": "file-in"}`))
+	})
+
+	mux.HandleFunc("POST /v1/batches", func(w http.ResponseWriter, r *http.Request) {
+		var reqBody BatchRequestBody
+
+		_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+		assert.Equal(t, BatchRequestBody{
+			CompletionWindow: "24h",
+			Endpoint:         "/v1/chat/completions",
+			InputFileID:      "file-in",
+		}, reqBody)
+
+		_, _ = w.Write([]byte(`{"id": "batch_1", "status": "validating", "created_at": 1700000000}`))
+	})
+
+	mux.HandleFunc("GET /v1/batches/batch_1", func(w http.ResponseWriter, _ *http.Request) {
+		if polls.Add(1) < 3 {
+			_, _ = w.Write([]byte(`{"id": "batch_1", "status": "in_progress", "request_counts": {"total": 3}}`))
+
+			return
+		}
+
+		_, _ = w.Write([]byte(`{
+			"id": "batch_1",
+			"status": "completed",
+			"output_file_id": "file-out",
+			"error_file_id": "file-err",
+			"request_counts": {"completed": 1, "failed": 1, "total": 3}
+		}`))
+	})
+
+	mux.HandleFunc("GET /v1/files/file-out/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"id": "r1", "custom_id": "sky", "response": {"status_code": 200, "body": {`+
+			`"model": "gpt-4o-mini", "choices": [{"message": {"role": "assistant", "content": "Blue"}}], `+
+			`"usage": {"prompt_tokens": 1000000, "completion_tokens": 1000000}}}, "error": null}`)
+	})
+
+	mux.HandleFunc("GET /v1/files/file-err/content", func(w http.ResponseWriter, _ *http.Request) {
+		fmt.Fprintln(w, `{"id": "r2", "custom_id": "sea", "response": {"status_code": 400, "body": {}}, "error": null}`)
+	})
+
+	mux.HandleFunc("POST /v1/batches/batch_1/cancel", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"id": "batch_1", "status": "cancelling"}`))
+	})
+
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	p, err := New(
+		provider.WithEndpoint(server.URL+"/v1/chat/completions"),
+		provider.WithToken("test"),
+		provider.WithDefaulModel("gpt-4o-mini"),
+	)
+	assert.NoError(t, err)
+
+	requests := []batch.Request{
+		{ID: "sky", Options: []provider.Func{provider.WithUserMessages("What's the color of the sky?")}},
+		{ID: "sea", Options: []provider.Func{provider.WithUserMessages("Why is the sea salty?")}},
+		{ID: "moon", Options: []provider.Func{provider.WithUserMessages("How far is the moon?")}},
+	}
+
+	results, err := batch.Run(context.Background(), p, requests, batch.WithPollInterval(time.Millisecond))
+	assert.NoError(t, err)
+
+	assert.Len(t, input, 3)
+	assert.Equal(t, "sky", input[0].CustomID)
+	assert.Equal(t, http.MethodPost, input[0].Method)
+	assert.Equal(t, "/v1/chat/completions", input[0].URL)
+	assert.Equal(t, "gpt-4o-mini", input[0].Body.Model)
+
+	assert.Equal(t, "sky", results[0].ID)
+	assert.Equal(t, "Blue", results[0].Response)
+	assert.Equal(t, 1000000, results[0].Usage.InputTokens)
+
+	// Half of 0.15, and 0.6 per million.
+	assert.InDelta(t, 0.375, results[0].Usage.Cost, 1e-9)
+
+	assert.Equal(t, batch.Result{Error: "status code 400", ID: "sea"}, results[1])
+	assert.Equal(t, batch.Result{Error: "no result", ID: "moon"}, results[2])
+
+	job, err := p.CancelBatch(context.Background(), "batch_1")
+	assert.NoError(t, err)
+	assert.Equal(t, batch.StatusInProgress, job.Status)
+
+	// Errors.
+	_, err = p.SubmitBatch(context.Background(), []batch.Request{{ID: "a"}, {ID: "a"}})
+	assert.Error(t, err)
+
+	_, err = p.SubmitBatch(context.Background(), []batch.Request{{
+		ID:      "stream",
+		Options: []provider.Func{provider.WithStreamHandler(func(string) error { return nil })},
+	}})
+	assert.Error(t, err)
+}
+
+func TestBatch_status(t *testing.T) {
+	assert.Equal(t, batch.StatusCompleted, Batch{Status: "completed"}.status())
+	assert.Equal(t, batch.StatusInProgress, Batch{Status: "finalizing"}.status())
+
+	// Unknown statuses aren't done, the same for jobs, and results.
+	assert.Equal(t, batch.StatusInProgress, Batch{Status: "paused"}.status())
+	assert.Equal(t, batch.StatusInProgress, Batch{Status: "paused"}.toJob().Status)
+}
+
+func TestAPIURL(t *testing.T) {
+	u, err := apiURL("https://api.openai.com/v1/chat/completions/", "files", "file-1", "content")
+	assert.NoError(t, err)
+	assert.Equal(t, "https://api.openai.com/v1/files/file-1/content", u)
+
+	_, err = apiURL(":", "batches")
+	assert.Error(t, err)
+}
@@ -0,0 +1,80 @@
+package openai
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+)
+
+//////
+// Embeddings.
+//////
+
+// Embed returns the embedding of each input, in order, using the embedding
+// model, e.g.: `text-embedding-3-small`.
+func (p *OpenAI) Embed(ctx context.Context, model string, input ...string) ([][]float64, error) {
+	if model == "" {
+		return nil, customerror.NewRequiredError("model")
+	}
+
+	if len(input) == 0 {
+		return nil, customerror.NewRequiredError("input")
+	}
+
+	u, err := embeddingsURL(p.Endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	var respBody EmbeddingsResponseBody
+
+	resp, err := p.client.Post(
+		ctx,
+		u,
+		httpclient.WithBearerAuthToken(p.Token),
+		httpclient.WithReqBody(EmbeddingsRequestBody{Input: input, Model: model}),
+		httpclient.WithRespBody(&respBody),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	defer resp.Body.Close()
+
+	if len(respBody.Data) != len(input) {
+		return nil, customerror.NewInvalidError("embeddings response, embeddings don't match the input")
+	}
+
+	embeddings := make([][]float64, len(input))
+
+	for _, e := range respBody.Data {
+		if e.Index < 0 || e.Index >= len(embeddings) {
+			return nil, customerror.NewInvalidError("embeddings response, index out of range")
+		}
+
+		embeddings[e.Index] = e.Embedding
+	}
+
+	return embeddings, nil
+}
+
+//////
+// Helpers.
+//////
+
+// embeddingsURL derives the embeddings API URL from the chat completions one,
+// e.g.: `https://api.openai.com/v1/chat/completions` ->
+// `https://api.openai.com/v1/embeddings`.
+func embeddingsURL(endpoint string) (string, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return "", customerror.NewInvalidError("endpoint", customerror.WithError(err))
+	}
+
+	u.Path = strings.TrimSuffix(strings.TrimSuffix(u.Path, "/"), "/chat/completions") + "/embeddings"
+
+	return u.String(), nil
+}
@@ -0,0 +1,44 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestEmbed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/embeddings", r.URL.Path)
+		assert.Equal(t, "Bearer token", r.Header.Get("Authorization"))
+
+		var reqBody EmbeddingsRequestBody
+
+		_ = json.NewDecoder(r.Body).Decode(&reqBody)
+
+		assert.Equal(t, "text-embedding-3-small", reqBody.Model)
+
+		// Out of order on purpose.
+		_ = json.NewEncoder(w).Encode(EmbeddingsResponseBody{
+			Data: []Embedding{
+				{Embedding: []float64{0, 1}, Index: 1},
+				{Embedding: []float64{1, 0}, Index: 0},
+			},
+		})
+	}))
+	defer server.Close()
+
+	o, err := New(
+		provider.WithEndpoint(server.URL+"/v1/chat/completions"),
+		provider.WithToken("token"),
+	)
+	assert.NoError(t, err)
+
+	embeddings, err := o.Embed(context.Background(), "text-embedding-3-small", "a", "b")
+	assert.NoError(t, err)
+	assert.Equal(t, [][]float64{{1, 0}, {0, 1}}, embeddings)
+}
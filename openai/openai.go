@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/thalesfsp/httpclient/v2"
+	"github.com/thalesfsp/inference/internal/builtin"
 	"github.com/thalesfsp/inference/internal/config"
 	"github.com/thalesfsp/inference/message"
 	"github.com/thalesfsp/inference/provider"
@@ -26,6 +27,104 @@ const Name = "openai"
 // Singleton.
 var singleton provider.IProvider
 
+// capabilities of the provider, and its models. Models are matched by
+// prefix, thus newer families, e.g.: `gpt-4.1`, need their own entry not to
+// fall back to an older one, e.g.: `gpt-4`, and one in the catalog, as strict
+// mode requires.
+var capabilities = provider.Capabilities{
+	JSONMode:   true,
+	MaxContext: 128000,
+	Seed:       true,
+	Streaming:  true,
+	Tools:      true,
+	Vision:     true,
+
+	Models: map[string]provider.Capabilities{
+		"gpt-3.5-turbo": {
+			JSONMode:   true,
+			MaxContext: 16385,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+		},
+		"gpt-4": {
+			MaxContext: 8192,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+		},
+		"gpt-4-turbo": {
+			JSONMode:   true,
+			MaxContext: 128000,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+		"gpt-4.1": {
+			JSONMode:   true,
+			MaxContext: 1047576,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+		"gpt-4.5": {
+			JSONMode:   true,
+			MaxContext: 128000,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+		"gpt-4o": {
+			JSONMode:   true,
+			MaxContext: 128000,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+		"gpt-5": {
+			JSONMode:   true,
+			MaxContext: 400000,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+		"o1": {
+			JSONMode:   true,
+			MaxContext: 200000,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+		"o1-mini": {
+			MaxContext: 128000,
+			Seed:       true,
+			Streaming:  true,
+		},
+		"o3": {
+			JSONMode:   true,
+			MaxContext: 200000,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+		"o4-mini": {
+			JSONMode:   true,
+			MaxContext: 200000,
+			Seed:       true,
+			Streaming:  true,
+			Tools:      true,
+			Vision:     true,
+		},
+	},
+}
+
 // OpenAI provider definition.
 type OpenAI struct {
 	*provider.Provider
@@ -50,46 +149,41 @@ type OpenAI struct {
 // NOTE: Not all options are available for all providers.
 func (p *OpenAI) Completion(ctx context.Context, options ...provider.Func) (string, error) {
 	//////
-	// Options initialization.
+	// Tracing.
 	//////
 
-	// Prepend the default model to the options.
-	options = append(
-		[]provider.Func{
-			provider.WithModel(p.DefaultModel),
-		},
-		options...,
-	)
-
-	processedOptions, err := provider.NewOptionsFrom(options...)
-	if err != nil {
-		return "", err
-	}
+	// Started first, so invalid requests are traced, and measured too.
+	ctx, span := p.StartSpan(ctx)
+	defer span.End()
 
 	//////
-	// Messages processing.
+	// Request.
 	//////
 
-	finalMessages := message.NewMessages(
-		processedOptions.SystemMessages,
-		processedOptions.UserMessages,
-	)
+	reqBody, processedOptions, err := p.BuildRequestBody(ctx, options...)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	span.SetRequest(processedOptions)
 
 	//////
-	// Request body formation.
+	// Budget, and usage accounting.
 	//////
 
-	reqBody := &RequestBody{
-		Messages: finalMessages,
-		Model:    processedOptions.Model,
-		Stream:   processedOptions.Stream,
+	settle, err := p.Reserve(ctx, processedOptions)
+	if err != nil {
+		p.GetCounterCompletionFailed().Add(1)
 
-		MaxTokens:   processedOptions.MaxTokens,
-		Seed:        processedOptions.Seed,
-		Temperature: processedOptions.Temperature,
-		TopP:        processedOptions.TopP,
+		return "", span.Fail(err)
 	}
 
+	var usage provider.Usage
+
+	defer func() { settle(usage) }()
+
 	//////
 	// Call LLM provider.
 	//////
@@ -99,27 +193,30 @@ func (p *OpenAI) Completion(ctx context.Context, options ...provider.Func) (stri
 	// Track performance.
 	now := time.Now()
 
-	resp, err := p.client.Post(
-		ctx,
-		p.Endpoint,
-		httpclient.WithBearerAuthToken(p.Token),
-		httpclient.WithReqBody(reqBody),
-		httpclient.WithRespBody(&respBody),
-	)
+	if processedOptions.StreamHandler != nil {
+		respBody, err = p.stream(ctx, reqBody, processedOptions.StreamHandler)
+	} else {
+		err = p.post(ctx, reqBody, &respBody)
+	}
+
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
 	}
 
-	defer resp.Body.Close()
+	usage = respBody.Usage.ToUsage()
+	usage.FinishReason = respBody.FinishReason()
+
+	span.SetResponse(respBody.ID, respBody.Model, respBody.FinishReasons()...)
+	span.SetUsage(usage)
 
 	// Response processing.
 	response, err := ProcessResponse(respBody)
 	if err != nil {
 		p.GetCounterCompletionFailed().Add(1)
 
-		return "", err
+		return "", span.Fail(err)
 	}
 
 	// Optional response body processing.
@@ -128,7 +225,7 @@ func (p *OpenAI) Completion(ctx context.Context, options ...provider.Func) (stri
 			[]byte(response),
 			processedOptions.ResponseBody,
 		); err != nil {
-			return "", err
+			return "", span.Fail(err)
 		}
 	}
 
@@ -141,6 +238,8 @@ func (p *OpenAI) Completion(ctx context.Context, options ...provider.Func) (stri
 		level.Debug,
 		fmt.Sprintf("Completion %s", status.Created.String()),
 		sypl.WithField("duration", time.Since(now)),
+		sypl.WithField("inputTokens", usage.InputTokens),
+		sypl.WithField("outputTokens", usage.OutputTokens),
 	)
 
 	// Metrics.
@@ -149,6 +248,83 @@ func (p *OpenAI) Completion(ctx context.Context, options ...provider.Func) (stri
 	return response, nil
 }
 
+// BuildRequestBody processes the options, and builds the request body sent to
+// the provider API.
+func (p *OpenAI) BuildRequestBody(ctx context.Context, options ...provider.Func) (*RequestBody, *provider.Options, error) {
+	//////
+	// Options initialization.
+	//////
+
+	// Prepend the provider, its default model, and capabilities to the
+	// options.
+	options = append(
+		[]provider.Func{
+			provider.WithProvider(p.GetName()),
+			provider.WithModel(p.DefaultModel),
+			provider.WithCapabilities(p.GetCapabilities()),
+		},
+		options...,
+	)
+
+	processedOptions, err := provider.NewOptionsFrom(options...)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Context window.
+	if err := provider.Truncate(ctx, processedOptions); err != nil {
+		return nil, nil, err
+	}
+
+	//////
+	// Messages processing.
+	//////
+
+	finalMessages := message.NewConversation(
+		processedOptions.SystemMessages,
+		processedOptions.History,
+		processedOptions.UserMessages,
+	)
+
+	//////
+	// Request body formation.
+	//////
+
+	reqBody := &RequestBody{
+		Messages: finalMessages,
+		Model:    processedOptions.Model,
+		Stream:   processedOptions.Stream,
+
+		MaxTokens:   processedOptions.MaxTokens,
+		Seed:        processedOptions.Seed,
+		Stop:        processedOptions.Stop,
+		Temperature: processedOptions.Temperature,
+		TopP:        processedOptions.TopP,
+	}
+
+	// Usage is only sent, in the last chunk, if asked.
+	if processedOptions.Stream {
+		reqBody.StreamOptions = &StreamOptions{IncludeUsage: true}
+	}
+
+	// Ignored by the models which can't, or rejected in strict mode.
+	if processedOptions.JSONMode && p.GetCapabilities().For(processedOptions.Model).JSONMode {
+		reqBody.ResponseFormat = &ResponseFormat{Type: ResponseFormatJSONObject}
+	}
+
+	// OpenAI-specific options.
+	extra, ok, err := provider.GetExtra[Extra](processedOptions, Name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok {
+		extra.apply(reqBody)
+	}
+
+	return reqBody, processedOptions, nil
+}
+
 // GetClient returns the client.
 func (p *OpenAI) GetClient() any {
 	return p.client
@@ -158,8 +334,20 @@ func (p *OpenAI) GetClient() any {
 // Factory.
 //////
 
-// New creates a new OpenAI provider.
-func New(
+// New creates a new OpenAI provider, and sets it as the one Get returns.
+func New(options ...provider.ClientFunc) (*OpenAI, error) {
+	p, err := newProvider(options...)
+	if err != nil {
+		return nil, err
+	}
+
+	singleton = p
+
+	return p, nil
+}
+
+// newProvider creates a new OpenAI provider, without setting the singleton.
+func newProvider(
 	options ...provider.ClientFunc,
 ) (*OpenAI, error) {
 	// Enforces IProvider interface implementation.
@@ -170,9 +358,9 @@ func New(
 		return nil, err
 	}
 
-	client, err := httpclient.NewDefault(
-		httpclient.WithClientName(Name),
-	)
+	p.Capabilities = Capabilities()
+
+	client, err := p.NewHTTPClient()
 	if err != nil {
 		return nil, err
 	}
@@ -190,8 +378,6 @@ func New(
 		return nil, err
 	}
 
-	singleton = provider
-
 	return provider, nil
 }
 
@@ -207,10 +393,28 @@ func NewDefault(options ...provider.ClientFunc) (*OpenAI, error) {
 	return New(opts...)
 }
 
+// Registers the constructor, for the registry.
+func init() {
+	builtin.Register(Name, func(options ...provider.ClientFunc) (provider.IProvider, error) {
+		p, err := newProvider(options...)
+		if err != nil {
+			return nil, err
+		}
+
+		return p, nil
+	})
+}
+
 //////
 // Exported functionalities.
 //////
 
+// Capabilities returns a copy of the capabilities of the provider, and its
+// models.
+func Capabilities() provider.Capabilities {
+	return capabilities.Clone()
+}
+
 // Get returns a setup MongoDB, or set it up.
 func Get() provider.IProvider {
 	if singleton == nil {
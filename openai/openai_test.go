@@ -1,12 +1,20 @@
 package openai
 
 import (
+	"cmp"
 	"context"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/cassette/cassettetest"
+	"github.com/thalesfsp/inference/internal/builtin"
 	"github.com/thalesfsp/inference/internal/config"
+	"github.com/thalesfsp/inference/message"
 	"github.com/thalesfsp/inference/provider"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestNew(t *testing.T) {
@@ -91,3 +99,188 @@ func TestNew(t *testing.T) {
 		})
 	}
 }
+
+func TestGet(t *testing.T) {
+	t.Cleanup(func() { Set(nil) })
+
+	p, err := New(provider.WithEndpoint("http://localhost"), provider.WithToken("test"))
+	assert.NoError(t, err)
+	assert.Same(t, p, Get())
+
+	// Not through the registry's constructor.
+	constructor, ok := builtin.Get(Name)
+	assert.True(t, ok)
+
+	_, err = constructor(provider.WithEndpoint("http://localhost"), provider.WithToken("test"))
+	assert.NoError(t, err)
+	assert.Same(t, p, Get())
+}
+
+func TestCapabilities(t *testing.T) {
+	tests := []struct {
+		model      string
+		jsonMode   bool
+		maxContext int
+	}{
+		{model: "gpt-4", maxContext: 8192},
+		{model: "gpt-4-0613", maxContext: 8192},
+		{model: "gpt-4-turbo", jsonMode: true, maxContext: 128000},
+		{model: "gpt-4.1-mini", jsonMode: true, maxContext: 1047576},
+		{model: "gpt-4.5-preview", jsonMode: true, maxContext: 128000},
+		{model: "gpt-4o-mini", jsonMode: true, maxContext: 128000},
+		{model: "gpt-5-nano", jsonMode: true, maxContext: 400000},
+		{model: "o1-mini", maxContext: 128000},
+		{model: "o3-mini", jsonMode: true, maxContext: 200000},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			c := Capabilities().For(tt.model)
+
+			assert.Equal(t, tt.jsonMode, c.JSONMode)
+			assert.Equal(t, tt.maxContext, c.MaxContext)
+
+			// Known to the catalog too, as strict mode requires.
+			_, err := provider.NewOptionsFrom(
+				provider.WithProvider(Name),
+				provider.WithModel(tt.model),
+				provider.WithStrict(true),
+				provider.WithUserMessages("hi"),
+			)
+			assert.NoError(t, err)
+		})
+	}
+
+	for model := range Capabilities().Models {
+		_, err := provider.NewOptionsFrom(
+			provider.WithProvider(Name),
+			provider.WithModel(model),
+			provider.WithStrict(true),
+			provider.WithUserMessages("hi"),
+		)
+		assert.NoError(t, err, model)
+	}
+}
+
+func TestBuildRequestBody(t *testing.T) {
+	o := &OpenAI{Provider: &provider.Provider{DefaultModel: "gpt-4o"}}
+
+	reqBody, _, err := o.BuildRequestBody(
+		context.Background(),
+		provider.WithUserMessages("why is the sky blue"),
+		WithExtra(Extra{
+			FrequencyPenalty: 0.5,
+			LogitBias:        map[string]int{"50256": -100},
+			PresencePenalty:  -0.5,
+			User:             "user-1234",
+		}),
+		// Ignored, not meant for OpenAI.
+		provider.WithExtra("anthropic", struct{}{}),
+	)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "gpt-4o", reqBody.Model)
+	assert.Equal(t, 0.5, reqBody.FrequencyPenalty)
+	assert.Equal(t, map[string]int{"50256": -100}, reqBody.LogitBias)
+	assert.Equal(t, -0.5, reqBody.PresencePenalty)
+	assert.Equal(t, "user-1234", reqBody.User)
+
+	_, _, err = o.BuildRequestBody(
+		context.Background(),
+		provider.WithUserMessages("why is the sky blue"),
+		provider.WithExtra(Name, "not openai extra"),
+	)
+	assert.Error(t, err)
+
+	// JSON mode, ignored by the models which can't.
+	o.Capabilities = Capabilities()
+
+	reqBody, _, err = o.BuildRequestBody(
+		context.Background(),
+		provider.WithUserMessages("why is the sky blue"),
+		provider.WithJSONMode(true),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, &ResponseFormat{Type: ResponseFormatJSONObject}, reqBody.ResponseFormat)
+
+	reqBody, _, err = o.BuildRequestBody(
+		context.Background(),
+		provider.WithModel("gpt-4"),
+		provider.WithUserMessages("why is the sky blue"),
+		provider.WithJSONMode(true),
+	)
+	assert.NoError(t, err)
+	assert.Nil(t, reqBody.ResponseFormat)
+
+	// All system messages are sent, in order, then history, and user
+	// messages.
+	reqBody, _, err = o.BuildRequestBody(
+		context.Background(),
+		provider.WithSystemMessages("be brief", "be kind"),
+		provider.WithHistory(
+			message.Message{Content: "hi", Role: message.User},
+			message.Message{Content: "hello", Role: message.Assistant},
+		),
+		provider.WithUserMessages("why is the sky blue"),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []message.Message{
+		{Content: "be brief", Role: message.System},
+		{Content: "be kind", Role: message.System},
+		{Content: "hi", Role: message.User},
+		{Content: "hello", Role: message.Assistant},
+		{Content: "why is the sky blue", Role: message.User},
+	}, reqBody.Messages)
+}
+
+func TestCompletion_invalid(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	p, err := New(
+		provider.WithEndpoint("http://localhost"),
+		provider.WithInstance("invalid"),
+		provider.WithToken("test"),
+		provider.WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+	)
+	assert.NoError(t, err)
+
+	// Invalid requests are traced, and measured too.
+	_, err = p.Completion(context.Background(), provider.WithUserMessages("hi"), provider.WithTemperature(-1))
+	assert.Error(t, err)
+
+	assert.Equal(t, int64(1), p.GetCounterCompletionFailed().Value())
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+}
+
+func TestCompletion(t *testing.T) {
+	// Replays `testdata/cassettes/completion.json`. Set `CASSETTE_MODE` to
+	// `record` to record it again against the API.
+	//
+	// NOTE: The cassette is synthetic, written by hand, not recorded, thus its
+	// IDs, and response headers aren't the API's. Record it with `make record`.
+	r := cassettetest.ForTest(t, "completion")
+
+	p, err := NewDefault(
+		provider.WithToken(cmp.Or(config.Get().OpenAIToken, "test")),
+		provider.WithTransport(r),
+	)
+	assert.NoError(t, err)
+
+	var usage provider.Usage
+
+	response, err := p.Completion(
+		context.Background(),
+		provider.WithModel("gpt-4o-mini"),
+		provider.WithMaxToken(16),
+		provider.WithSystemMessages("Answer with a single word."),
+		provider.WithUserMessages("What is the color of a clear daytime sky?"),
+		provider.WithUsage(&usage),
+	)
+	assert.NoError(t, err)
+	assert.Contains(t, strings.ToLower(response), "blue")
+	assert.Positive(t, usage.InputTokens)
+	assert.Positive(t, usage.OutputTokens)
+}
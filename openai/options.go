@@ -0,0 +1,48 @@
+package openai
+
+import "github.com/thalesfsp/inference/provider"
+
+//////
+// Const, vars, and types.
+//////
+
+// Extra are the OpenAI-specific options. Zero values are not sent, so the
+// API defaults apply.
+type Extra struct {
+	// FrequencyPenalty between -2.0 and 2.0. Positive values penalize new
+	// tokens based on their existing frequency in the text so far.
+	FrequencyPenalty float64 `json:"frequencyPenalty,omitempty"`
+
+	// LogitBias modifies the likelihood of specified tokens appearing in the
+	// completion. Maps token IDs to a bias value from -100 to 100.
+	LogitBias map[string]int `json:"logitBias,omitempty"`
+
+	// PresencePenalty between -2.0 and 2.0. Positive values penalize new
+	// tokens based on whether they appear in the text so far.
+	PresencePenalty float64 `json:"presencePenalty,omitempty"`
+
+	// User is a unique identifier representing the end-user, which can help
+	// OpenAI to monitor and detect abuse.
+	User string `json:"user,omitempty"`
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithExtra sets the OpenAI-specific options. Other providers ignore them.
+func WithExtra(extra Extra) provider.Func {
+	return provider.WithExtra(Name, extra)
+}
+
+//////
+// Helpers.
+//////
+
+// apply merges the options into the request body.
+func (e Extra) apply(reqBody *RequestBody) {
+	reqBody.FrequencyPenalty = e.FrequencyPenalty
+	reqBody.LogitBias = e.LogitBias
+	reqBody.PresencePenalty = e.PresencePenalty
+	reqBody.User = e.User
+}
@@ -6,6 +6,9 @@ import "github.com/thalesfsp/inference/message"
 // Const, vars, types.
 //////
 
+// ResponseFormatJSONObject constrains the response to a valid JSON object.
+const ResponseFormatJSONObject = "json_object"
+
 //////
 // Request body.
 
@@ -15,10 +18,31 @@ type RequestBody struct {
 	Model    string            `json:"model"`
 	Stream   bool              `json:"stream"`
 
-	MaxTokens   int     `json:"max_completion_tokens,omitempty"`
-	Seed        int     `json:"seed,omitempty"`
-	Temperature float64 `json:"temperature,omitempty"`
-	TopP        float64 `json:"top_p,omitempty"`
+	MaxTokens   int      `json:"max_completion_tokens,omitempty"`
+	Seed        int      `json:"seed,omitempty"`
+	Stop        []string `json:"stop,omitempty"`
+	Temperature float64  `json:"temperature,omitempty"`
+	TopP        float64  `json:"top_p,omitempty"`
+
+	FrequencyPenalty float64        `json:"frequency_penalty,omitempty"`
+	LogitBias        map[string]int `json:"logit_bias,omitempty"`
+	PresencePenalty  float64        `json:"presence_penalty,omitempty"`
+	User             string         `json:"user,omitempty"`
+
+	ResponseFormat *ResponseFormat `json:"response_format,omitempty"`
+	StreamOptions  *StreamOptions  `json:"stream_options,omitempty"`
+}
+
+// ResponseFormat OpenAI API definition.
+type ResponseFormat struct {
+	// Type of the response, e.g.: ResponseFormatJSONObject.
+	Type string `json:"type"`
+}
+
+// StreamOptions OpenAI API definition.
+type StreamOptions struct {
+	// IncludeUsage sends the usage in the last chunk.
+	IncludeUsage bool `json:"include_usage"`
 }
 
 //////
@@ -47,3 +71,86 @@ type ResponseBody struct {
 	Object  string   `json:"object"`
 	Usage   Usage    `json:"usage"`
 }
+
+//////
+// Embeddings.
+
+// EmbeddingsRequestBody represents the request body of the embeddings API.
+type EmbeddingsRequestBody struct {
+	Dimensions int      `json:"dimensions,omitempty"`
+	Input      []string `json:"input"`
+	Model      string   `json:"model"`
+}
+
+// Embedding OpenAI API definition.
+type Embedding struct {
+	Embedding []float64 `json:"embedding"`
+	Index     int       `json:"index"`
+}
+
+// EmbeddingsResponseBody represents the response body of the embeddings API.
+type EmbeddingsResponseBody struct {
+	Data  []Embedding `json:"data"`
+	Model string      `json:"model"`
+	Usage Usage       `json:"usage"`
+}
+
+//////
+// Batch.
+
+// BatchLine is a line of the batch input file.
+type BatchLine struct {
+	Body     *RequestBody `json:"body"`
+	CustomID string       `json:"custom_id"`
+	Method   string       `json:"method"`
+	URL      string       `json:"url"`
+}
+
+// BatchRequestBody represents the request body of the batches API.
+type BatchRequestBody struct {
+	CompletionWindow string `json:"completion_window"`
+	Endpoint         string `json:"endpoint"`
+	InputFileID      string `json:"input_file_id"`
+}
+
+// BatchRequestCounts OpenAI API definition.
+type BatchRequestCounts struct {
+	Completed int `json:"completed"`
+	Failed    int `json:"failed"`
+	Total     int `json:"total"`
+}
+
+// Batch OpenAI API definition.
+type Batch struct {
+	CreatedAt     int64              `json:"created_at"`
+	ErrorFileID   string             `json:"error_file_id,omitempty"`
+	ID            string             `json:"id"`
+	OutputFileID  string             `json:"output_file_id,omitempty"`
+	RequestCounts BatchRequestCounts `json:"request_counts"`
+	Status        string             `json:"status"`
+}
+
+// File OpenAI API definition.
+type File struct {
+	ID string `json:"id"`
+}
+
+// BatchError OpenAI API definition.
+type BatchError struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// BatchResponse OpenAI API definition.
+type BatchResponse struct {
+	Body       ResponseBody `json:"body"`
+	StatusCode int          `json:"status_code"`
+}
+
+// BatchResultLine is a line of the batch output, or error file.
+type BatchResultLine struct {
+	CustomID string         `json:"custom_id"`
+	Error    *BatchError    `json:"error"`
+	ID       string         `json:"id"`
+	Response *BatchResponse `json:"response"`
+}
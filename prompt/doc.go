@@ -0,0 +1,30 @@
+// Package prompt provides named, versioned prompt templates, based on Go's
+// text/template, rendered into messages.
+//
+// A template file (`.tmpl`) has an optional YAML front matter declaring its
+// name, version, and typed variables, followed by the template itself, e.g.:
+//
+//	---
+//	name: classify
+//	version: 2
+//	variables:
+//	  text:
+//	    type: string
+//	    required: true
+//	  labels:
+//	    type: list
+//	    default: [positive, negative]
+//	---
+//	{{define "system"}}{{template "tone" .}} Classify as {{join .labels ", "}}.{{end}}
+//	{{define "user"}}{{.text}}{{end}}
+//
+// The `system`, and `user` blocks are rendered into the messages of the
+// respective roles. Without them, the whole template is the user message.
+// Files in a `partials` directory, e.g.: `partials/tone.tmpl`, are partials,
+// usable by every template.
+//
+// Render into the options of a completion with WithTemplate, which also
+// records the template name, and version for observability:
+//
+//	p.Completion(ctx, prompt.WithTemplate("classify", prompt.Vars{"text": "I love it"}))
+package prompt
@@ -0,0 +1,104 @@
+package prompt
+
+import (
+	"embed"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//go:embed testdata/prompts
+var prompts embed.FS
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+
+	assert.NoError(t, r.Load(prompts, "testdata/prompts"))
+	assert.Equal(t, []string{"classify", "support/greet"}, r.Names())
+
+	// Latest version, partials, and defaults.
+	rendered, err := r.Render("classify", Vars{"text": "I love it"})
+	assert.NoError(t, err)
+	assert.Equal(t, &Rendered{
+		Name:           "classify",
+		SystemMessages: []string{"You are a precise assistant. Classify the text as one of: positive, negative."},
+		UserMessages:   []string{"I love it"},
+		Version:        2,
+	}, rendered)
+
+	// Specific version, without blocks.
+	rendered, err = r.Render("classify@1", Vars{"text": "I love it"})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Classify the sentiment of: I love it"}, rendered.UserMessages)
+	assert.Empty(t, rendered.SystemMessages)
+
+	// Undeclared variables are free.
+	rendered, err = r.Render("support/greet", Vars{"name": "Ana", "count": 3})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Hello Ana, you have 3 new messages."}, rendered.UserMessages)
+
+	// Errors.
+	_, err = r.Render("classify", Vars{})
+	assert.ErrorContains(t, err, "text")
+
+	_, err = r.Render("classify", Vars{"text": 1})
+	assert.ErrorContains(t, err, "expected string")
+
+	_, err = r.Render("classify", Vars{"text": "ok", "txet": "typo"})
+	assert.ErrorContains(t, err, "txet")
+
+	_, err = r.Render("support/greet", Vars{"name": "Ana"})
+	assert.ErrorContains(t, err, "count")
+
+	_, err = r.Render("classify@3", nil)
+	assert.Error(t, err)
+
+	_, err = r.Render("unknown", nil)
+	assert.Error(t, err)
+
+	// Loading from a directory.
+	r = NewRegistry()
+
+	assert.NoError(t, r.LoadDir("testdata/prompts"))
+	assert.Equal(t, []string{"classify", "support/greet"}, r.Names())
+}
+
+func TestWithTemplate(t *testing.T) {
+	r := NewRegistry()
+
+	tmpl, err := New("echo", 3, `{{define "user"}}{{upper .text}}{{end}}`, map[string]Variable{
+		"text": {Type: TypeString, Required: true},
+	})
+	assert.NoError(t, err)
+	assert.NoError(t, r.Register(tmpl))
+
+	o, err := provider.NewOptionsFrom(
+		provider.WithModel("m"),
+		provider.WithSystemMessages("be brief"),
+		r.WithTemplate("echo", Vars{"text": "hi"}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"be brief"}, o.SystemMessages)
+	assert.Equal(t, []string{"HI"}, o.UserMessages)
+	assert.Equal(t, &provider.TemplateRef{Name: "echo", Version: 3}, o.Template)
+
+	_, err = provider.NewOptionsFrom(provider.WithModel("m"), r.WithTemplate("echo", nil))
+	assert.Error(t, err)
+
+	_, err = New("broken", 1, "{{.text", nil)
+	assert.Error(t, err)
+}
+
+func TestHasType(t *testing.T) {
+	assert.True(t, hasType(1, TypeInt))
+	assert.True(t, hasType(2.0, TypeInt))
+	assert.False(t, hasType(2.5, TypeInt))
+	assert.True(t, hasType(2, TypeFloat))
+	assert.True(t, hasType([]string{}, TypeList))
+	assert.True(t, hasType(map[string]any{}, TypeMap))
+	assert.True(t, hasType(struct{}{}, TypeMap))
+	assert.True(t, hasType(true, TypeBool))
+	assert.True(t, hasType(nil, TypeAny))
+	assert.False(t, hasType(1, TypeString))
+}
@@ -0,0 +1,303 @@
+package prompt
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Extension of template files.
+const Extension = ".tmpl"
+
+// partialsDir is the name of directories holding partials.
+const partialsDir = "partials"
+
+// versionSeparator separates the name, and the version in references, e.g.:
+// `classify@2`.
+const versionSeparator = "@"
+
+// Singleton.
+var (
+	once      sync.Once
+	singleton *Registry
+)
+
+// Registry of templates, and partials, safe for concurrent use.
+type Registry struct {
+	mu        sync.RWMutex
+	partials  map[string]string
+	templates map[string]map[int]*Template
+}
+
+//////
+// Methods.
+//////
+
+// Register adds, or replaces templates.
+func (r *Registry) Register(templates ...*Template) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range templates {
+		if t == nil {
+			return customerror.NewRequiredError("template")
+		}
+
+		if r.templates[t.Name] == nil {
+			r.templates[t.Name] = make(map[int]*Template)
+		}
+
+		r.templates[t.Name][t.Version] = t
+	}
+
+	return nil
+}
+
+// RegisterPartial adds, or replaces a partial, usable by every template as
+// `{{template "name" .}}`. Surrounding white space, e.g.: the trailing new
+// line of files, is trimmed.
+func (r *Registry) RegisterPartial(name, source string) error {
+	if name == "" {
+		return customerror.NewRequiredError("name")
+	}
+
+	source = strings.TrimSpace(source)
+
+	if _, err := template.New(name).Funcs(Funcs).Parse(source); err != nil {
+		return customerror.NewInvalidError("partial "+name, customerror.WithError(err))
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.partials[name] = source
+
+	return nil
+}
+
+// Get returns the template referenced by name, at its latest version, or at
+// a specific one, e.g.: `classify@2`.
+func (r *Registry) Get(ref string) (*Template, error) {
+	name, version, err := parseRef(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	versions, ok := r.templates[name]
+	if !ok {
+		return nil, customerror.NewMissingError("template " + name)
+	}
+
+	if version == 0 {
+		for v := range versions {
+			version = max(version, v)
+		}
+	}
+
+	t, ok := versions[version]
+	if !ok {
+		return nil, customerror.NewMissingError(fmt.Sprintf("template %s, version %d", name, version))
+	}
+
+	return t, nil
+}
+
+// Names returns the names of the templates, sorted.
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.templates))
+
+	for name := range r.templates {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// Render renders the referenced template with the variables.
+func (r *Registry) Render(ref string, vars Vars) (*Rendered, error) {
+	t, err := r.Get(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	partials, err := r.parsePartials()
+	if err != nil {
+		return nil, err
+	}
+
+	return t.render(partials, vars)
+}
+
+// WithTemplate renders the referenced template with the variables, appending
+// the messages to the options, and recording the template name, and version.
+func (r *Registry) WithTemplate(ref string, vars Vars) provider.Func {
+	return func(o *provider.Options) error {
+		rendered, err := r.Render(ref, vars)
+		if err != nil {
+			return err
+		}
+
+		o.SystemMessages = append(o.SystemMessages, rendered.SystemMessages...)
+		o.UserMessages = append(o.UserMessages, rendered.UserMessages...)
+		o.Template = &provider.TemplateRef{Name: rendered.Name, Version: rendered.Version}
+
+		return nil
+	}
+}
+
+// Load loads the templates (`*.tmpl`), and partials (`partials/*.tmpl`) found
+// under `dir` of the file system, e.g.: an embed.FS. Template names default to
+// the path relative to `dir`, without the extension, e.g.: `support/classify`.
+// Partial names are the file name, without the extension.
+func (r *Registry) Load(fsys fs.FS, dir string) error {
+	templates := []*Template{}
+
+	if err := fs.WalkDir(fsys, dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if d.IsDir() || path.Ext(p) != Extension {
+			return nil
+		}
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		name := strings.TrimSuffix(p, Extension)
+
+		if dir != "." {
+			name = strings.TrimPrefix(name, strings.TrimSuffix(dir, "/")+"/")
+		}
+
+		if path.Base(path.Dir(p)) == partialsDir {
+			return r.RegisterPartial(path.Base(name), string(data))
+		}
+
+		t, err := Parse(name, data)
+		if err != nil {
+			return err
+		}
+
+		templates = append(templates, t)
+
+		return nil
+	}); err != nil {
+		return customerror.NewFailedToError("load templates", customerror.WithError(err))
+	}
+
+	return r.Register(templates...)
+}
+
+// LoadDir loads the templates, and partials found under the directory.
+func (r *Registry) LoadDir(dir string) error {
+	return r.Load(os.DirFS(dir), ".")
+}
+
+//////
+// Helpers.
+//////
+
+// parsePartials parses the partials into a template set.
+func (r *Registry) parsePartials() (*template.Template, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if len(r.partials) == 0 {
+		return nil, nil
+	}
+
+	set := template.New("").Funcs(Funcs)
+
+	for name, source := range r.partials {
+		if _, err := set.New(name).Parse(source); err != nil {
+			return nil, customerror.NewInvalidError("partial "+name, customerror.WithError(err))
+		}
+	}
+
+	return set, nil
+}
+
+// parseRef parses a template reference, e.g.: `classify`, or `classify@2`.
+func parseRef(ref string) (string, int, error) {
+	name, v, found := strings.Cut(ref, versionSeparator)
+	if name == "" {
+		return "", 0, customerror.NewRequiredError("template name")
+	}
+
+	if !found {
+		return name, 0, nil
+	}
+
+	version, err := strconv.Atoi(v)
+	if err != nil || version < 1 {
+		return "", 0, customerror.NewInvalidError("template version " + v)
+	}
+
+	return name, version, nil
+}
+
+//////
+// Factory.
+//////
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		partials:  make(map[string]string),
+		templates: make(map[string]map[int]*Template),
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Get returns the default registry. Changes to it affect the whole
+// application.
+func Get() *Registry {
+	once.Do(func() {
+		singleton = NewRegistry()
+	})
+
+	return singleton
+}
+
+// Set sets the default registry.
+func Set(r *Registry) {
+	once.Do(func() {})
+
+	singleton = r
+}
+
+// WithTemplate renders the referenced template of the default registry with
+// the variables, appending the messages to the options, and recording the
+// template name, and version.
+func WithTemplate(ref string, vars Vars) provider.Func {
+	return func(o *provider.Options) error {
+		return Get().WithTemplate(ref, vars)(o)
+	}
+}
@@ -0,0 +1,370 @@
+package prompt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/validation"
+	"gopkg.in/yaml.v3"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Names of the blocks rendered into messages.
+const (
+	BlockSystem = "system"
+	BlockUser   = "user"
+)
+
+// Variable types.
+const (
+	TypeAny    = "any"
+	TypeBool   = "bool"
+	TypeFloat  = "float"
+	TypeInt    = "int"
+	TypeList   = "list"
+	TypeMap    = "map"
+	TypeString = "string"
+)
+
+// frontMatterDelimiter delimits the front matter.
+const frontMatterDelimiter = "---"
+
+// Funcs are the functions available to templates.
+var Funcs = template.FuncMap{
+	"join":  join,
+	"lower": strings.ToLower,
+	"toJSON": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+
+		return string(data), err
+	},
+	"trim":  strings.TrimSpace,
+	"upper": strings.ToUpper,
+}
+
+// Vars are the variables a template is rendered with.
+type Vars map[string]any
+
+// Variable is a declared variable.
+type Variable struct {
+	// Default value, used when not set.
+	Default any `json:"default,omitempty" yaml:"default,omitempty"`
+
+	// Description of the variable.
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+
+	// Required variables must be set, unless they have a default.
+	Required bool `json:"required,omitempty" yaml:"required,omitempty"`
+
+	// Type of the variable. Default to `string`.
+	Type string `json:"type,omitempty" yaml:"type,omitempty" validate:"omitempty,oneof=any bool float int list map string"`
+}
+
+// Template is a named, versioned prompt template.
+type Template struct {
+	// Name of the template.
+	Name string `json:"name" yaml:"name" validate:"required"`
+
+	// Source of the template, without the front matter.
+	Source string `json:"-" yaml:"-" validate:"required"`
+
+	// Variables declared, by name.
+	Variables map[string]Variable `json:"variables,omitempty" yaml:"variables,omitempty" validate:"dive"`
+
+	// Version of the template. Default to 1.
+	Version int `json:"version" yaml:"version" validate:"gte=1"`
+}
+
+// Rendered is a rendered template.
+type Rendered struct {
+	Name           string   `json:"name"`
+	SystemMessages []string `json:"systemMessages,omitempty"`
+	UserMessages   []string `json:"userMessages,omitempty"`
+	Version        int      `json:"version"`
+}
+
+//////
+// Methods.
+//////
+
+// Vars validates the variables against the declared ones, and applies the
+// defaults. Undeclared variables are rejected, unless none are declared.
+func (t *Template) Vars(vars Vars) (Vars, error) {
+	resolved := make(Vars, len(vars)+len(t.Variables))
+
+	for name, value := range vars {
+		if len(t.Variables) > 0 {
+			if _, ok := t.Variables[name]; !ok {
+				return nil, customerror.NewInvalidError(fmt.Sprintf("variable %s, not declared by %s", name, t.Name))
+			}
+		}
+
+		resolved[name] = value
+	}
+
+	for _, name := range sortedNames(t.Variables) {
+		v := t.Variables[name]
+
+		value, ok := resolved[name]
+		if !ok || value == nil {
+			if v.Default == nil {
+				if v.Required {
+					return nil, customerror.NewRequiredError(fmt.Sprintf("variable %s of %s", name, t.Name))
+				}
+
+				continue
+			}
+
+			value = v.Default
+			resolved[name] = value
+		}
+
+		if !hasType(value, v.Type) {
+			return nil, customerror.NewInvalidError(
+				fmt.Sprintf("variable %s of %s, expected %s, got %T", name, t.Name, typeOrDefault(v.Type), value),
+			)
+		}
+	}
+
+	return resolved, nil
+}
+
+// String implements the Stringer interface.
+func (t *Template) String() string {
+	return fmt.Sprintf("%s@%d", t.Name, t.Version)
+}
+
+//////
+// Helpers.
+//////
+
+// render renders the template, parsed with the partials, into messages.
+func (t *Template) render(partials *template.Template, vars Vars) (*Rendered, error) {
+	resolved, err := t.Vars(vars)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := t.parse(partials)
+	if err != nil {
+		return nil, err
+	}
+
+	rendered := &Rendered{Name: t.Name, Version: t.Version}
+
+	execute := func(name string) (string, error) {
+		var buf bytes.Buffer
+
+		if err := tmpl.ExecuteTemplate(&buf, name, resolved); err != nil {
+			return "", customerror.NewFailedToError("render "+t.String(), customerror.WithError(err))
+		}
+
+		return strings.TrimSpace(buf.String()), nil
+	}
+
+	system, user := tmpl.Lookup(BlockSystem), tmpl.Lookup(BlockUser)
+
+	if system == nil && user == nil {
+		content, err := execute(tmpl.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		rendered.UserMessages = nonEmpty(content)
+
+		return rendered, nil
+	}
+
+	if system != nil {
+		content, err := execute(BlockSystem)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered.SystemMessages = nonEmpty(content)
+	}
+
+	if user != nil {
+		content, err := execute(BlockUser)
+		if err != nil {
+			return nil, err
+		}
+
+		rendered.UserMessages = nonEmpty(content)
+	}
+
+	return rendered, nil
+}
+
+// parse parses the template along with the partials. Missing keys are errors.
+func (t *Template) parse(partials *template.Template) (*template.Template, error) {
+	var (
+		tmpl *template.Template
+		err  error
+	)
+
+	if partials != nil {
+		tmpl, err = partials.Clone()
+		if err != nil {
+			return nil, customerror.NewFailedToError("clone partials", customerror.WithError(err))
+		}
+
+		tmpl = tmpl.New(t.String())
+	} else {
+		tmpl = template.New(t.String()).Funcs(Funcs)
+	}
+
+	tmpl, err = tmpl.Option("missingkey=error").Parse(t.Source)
+	if err != nil {
+		return nil, customerror.NewInvalidError("template "+t.String(), customerror.WithError(err))
+	}
+
+	return tmpl, nil
+}
+
+// join joins the elements of any list, e.g.: decoded from YAML, with sep.
+func join(list any, sep string) (string, error) {
+	v := reflect.ValueOf(list)
+
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return "", customerror.NewInvalidError(fmt.Sprintf("join argument, expected list, got %T", list))
+	}
+
+	elems := make([]string, v.Len())
+
+	for i := range elems {
+		elems[i] = fmt.Sprint(v.Index(i).Interface())
+	}
+
+	return strings.Join(elems, sep), nil
+}
+
+// hasType returns true if the value is of the variable type.
+func hasType(value any, typ string) bool {
+	v := reflect.ValueOf(value)
+
+	switch typeOrDefault(typ) {
+	case TypeAny:
+		return true
+	case TypeBool:
+		return v.Kind() == reflect.Bool
+	case TypeFloat:
+		return v.CanInt() || v.CanUint() || v.CanFloat()
+	case TypeInt:
+		if v.CanFloat() {
+			// Numbers decoded from JSON, or YAML may be floats.
+			return v.Float() == float64(int64(v.Float()))
+		}
+
+		return v.CanInt() || v.CanUint()
+	case TypeList:
+		return v.Kind() == reflect.Slice || v.Kind() == reflect.Array
+	case TypeMap:
+		return v.Kind() == reflect.Map || v.Kind() == reflect.Struct ||
+			(v.Kind() == reflect.Pointer && v.Elem().Kind() == reflect.Struct)
+	case TypeString:
+		return v.Kind() == reflect.String
+	default:
+		return false
+	}
+}
+
+// typeOrDefault returns the type, or the default one.
+func typeOrDefault(typ string) string {
+	if typ == "" {
+		return TypeString
+	}
+
+	return typ
+}
+
+// nonEmpty returns the content as a single message, or none if empty.
+func nonEmpty(content string) []string {
+	if content == "" {
+		return nil
+	}
+
+	return []string{content}
+}
+
+// sortedNames returns the variable names, sorted.
+func sortedNames(variables map[string]Variable) []string {
+	names := make([]string, 0, len(variables))
+
+	for name := range variables {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+//////
+// Factory.
+//////
+
+// New returns a validated template.
+func New(name string, version int, source string, variables map[string]Variable) (*Template, error) {
+	if version == 0 {
+		version = 1
+	}
+
+	t := &Template{
+		Name:      name,
+		Source:    source,
+		Variables: variables,
+		Version:   version,
+	}
+
+	if err := validation.Validate(t); err != nil {
+		return nil, err
+	}
+
+	// Fail early on syntax errors.
+	if _, err := t.parse(nil); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+// Parse parses a template file: an optional YAML front matter, followed by
+// the template. Name defaults to `name`, if not set in the front matter.
+func Parse(name string, data []byte) (*Template, error) {
+	var t Template
+
+	source := string(data)
+
+	if rest, ok := strings.CutPrefix(source, frontMatterDelimiter+"\n"); ok {
+		frontMatter, body, found := strings.Cut(rest, "\n"+frontMatterDelimiter+"\n")
+		if !found {
+			return nil, customerror.NewInvalidError("front matter of " + name + ", not closed")
+		}
+
+		decoder := yaml.NewDecoder(strings.NewReader(frontMatter))
+
+		decoder.KnownFields(true)
+
+		if err := decoder.Decode(&t); err != nil {
+			return nil, customerror.NewFailedToError("unmarshal front matter of "+name, customerror.WithError(err))
+		}
+
+		source = body
+	}
+
+	if t.Name == "" {
+		t.Name = name
+	}
+
+	return New(t.Name, t.Version, source, t.Variables)
+}
@@ -0,0 +1,163 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// ErrBudgetExceeded is the error code of requests rejected because they would
+// exceed the budget.
+const ErrBudgetExceeded = "ErrBudgetExceeded"
+
+// ErrUnpricedModel is the error code of requests rejected because they carry
+// a budget, but the prices of the model are unknown by the catalog.
+const ErrUnpricedModel = "ErrUnpricedModel"
+
+// budgetContextKey is the context key of the budget.
+type budgetContextKey struct{}
+
+// Budget is the maximum amount, in USD, a caller can spend. It's safe for
+// concurrent use, and can be shared by many requests, and providers.
+type Budget struct {
+	mu sync.Mutex
+
+	limit    float64
+	reserved float64
+	spent    float64
+}
+
+// Settle settles a reservation with the actual usage of the request. Pass a
+// zero Usage if the request failed.
+type Settle func(usage Usage)
+
+//////
+// Methods.
+//////
+
+// Limit returns the budget limit.
+func (b *Budget) Limit() float64 {
+	return b.limit
+}
+
+// Spent returns the amount spent.
+func (b *Budget) Spent() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.spent
+}
+
+// Remaining returns the amount not spent, nor reserved by in-flight requests.
+func (b *Budget) Remaining() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.limit - b.spent - b.reserved
+}
+
+// reserve reserves the amount, if available.
+func (b *Budget) reserve(amount float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	remaining := b.limit - b.spent - b.reserved
+
+	if amount > remaining {
+		return customerror.New(
+			"budget exceeded",
+			customerror.WithErrorCode(ErrBudgetExceeded),
+			customerror.WithStatusCode(http.StatusPaymentRequired),
+			customerror.WithField("estimated", fmt.Sprintf("%.6f", amount)),
+			customerror.WithField("remaining", fmt.Sprintf("%.6f", remaining)),
+		)
+	}
+
+	b.reserved += amount
+
+	return nil
+}
+
+// settle releases the reserved amount, and spends the actual amount.
+func (b *Budget) settle(reserved, actual float64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reserved -= reserved
+	b.spent += actual
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithUnpricedModels allows requests carrying a budget to use models whose
+// prices are unknown by the catalog, e.g.: local ones. They cost nothing
+// against the budget. Default to rejecting them.
+func WithUnpricedModels(allowed bool) Func {
+	return func(o *Options) error {
+		o.AllowUnpriced = allowed
+
+		return nil
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// ContextWithBudget returns a copy of ctx carrying the budget. Completions
+// called with it are rejected, before hitting the API, if their estimated cost
+// exceeds the remaining budget, or if the prices of their model are unknown,
+// see WithUnpricedModels.
+func ContextWithBudget(ctx context.Context, budget *Budget) context.Context {
+	return context.WithValue(ctx, budgetContextKey{}, budget)
+}
+
+// BudgetFromContext returns the budget carried by ctx, if any.
+func BudgetFromContext(ctx context.Context) (*Budget, bool) {
+	budget, ok := ctx.Value(budgetContextKey{}).(*Budget)
+
+	return budget, ok && budget != nil
+}
+
+// IsBudgetExceeded returns true if err is due to the budget being exceeded.
+func IsBudgetExceeded(err error) bool {
+	return customerror.IsErrorCode(err, ErrBudgetExceeded)
+}
+
+// IsUnpricedModel returns true if err is due to the budget not being
+// enforceable, the prices of the model being unknown.
+func IsUnpricedModel(err error) bool {
+	return customerror.IsErrorCode(err, ErrUnpricedModel)
+}
+
+//////
+// Helpers.
+//////
+
+// newUnpricedModelError returns the error of requests carrying a budget, for
+// an unpriced model.
+func newUnpricedModelError(model string) error {
+	return customerror.New(
+		fmt.Sprintf("prices of %s unknown, the budget can't be enforced", model),
+		customerror.WithErrorCode(ErrUnpricedModel),
+		customerror.WithStatusCode(http.StatusPaymentRequired),
+	)
+}
+
+//////
+// Factory.
+//////
+
+// NewBudget returns a new budget with the limit, in USD.
+func NewBudget(limit float64) *Budget {
+	return &Budget{limit: limit}
+}
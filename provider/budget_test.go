@@ -0,0 +1,79 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReserve(t *testing.T) {
+	p, err := New("budget", WithEndpoint("http://localhost"))
+	assert.NoError(t, err)
+
+	// gpt-4o: $2.50, and $10 per million input, and output tokens.
+	newOptions := func(maxTokens int) *Options {
+		o, err := NewOptionsFrom(
+			WithProvider("openai"),
+			WithModel("gpt-4o"),
+			WithMaxToken(maxTokens),
+			WithUserMessages("why is the sky blue"),
+		)
+		assert.NoError(t, err)
+
+		return o
+	}
+
+	budget := NewBudget(0.01)
+	ctx := ContextWithBudget(context.Background(), budget)
+
+	// Estimated: ~10 input tokens, 1000 output tokens = ~$0.01.
+	_, err = p.Reserve(ctx, newOptions(1000))
+	assert.True(t, IsBudgetExceeded(err))
+
+	// Estimated: ~10 input tokens, 500 output tokens = ~$0.005.
+	o := newOptions(500)
+
+	var usage Usage
+
+	assert.NoError(t, WithUsage(&usage)(o))
+
+	settle, err := p.Reserve(ctx, o)
+	assert.NoError(t, err)
+	assert.Less(t, budget.Remaining(), 0.005)
+
+	// Another concurrent request can't fit.
+	_, err = p.Reserve(ctx, newOptions(500))
+	assert.True(t, IsBudgetExceeded(err))
+
+	settle(Usage{InputTokens: 1000, OutputTokens: 100})
+
+	assert.InDelta(t, 0.0035, usage.Cost, 1e-9)
+	assert.InDelta(t, 0.0035, budget.Spent(), 1e-9)
+	assert.InDelta(t, 0.0065, budget.Remaining(), 1e-9)
+	assert.InDelta(t, 0.0035, p.GetCounterCost().Value(), 1e-9)
+	assert.Equal(t, int64(1000), p.GetCounterInputTokens().Value())
+	assert.Equal(t, int64(100), p.GetCounterOutputTokens().Value())
+
+	// Unpriced models, unless allowed.
+	unpriced, err := NewOptionsFrom(WithProvider("custom"), WithModel("model"), WithUserMessages("hi"))
+	assert.NoError(t, err)
+
+	_, err = p.Reserve(ctx, unpriced)
+	assert.True(t, IsUnpricedModel(err))
+
+	assert.NoError(t, WithUnpricedModels(true)(unpriced))
+
+	settle, err = p.Reserve(ctx, unpriced)
+	assert.NoError(t, err)
+
+	settle(Usage{InputTokens: 1000, OutputTokens: 100})
+
+	assert.InDelta(t, 0.0035, budget.Spent(), 1e-9)
+
+	// Without budget, nothing is reserved.
+	settle, err = p.Reserve(context.Background(), newOptions(100000))
+	assert.NoError(t, err)
+
+	settle(Usage{})
+}
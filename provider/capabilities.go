@@ -0,0 +1,104 @@
+package provider
+
+import (
+	"maps"
+	"strings"
+
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Capabilities describes what a provider, or model supports.
+type Capabilities struct {
+	// JSONMode means the response can be constrained to valid JSON.
+	JSONMode bool `json:"jsonMode"`
+
+	// MaxContext is the maximum context length in tokens. Zero means unknown.
+	MaxContext int `json:"maxContext,omitempty"`
+
+	// Seed means sampling can be made deterministic with a seed.
+	Seed bool `json:"seed"`
+
+	// Streaming means the response can be streamed.
+	Streaming bool `json:"streaming"`
+
+	// Tools means the model can call tools (functions). Descriptive only, no
+	// option uses them yet.
+	Tools bool `json:"tools"`
+
+	// TopK means top-k sampling is available.
+	TopK bool `json:"topK"`
+
+	// Vision means images can be sent as input. Descriptive only, no option
+	// sends them yet.
+	Vision bool `json:"vision"`
+
+	// Models overrides the capabilities per model. Keys are model name
+	// prefixes, the longest matching prefix wins.
+	Models map[string]Capabilities `json:"models,omitempty"`
+}
+
+//////
+// Methods.
+//////
+
+// Clone returns a copy, not sharing the Models.
+func (c Capabilities) Clone() Capabilities {
+	c.Models = maps.Clone(c.Models)
+
+	return c
+}
+
+// For returns the capabilities of the model.
+func (c Capabilities) For(model string) Capabilities {
+	match := ""
+
+	for prefix := range c.Models {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(match) {
+			match = prefix
+		}
+	}
+
+	if match == "" {
+		return c
+	}
+
+	return c.Models[match]
+}
+
+// Validate returns an error if the options use features which aren't
+// supported.
+func (c Capabilities) Validate(o *Options) error {
+	unsupported := []string{}
+
+	if o.JSONMode && !c.JSONMode {
+		unsupported = append(unsupported, "jsonMode")
+	}
+
+	if o.Seed > 0 && !c.Seed {
+		unsupported = append(unsupported, "seed")
+	}
+
+	if o.Stream && !c.Streaming {
+		unsupported = append(unsupported, "stream")
+	}
+
+	if o.TopK > 0 && !c.TopK {
+		unsupported = append(unsupported, "topK")
+	}
+
+	if c.MaxContext > 0 && o.MaxTokens > c.MaxContext {
+		unsupported = append(unsupported, "maxTokens greater than max context")
+	}
+
+	if len(unsupported) > 0 {
+		return customerror.NewInvalidError(
+			"options, not supported by " + o.Model + ": " + strings.Join(unsupported, ", "),
+		)
+	}
+
+	return nil
+}
@@ -0,0 +1,84 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilities(t *testing.T) {
+	capabilities := Capabilities{
+		MaxContext: 8192,
+		Seed:       true,
+
+		Models: map[string]Capabilities{
+			"model-a":       {MaxContext: 4096},
+			"model-a-large": {MaxContext: 16384, Seed: true, TopK: true},
+		},
+	}
+
+	assert.Equal(t, 8192, capabilities.For("model-b").MaxContext)
+	assert.Equal(t, 4096, capabilities.For("model-a-20240101").MaxContext)
+	assert.Equal(t, 16384, capabilities.For("model-a-large-20240101").MaxContext)
+
+	clone := capabilities.Clone()
+	clone.Models["model-a"] = Capabilities{MaxContext: 1}
+
+	assert.Equal(t, 4096, capabilities.For("model-a").MaxContext)
+
+	tests := []struct {
+		name    string
+		options []Func
+		wantErr bool
+	}{
+		{
+			name:    "Supported",
+			options: []Func{WithModel("model-b"), WithSeed(42), WithStrict(true)},
+		},
+		{
+			name:    "Unsupported, not strict",
+			options: []Func{WithModel("model-a"), WithSeed(42), WithTopK(40)},
+		},
+		{
+			name:    "Unsupported seed",
+			options: []Func{WithModel("model-a"), WithSeed(42), WithStrict(true)},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported JSON mode",
+			options: []Func{WithModel("model-b"), WithJSONMode(true), WithStrict(true)},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported topK",
+			options: []Func{WithModel("model-b"), WithTopK(40), WithStrict(true)},
+			wantErr: true,
+		},
+		{
+			name:    "Unsupported stream handler, not strict",
+			options: []Func{WithModel("model-a"), WithStreamHandler(func(string) error { return nil })},
+			wantErr: true,
+		},
+		{
+			name:    "MaxTokens greater than context",
+			options: []Func{WithModel("model-a"), WithMaxToken(8192), WithStrict(true)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			options := append([]Func{
+				WithCapabilities(capabilities),
+				WithUserMessages("message"),
+			}, tt.options...)
+
+			_, err := NewOptionsFrom(options...)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -1,5 +1,16 @@
 package provider
 
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
 //////
 // Vars, consts, and types.
 //////
@@ -7,16 +18,47 @@ package provider
 // ClientFunc allows to set provider options.
 type ClientFunc func(o *ClientOptions) error
 
+// Retry of failed requests, e.g.: rate limited, with an exponential backoff.
+type Retry struct {
+	// Backoff before the first retry, doubled for each next. Default to 1s.
+	Backoff time.Duration `json:"backoff,omitempty" validate:"gte=0"`
+
+	// Times failed requests are retried. Zero disables retries.
+	Times int `json:"times" validate:"gte=0"`
+}
+
 // ClientOptions for the provider.
 type ClientOptions struct {
 	// Endpoint to reach the provider.
 	Endpoint string `json:"endpoint" validate:"required"`
 
+	// Instance name, telling apart providers with the same name, e.g.: two
+	// `openai` ones of a registry, in their metrics.
+	Instance string `json:"instance,omitempty"`
+
 	// Model default model to be used.
 	Model string `json:"model,omitempty"`
 
 	// Token to authenticate against the provider.
 	Token string `json:"-"`
+
+	// MeterProvider to create the OpenTelemetry metric instruments. Default to
+	// the global one.
+	MeterProvider metric.MeterProvider `json:"-"`
+
+	// TracerProvider to create the OpenTelemetry tracer. Default to the global
+	// one.
+	TracerProvider trace.TracerProvider `json:"-"`
+
+	// Retry of failed requests. Default to 3 retries, with a 1s backoff.
+	Retry *Retry `json:"retry,omitempty"`
+
+	// Timeout of requests, excluding streamed ones. Default to 30s.
+	Timeout time.Duration `json:"timeout,omitempty" validate:"gte=0"`
+
+	// Transport of the HTTP client, e.g.: a cassette recorder in tests.
+	// Default to the standard one.
+	Transport http.RoundTripper `json:"-"`
 }
 
 //////
@@ -45,6 +87,17 @@ func WithToken(token string) ClientFunc {
 	}
 }
 
+// WithInstance sets the instance name.
+func WithInstance(instance string) ClientFunc {
+	return func(o *ClientOptions) error {
+		if instance != "" {
+			o.Instance = instance
+		}
+
+		return nil
+	}
+}
+
 // WithDefaulModel sets the default model.
 func WithDefaulModel(model string) ClientFunc {
 	return func(o *ClientOptions) error {
@@ -55,3 +108,61 @@ func WithDefaulModel(model string) ClientFunc {
 		return nil
 	}
 }
+
+// WithMeterProvider sets the OpenTelemetry meter provider.
+func WithMeterProvider(mp metric.MeterProvider) ClientFunc {
+	return func(o *ClientOptions) error {
+		if mp != nil {
+			o.MeterProvider = mp
+		}
+
+		return nil
+	}
+}
+
+// WithTracerProvider sets the OpenTelemetry tracer provider.
+func WithTracerProvider(tp trace.TracerProvider) ClientFunc {
+	return func(o *ClientOptions) error {
+		if tp != nil {
+			o.TracerProvider = tp
+		}
+
+		return nil
+	}
+}
+
+// WithTransport sets the transport of the HTTP client.
+func WithTransport(transport http.RoundTripper) ClientFunc {
+	return func(o *ClientOptions) error {
+		if transport != nil {
+			o.Transport = transport
+		}
+
+		return nil
+	}
+}
+
+// WithRetry sets the retry of failed requests: up to `times` retries, zero
+// disables them, waiting `backoff`, doubled for each next, default to 1s.
+func WithRetry(times int, backoff time.Duration) ClientFunc {
+	return func(o *ClientOptions) error {
+		if times < 0 || backoff < 0 {
+			return customerror.NewInvalidError(fmt.Sprintf("retry %d times, after %s", times, backoff))
+		}
+
+		o.Retry = &Retry{Backoff: backoff, Times: times}
+
+		return nil
+	}
+}
+
+// WithTimeout sets the timeout of requests, excluding streamed ones.
+func WithTimeout(timeout time.Duration) ClientFunc {
+	return func(o *ClientOptions) error {
+		if timeout > 0 {
+			o.Timeout = timeout
+		}
+
+		return nil
+	}
+}
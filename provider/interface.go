@@ -13,6 +13,7 @@ import (
 
 // IMeta definition.
 type IMeta interface {
+	// GetClient returns the client.
 	GetClient() any
 
 	// GetLogger returns the logger.
@@ -25,6 +26,34 @@ type IMeta interface {
 	GetType() string
 }
 
+// ICapabilities is optionally implemented by providers describing what they,
+// and their models support, e.g.: the built-in ones. See CapabilitiesOf.
+type ICapabilities interface {
+	// GetCapabilities returns what the provider, and its models support.
+	GetCapabilities() Capabilities
+}
+
+// IDefaultModel is optionally implemented by providers with a default model,
+// e.g.: the built-in ones. See DefaultModel.
+type IDefaultModel interface {
+	// GetDefaultModel returns the model used when none is set.
+	GetDefaultModel() string
+}
+
+// IEndpoint is optionally implemented by providers reached through an
+// endpoint, e.g.: the built-in ones. See EndpointOf.
+type IEndpoint interface {
+	// GetEndpoint returns the endpoint requests are sent to.
+	GetEndpoint() string
+}
+
+// IInstance is optionally implemented by providers telling apart instances
+// with the same name, e.g.: the built-in ones. See InstanceOf.
+type IInstance interface {
+	// GetInstance returns the instance name, if set.
+	GetInstance() string
+}
+
 // IMetrics definition.
 type IMetrics interface {
 	// GetCounterCompletion returns the completion metric.
@@ -32,6 +61,15 @@ type IMetrics interface {
 
 	// GetCounterCompletionFailed returns the failed completion metric.
 	GetCounterCompletionFailed() *expvar.Int
+
+	// GetCounterCost returns the cost, in USD, metric.
+	GetCounterCost() *expvar.Float
+
+	// GetCounterInputTokens returns the input tokens metric.
+	GetCounterInputTokens() *expvar.Int
+
+	// GetCounterOutputTokens returns the output tokens metric.
+	GetCounterOutputTokens() *expvar.Int
 }
 
 // IProvider defines what a provider does.
@@ -44,6 +82,52 @@ type IProvider interface {
 	// Optionally pass WithResponseBody to unmarshal the response body.
 	// It will always return the original, unparsed response body, if no error.
 	//
-	// NOTE: Not all options are available for all providers.
+	// NOTE: Not all options are available for all providers. Use WithStrict to
+	// fail instead of silently dropping them.
 	Completion(ctx context.Context, options ...Func) (string, error)
 }
+
+//////
+// Exported functionalities.
+//////
+
+// CapabilitiesOf returns what the provider, and its models support, if it
+// implements ICapabilities.
+func CapabilitiesOf(p IMeta) (Capabilities, bool) {
+	if c, ok := p.(ICapabilities); ok {
+		return c.GetCapabilities(), true
+	}
+
+	return Capabilities{}, false
+}
+
+// EndpointOf returns the endpoint requests are sent to, if the provider
+// implements IEndpoint, otherwise empty.
+func EndpointOf(p IMeta) string {
+	if e, ok := p.(IEndpoint); ok {
+		return e.GetEndpoint()
+	}
+
+	return ""
+}
+
+// InstanceOf returns the name identifying the provider instance, e.g.: in
+// metrics: its name, suffixed with the instance, if it implements IInstance,
+// and it's set, e.g.: `openai.fast`.
+func InstanceOf(p IMeta) string {
+	if i, ok := p.(IInstance); ok && i.GetInstance() != "" {
+		return p.GetName() + "." + i.GetInstance()
+	}
+
+	return p.GetName()
+}
+
+// DefaultModel returns the model the provider uses when none is set, if it
+// implements IDefaultModel, otherwise empty.
+func DefaultModel(p IMeta) string {
+	if d, ok := p.(IDefaultModel); ok {
+		return d.GetDefaultModel()
+	}
+
+	return ""
+}
@@ -2,6 +2,9 @@ package provider
 
 import (
 	"context"
+	"slices"
+	"sync"
+	"time"
 
 	"github.com/thalesfsp/concurrentloop"
 )
@@ -13,6 +16,21 @@ import (
 // Map is a map of strgs.
 type Map map[string]IProvider
 
+// Result of the completion of a provider.
+type Result struct {
+	// Duration of the completion.
+	Duration time.Duration `json:"duration"`
+
+	// Error of the completion, if it failed.
+	Error string `json:"error,omitempty"`
+
+	// Response of the completion, if it succeeded.
+	Response string `json:"response,omitempty"`
+
+	// Usage of the completion.
+	Usage Usage `json:"usage"`
+}
+
 //////
 // Methods.
 //////
@@ -60,6 +78,8 @@ func (m Map) Completion(
 ) (map[string]string, error) {
 	responseMap := make(map[string]string)
 
+	var mu sync.Mutex
+
 	if _, errs := concurrentloop.MapM(ctx, m,
 		func(ctx context.Context, providerName string, p IProvider) (string, error) {
 			response, err := p.Completion(ctx, options...)
@@ -67,6 +87,9 @@ func (m Map) Completion(
 				return "", err
 			}
 
+			mu.Lock()
+			defer mu.Unlock()
+
 			responseMap[providerName] = response
 
 			return response, nil
@@ -77,3 +100,41 @@ func (m Map) Completion(
 
 	return responseMap, nil
 }
+
+// CompletionEach calls the Completion concurrently against all providers in
+// the map, e.g.: to compare them. Unlike Completion, failures don't fail the
+// others, each result, mapped to the provider name, has its own error, and
+// usage. Providers not called, e.g.: ctx is done, have no result.
+func (m Map) CompletionEach(
+	ctx context.Context,
+	options ...Func,
+) map[string]Result {
+	results := make(map[string]Result)
+
+	var mu sync.Mutex
+
+	_, _ = concurrentloop.MapM(ctx, m,
+		func(ctx context.Context, providerName string, p IProvider) (string, error) {
+			var r Result
+
+			now := time.Now()
+
+			response, err := p.Completion(ctx, append(slices.Clone(options), WithUsage(&r.Usage))...)
+			if err != nil {
+				r.Error = err.Error()
+			}
+
+			r.Duration = time.Since(now)
+			r.Response = response
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			results[providerName] = r
+
+			return response, nil
+		},
+	)
+
+	return results
+}
@@ -2,6 +2,7 @@ package provider_test
 
 import (
 	"context"
+	"errors"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -9,6 +10,7 @@ import (
 	"github.com/thalesfsp/inference/internal/config"
 	"github.com/thalesfsp/inference/openai"
 	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/providertest"
 )
 
 func TestCompletionMany(t *testing.T) {
@@ -65,3 +67,34 @@ func TestCompletionMany(t *testing.T) {
 		})
 	}
 }
+
+func TestCompletionEach(t *testing.T) {
+	a, err := providertest.New(
+		providertest.WithName("a"),
+		providertest.WithResponses(providertest.Response{
+			Content: "pong",
+			Expect:  providertest.ExpectUserMessages("ping"),
+			Usage:   provider.Usage{InputTokens: 3, OutputTokens: 1},
+		}),
+	)
+	assert.NoError(t, err)
+
+	b, err := providertest.New(
+		providertest.WithName("b"),
+		providertest.WithResponses(providertest.Response{Err: errors.New("down")}),
+	)
+	assert.NoError(t, err)
+
+	results := provider.Map{"a": a, "b": b}.CompletionEach(
+		context.Background(),
+		provider.WithUserMessages("ping"),
+	)
+	assert.Len(t, results, 2)
+
+	assert.Equal(t, "pong", results["a"].Response)
+	assert.Empty(t, results["a"].Error)
+	assert.Equal(t, provider.Usage{InputTokens: 3, OutputTokens: 1}, results["a"].Usage)
+
+	assert.Empty(t, results["b"].Response)
+	assert.Equal(t, "down", results["b"].Error)
+}
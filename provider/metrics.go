@@ -0,0 +1,113 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/internal/metrics"
+	"github.com/thalesfsp/status"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Metric names. They are stable, and follow the Prometheus naming
+// conventions.
+const (
+	MetricCompletionDuration = "inference_completion_duration_seconds"
+	MetricCompletions        = "inference_completions_total"
+	MetricCost               = "inference_cost_usd_total"
+	MetricTokens             = "inference_tokens"
+)
+
+// Error classes, used as label of failed completions.
+const (
+	ErrorClassAuth           = "auth"
+	ErrorClassBudgetExceeded = "budget_exceeded"
+	ErrorClassCanceled       = "canceled"
+	ErrorClassClient         = "client"
+	ErrorClassOther          = "other"
+	ErrorClassRateLimited    = "rate_limited"
+	ErrorClassServer         = "server"
+	ErrorClassTimeout        = "timeout"
+)
+
+// Status label values.
+var (
+	metricStatusFailed    = status.Failed.String()
+	metricStatusSucceeded = status.Succeeded.String()
+)
+
+// Labeled metrics.
+var (
+	metricCompletionDuration = metrics.Default.NewHistogramVec(
+		MetricCompletionDuration,
+		"Duration of completions.",
+		[]float64{0.1, 0.25, 0.5, 1, 2.5, 5, 10, 20, 30, 60, 120},
+		"provider", "model", "status", "error_class",
+	)
+
+	metricCompletions = metrics.Default.NewCounterVec(
+		MetricCompletions,
+		"Amount of completions.",
+		"provider", "model", "status", "error_class",
+	)
+
+	metricCost = metrics.Default.NewCounterVec(
+		MetricCost,
+		"Cost, in USD, of completions.",
+		"provider", "model",
+	)
+
+	metricTokens = metrics.Default.NewHistogramVec(
+		MetricTokens,
+		"Tokens per completion.",
+		[]float64{16, 64, 256, 1024, 4096, 16384, 65536, 262144},
+		"provider", "model", "type",
+	)
+)
+
+//////
+// Exported functionalities.
+//////
+
+// MetricsHandler returns an HTTP handler exposing the labeled metrics of all
+// providers in the Prometheus text format.
+func MetricsHandler() http.Handler {
+	return metrics.Default.Handler()
+}
+
+// ErrorClass returns the low-cardinality class of a completion error.
+func ErrorClass(err error) string {
+	switch {
+	case IsBudgetExceeded(err):
+		return ErrorClassBudgetExceeded
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	}
+
+	cE, ok := customerror.To(err)
+	if !ok {
+		return ErrorClassOther
+	}
+
+	switch {
+	case cE.StatusCode == http.StatusUnauthorized, cE.StatusCode == http.StatusForbidden:
+		return ErrorClassAuth
+	case cE.StatusCode == http.StatusTooManyRequests:
+		return ErrorClassRateLimited
+	case cE.StatusCode == http.StatusRequestTimeout, cE.StatusCode == http.StatusGatewayTimeout:
+		return ErrorClassTimeout
+	case cE.StatusCode >= http.StatusInternalServerError:
+		return ErrorClassServer
+	case cE.StatusCode >= http.StatusBadRequest:
+		return ErrorClassClient
+	default:
+		return ErrorClassOther
+	}
+}
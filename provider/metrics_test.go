@@ -0,0 +1,66 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/customerror"
+)
+
+func TestErrorClass(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{"budget", customerror.New("over budget", customerror.WithErrorCode(ErrBudgetExceeded)), ErrorClassBudgetExceeded},
+		{"timeout", context.DeadlineExceeded, ErrorClassTimeout},
+		{"canceled", context.Canceled, ErrorClassCanceled},
+		{"auth", customerror.New("unauthorized", customerror.WithStatusCode(http.StatusUnauthorized)), ErrorClassAuth},
+		{"rate limited", customerror.New("rate limited", customerror.WithStatusCode(http.StatusTooManyRequests)), ErrorClassRateLimited},
+		{"client", customerror.New("bad request", customerror.WithStatusCode(http.StatusBadRequest)), ErrorClassClient},
+		{"server", customerror.New("bad gateway", customerror.WithStatusCode(http.StatusBadGateway)), ErrorClassServer},
+		{"other", errors.New("boom"), ErrorClassOther},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, ErrorClass(tt.err))
+		})
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	p, err := New("prometheus", WithEndpoint("http://localhost"))
+	assert.NoError(t, err)
+
+	o, err := NewOptionsFrom(WithModel("m"), WithUserMessages("hi"))
+	assert.NoError(t, err)
+
+	_, span := p.StartSpan(context.Background())
+	span.SetRequest(o)
+	span.SetUsage(Usage{InputTokens: 10, OutputTokens: 20})
+	span.End()
+
+	_, span = p.StartSpan(context.Background())
+	span.SetRequest(o)
+	assert.Error(t, span.Fail(customerror.New("rate limited", customerror.WithStatusCode(http.StatusTooManyRequests))))
+	span.End()
+
+	assert.Equal(t, 1.0, metricCompletions.Value("prometheus", "m", metricStatusSucceeded, ""))
+	assert.Equal(t, 1.0, metricCompletions.Value("prometheus", "m", metricStatusFailed, ErrorClassRateLimited))
+	assert.Equal(t, uint64(1), metricTokens.Count("prometheus", "m", "input"))
+
+	rec := httptest.NewRecorder()
+	MetricsHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	assert.Contains(
+		t,
+		rec.Body.String(),
+		`inference_completions_total{provider="prometheus",model="m",status="failed",error_class="rate_limited"} 1`,
+	)
+}
@@ -1,6 +1,12 @@
 package provider
 
 import (
+	"fmt"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/catalog"
+	"github.com/thalesfsp/inference/message"
 	"github.com/thalesfsp/validation"
 )
 
@@ -8,21 +14,75 @@ import (
 // Vars, consts, and types.
 //////
 
+// DefaultMaxTokens is the default max amount of tokens in the response.
+const DefaultMaxTokens = 4096
+
 // Func allows to set options.
 type Func func(o *Options) error
 
+// TemplateRef identifies the prompt template messages were rendered from.
+type TemplateRef struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// StreamFunc is called with each chunk of a streamed response. Returning an
+// error aborts the completion.
+type StreamFunc func(chunk string) error
+
 // Options for operations.
 type Options struct {
 	// Model is the model to be used.
 	Model string `json:"model" validate:"required"`
 
+	// Usage, if set, is filled with the usage of the completion.
+	Usage *Usage `json:"-"`
+
 	// UserMessages is the user role messages.
 	UserMessages []string `json:"userMessages" validate:"required"`
 
-	// MaxTokens defines the max amount of tokens in the response. Default to 0
-	// which means no limit.
+	// MaxTokens defines the max amount of tokens in the response. Default to
+	// DefaultMaxTokens, or the model's max output tokens if lower and known by
+	// the catalog.
 	MaxTokens int `json:"maxToken,omitempty" validate:"gte=0"`
 
+	// AllowUnpriced allows requests carrying a budget to use models whose
+	// prices are unknown. Set through WithUnpricedModels.
+	AllowUnpriced bool `json:"-"`
+
+	// Capabilities of the target provider, set by the provider itself. Used
+	// to validate the options in strict mode.
+	Capabilities *Capabilities `json:"-"`
+
+	// ContextLimit overrides the context window, in tokens, of the model.
+	// Default to the one in the catalog, or in the capabilities.
+	ContextLimit int `json:"contextLimit,omitempty" validate:"gte=0"`
+
+	// DisableTruncation disables the truncation of the conversation to fit
+	// the context window. Default to false.
+	DisableTruncation bool `json:"disableTruncation,omitempty"`
+
+	// History is the previous turns of the conversation, user, and assistant
+	// messages, oldest first. They are sent after the system messages, and
+	// before the user messages.
+	History []message.Message `json:"history,omitempty"`
+
+	// Extras are provider-specific options keyed by provider name. They are
+	// set through the providers' own helpers, e.g.: `ollama.WithExtra`.
+	// Providers ignore extras not meant for them.
+	Extras map[string]any `json:"extras,omitempty"`
+
+	// JSONMode constrains the response to valid JSON. Providers, and models
+	// which can't, see Capabilities.JSONMode, ignore it, or fail in strict
+	// mode.
+	//
+	// NOTE: Most models also need to be asked for JSON in the messages.
+	JSONMode bool `json:"jsonMode,omitempty"`
+
+	// Provider is the name of the target provider, set by the provider itself.
+	// Used to look up the model in the catalog.
+	Provider string `json:"provider,omitempty"`
+
 	// ResponseBody is the request body.
 	ResponseBody any `json:"requestBody"`
 
@@ -33,11 +93,35 @@ type Options struct {
 	// NOTE: Determinism is not guaranteed.
 	Seed int `json:"seed,omitempty" validate:"gte=0"`
 
+	// Strict makes the request fail if it uses features the target provider,
+	// or model can't honour, instead of silently dropping them. Default to
+	// false.
+	Strict bool `json:"strict,omitempty"`
+
+	// Stop sequences where the LLM will stop generating further tokens.
+	Stop []string `json:"stop,omitempty"`
+
 	// Stream determines if the response should be streamed or not. Default to
 	// false which means not to stream.
 	Stream bool `json:"stream"`
 
-	// SystemMessages is the system role messages.
+	// StreamHandler, if set, is called with each chunk of the streamed
+	// response. Set through WithStreamHandler.
+	StreamHandler StreamFunc `json:"-"`
+
+	// Template the messages were rendered from, if any. Set by
+	// `prompt.WithTemplate`, and recorded for observability.
+	Template *TemplateRef `json:"template,omitempty"`
+
+	// Summarizer, if set, summarizes the messages dropped to fit the context
+	// window, instead of dropping them.
+	Summarizer SummarizeFunc `json:"-"`
+
+	// SystemMessages is the system role messages. Every provider sends all of
+	// them, in order, before the history, and user messages: as separate
+	// system role messages (OpenAI, Ollama, HuggingFace), or as text blocks of
+	// the system prompt (Anthropic). They are never merged, nor dropped, not
+	// even to fit the context window.
 	SystemMessages []string `json:"systemMessages,omitempty"`
 
 	// Temperature to use, between 0 and 2. Higher values like 0.8 will make the
@@ -52,6 +136,10 @@ type Options struct {
 	// for sampling, default is not set (0) which means no restrictions.
 	TopK int `json:"topK,omitempty" validate:"gte=0"`
 
+	// Truncated, if set, is filled with what was removed to fit the context
+	// window.
+	Truncated *Truncated `json:"-"`
+
 	// TopP is an alternative to sampling with temperature, called nucleus
 	// sampling, where the model considers the results of the tokens with top_p
 	// probability mass. So 0.1 means only the tokens comprising the top 10%
@@ -61,6 +149,19 @@ type Options struct {
 	// NOTE: It's generally recommended altering this OR temperature but not
 	// both!
 	TopP float64 `json:"topP,omitempty" validate:"gte=0"`
+
+	// maxTokensDefaulted is true if MaxTokens was defaulted, not set.
+	maxTokensDefaulted bool
+}
+
+//////
+// Methods.
+//////
+
+// MaxTokensSet returns true if MaxTokens was set, not defaulted, e.g.: for
+// providers which prefer their own default.
+func (o *Options) MaxTokensSet() bool {
+	return !o.maxTokensDefaulted
 }
 
 //////
@@ -133,6 +234,24 @@ func WithTopP(topP float64) Func {
 	}
 }
 
+// WithStop sets the stop option.
+func WithStop(stop ...string) Func {
+	return func(o *Options) error {
+		o.Stop = append(o.Stop, stop...)
+
+		return nil
+	}
+}
+
+// WithJSONMode constrains the response to valid JSON.
+func WithJSONMode(enabled bool) Func {
+	return func(o *Options) error {
+		o.JSONMode = enabled
+
+		return nil
+	}
+}
+
 // WithStream sets the stream option.
 func WithStream(stream bool) Func {
 	return func(o *Options) error {
@@ -142,7 +261,22 @@ func WithStream(stream bool) Func {
 	}
 }
 
-// WithSystemMessages sets the systemMessages option.
+// WithStreamHandler streams the response, calling handler with each chunk.
+// Completion still returns the whole response. Models which can't stream,
+// see Capabilities.Streaming, reject it, even if not strict.
+func WithStreamHandler(handler StreamFunc) Func {
+	return func(o *Options) error {
+		if handler != nil {
+			o.Stream = true
+			o.StreamHandler = handler
+		}
+
+		return nil
+	}
+}
+
+// WithSystemMessages sets the systemMessages option, replacing the ones set
+// before. See Options.SystemMessages for how providers send them.
 func WithSystemMessages(systemMessage ...string) Func {
 	return func(o *Options) error {
 		if len(o.SystemMessages) > 0 {
@@ -170,6 +304,37 @@ func WithUserMessages(userMessage ...string) Func {
 	}
 }
 
+// WithHistory appends previous turns of the conversation, oldest first. Only
+// user, and assistant messages are allowed, set system messages with
+// WithSystemMessages.
+func WithHistory(messages ...message.Message) Func {
+	return func(o *Options) error {
+		for _, m := range messages {
+			if m.Role != message.User && m.Role != message.Assistant {
+				return customerror.NewInvalidError(
+					fmt.Sprintf("history message role %s, expected %s, or %s", m.Role, message.User, message.Assistant),
+				)
+			}
+		}
+
+		o.History = append(o.History, messages...)
+
+		return nil
+	}
+}
+
+// WithUsage sets the usage option. It's filled with the usage of the
+// completion.
+func WithUsage(usage *Usage) Func {
+	return func(o *Options) error {
+		if usage != nil {
+			o.Usage = usage
+		}
+
+		return nil
+	}
+}
+
 // WithResponseBody sets the responseBody option.
 func WithResponseBody(requestBody any) Func {
 	return func(o *Options) error {
@@ -181,16 +346,96 @@ func WithResponseBody(requestBody any) Func {
 	}
 }
 
+// WithStrict sets the strict option.
+func WithStrict(strict bool) Func {
+	return func(o *Options) error {
+		o.Strict = strict
+
+		return nil
+	}
+}
+
+// WithProvider sets the name of the target provider. Providers set it
+// themselves.
+func WithProvider(name string) Func {
+	return func(o *Options) error {
+		if name != "" {
+			o.Provider = name
+		}
+
+		return nil
+	}
+}
+
+// WithCapabilities sets the capabilities of the target provider. Providers
+// set it themselves.
+func WithCapabilities(capabilities Capabilities) Func {
+	return func(o *Options) error {
+		o.Capabilities = &capabilities
+
+		return nil
+	}
+}
+
+// WithExtra sets provider-specific options for the provider named
+// `providerName`. Providers define typed helpers on top of it.
+func WithExtra(providerName string, extra any) Func {
+	return func(o *Options) error {
+		if providerName == "" || extra == nil {
+			return nil
+		}
+
+		if o.Extras == nil {
+			o.Extras = make(map[string]any)
+		}
+
+		o.Extras[providerName] = extra
+
+		return nil
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// GetExtra returns the provider-specific options of type `T` set for the
+// provider named `providerName`, if any. It errors if the options set are not
+// of type `T`, e.g.: another provider's options were set under the name.
+func GetExtra[T any](o *Options, providerName string) (T, bool, error) {
+	var extra T
+
+	value, ok := o.Extras[providerName]
+	if !ok {
+		return extra, false, nil
+	}
+
+	extra, ok = value.(T)
+	if !ok {
+		return extra, false, customerror.NewInvalidError(
+			fmt.Sprintf("extra options for %s, got %T, expected %T", providerName, value, extra),
+		)
+	}
+
+	return extra, true, nil
+}
+
 //////
 // Factory.
 //////
 
 // NewOptionsFrom process, and validate against the default options.
 //
+// If the model is known by the catalog, it's used to default MaxTokens, and
+// in strict mode, to reject unknown, or deprecated models, and MaxTokens
+// above the model's max output tokens.
+//
+// It doesn't truncate the conversation to fit the context window: providers
+// do, once, when building the request, see Truncate.
+//
 //nolint:mnd,gomnd
 func NewOptionsFrom(options ...Func) (*Options, error) {
 	defaultOptions := Options{
-		MaxTokens:   4096,
 		Stream:      false,
 		Temperature: 0.7,
 	}
@@ -202,9 +447,73 @@ func NewOptionsFrom(options ...Func) (*Options, error) {
 		}
 	}
 
+	model, known := catalog.Get().Lookup(defaultOptions.Provider, defaultOptions.Model)
+
+	// MaxTokens defaulting.
+	if defaultOptions.MaxTokens == 0 {
+		defaultOptions.MaxTokens = DefaultMaxTokens
+		defaultOptions.maxTokensDefaulted = true
+
+		if known && model.MaxOutputTokens > 0 && model.MaxOutputTokens < DefaultMaxTokens {
+			defaultOptions.MaxTokens = model.MaxOutputTokens
+		}
+	}
+
 	if err := validation.Validate(&defaultOptions); err != nil {
 		return nil, err
 	}
 
+	// Even if not strict, the handler would never be called.
+	if defaultOptions.StreamHandler != nil &&
+		defaultOptions.Capabilities != nil &&
+		!defaultOptions.Capabilities.For(defaultOptions.Model).Streaming {
+		return nil, customerror.NewInvalidError("stream handler, " + defaultOptions.Model + " doesn't support streaming")
+	}
+
+	// Strict mode.
+	if defaultOptions.Strict {
+		if err := validateAgainstCatalog(&defaultOptions, model, known); err != nil {
+			return nil, err
+		}
+
+		if defaultOptions.Capabilities != nil {
+			if err := defaultOptions.Capabilities.For(defaultOptions.Model).Validate(&defaultOptions); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return &defaultOptions, nil
 }
+
+//////
+// Helpers.
+//////
+
+// validateAgainstCatalog validates the options against the model found in the
+// catalog. Providers unknown to the catalog are not validated.
+func validateAgainstCatalog(o *Options, model catalog.Model, known bool) error {
+	if o.Provider == "" || !catalog.Get().Has(o.Provider) {
+		return nil
+	}
+
+	if !known {
+		return customerror.NewInvalidError(
+			fmt.Sprintf("model %s, unknown to the %s catalog", o.Model, o.Provider),
+		)
+	}
+
+	if model.IsDeprecated(time.Now()) {
+		return customerror.NewInvalidError(
+			fmt.Sprintf("model %s, deprecated since %s", o.Model, model.DeprecatedAt),
+		)
+	}
+
+	if model.MaxOutputTokens > 0 && o.MaxTokens > model.MaxOutputTokens {
+		return customerror.NewInvalidError(
+			fmt.Sprintf("maxTokens, %s outputs at most %d tokens", o.Model, model.MaxOutputTokens),
+		)
+	}
+
+	return nil
+}
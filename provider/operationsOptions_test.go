@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+)
+
+func TestGetExtra(t *testing.T) {
+	type extra struct {
+		Value string
+	}
+
+	o, err := NewOptionsFrom(
+		WithModel("model"),
+		WithUserMessages("message"),
+		WithExtra("a", extra{Value: "a"}),
+		WithExtra("b", "b"),
+	)
+	assert.NoError(t, err)
+
+	a, ok, err := GetExtra[extra](o, "a")
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "a", a.Value)
+
+	_, ok, err = GetExtra[extra](o, "c")
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	_, ok, err = GetExtra[extra](o, "b")
+	assert.Error(t, err)
+	assert.False(t, ok)
+}
+
+func TestNewOptionsFrom_catalog(t *testing.T) {
+	tests := []struct {
+		name             string
+		options          []Func
+		wantMaxTokens    int
+		wantMaxTokensSet bool
+		wantErr          bool
+	}{
+		{
+			name:          "Unknown provider",
+			options:       []Func{WithProvider("custom"), WithModel("model")},
+			wantMaxTokens: DefaultMaxTokens,
+		},
+		{
+			name:          "Max output tokens lower than default",
+			options:       []Func{WithProvider("openai"), WithModel("gpt-4-turbo")},
+			wantMaxTokens: 4096,
+		},
+		{
+			name:             "Explicit max tokens",
+			options:          []Func{WithProvider("anthropic"), WithModel("claude-3-haiku-20240307"), WithMaxToken(1024)},
+			wantMaxTokens:    1024,
+			wantMaxTokensSet: true,
+		},
+		{
+			name:    "Unknown model, strict",
+			options: []Func{WithProvider("openai"), WithModel("gpt-unknown"), WithStrict(true)},
+			wantErr: true,
+		},
+		{
+			name:    "Deprecated model, strict",
+			options: []Func{WithProvider("anthropic"), WithModel("claude-3-sonnet-20240229"), WithStrict(true)},
+			wantErr: true,
+		},
+		{
+			name:    "MaxTokens above max output tokens, strict",
+			options: []Func{WithProvider("openai"), WithModel("gpt-4o"), WithMaxToken(32768), WithStrict(true)},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, err := NewOptionsFrom(append(tt.options, WithUserMessages("message"))...)
+			if tt.wantErr {
+				assert.Error(t, err)
+
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantMaxTokens, o.MaxTokens)
+			assert.Equal(t, tt.wantMaxTokensSet, o.MaxTokensSet())
+		})
+	}
+}
+
+func TestWithHistory(t *testing.T) {
+	o, err := NewOptionsFrom(
+		WithModel("model"),
+		WithHistory(
+			message.Message{Content: "hi", Role: message.User},
+			message.Message{Content: "hello", Role: message.Assistant},
+		),
+		WithUserMessages("how are you"),
+	)
+	assert.NoError(t, err)
+	assert.Len(t, o.History, 2)
+
+	_, err = NewOptionsFrom(
+		WithModel("model"),
+		WithHistory(message.Message{Content: "be brief", Role: message.System}),
+		WithUserMessages("how are you"),
+	)
+	assert.Error(t, err)
+}
@@ -1,13 +1,19 @@
 package provider
 
 import (
+	"context"
 	"expvar"
+	"net/http"
+	"time"
 
+	"github.com/thalesfsp/httpclient/v2"
 	"github.com/thalesfsp/inference/internal/metrics"
 	"github.com/thalesfsp/status"
 	"github.com/thalesfsp/sypl"
 	"github.com/thalesfsp/sypl/level"
 	"github.com/thalesfsp/validation"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
 )
 
 //////
@@ -28,9 +34,20 @@ type Provider struct {
 	// Name of the provider type.
 	Name string `json:"name" validate:"required,lowercase,gte=1"`
 
+	// Instance name, if set, see WithInstance.
+	Instance string `json:"instance,omitempty"`
+
 	// Metrics.
-	counterCompletion       *expvar.Int `json:"-" validate:"required,gte=0"`
-	counterCompletionFailed *expvar.Int `json:"-" validate:"required,gte=0"`
+	counterCompletion       *expvar.Int   `json:"-" validate:"required,gte=0"`
+	counterCompletionFailed *expvar.Int   `json:"-" validate:"required,gte=0"`
+	counterCost             *expvar.Float `json:"-" validate:"required,gte=0"`
+	counterInputTokens      *expvar.Int   `json:"-" validate:"required,gte=0"`
+	counterOutputTokens     *expvar.Int   `json:"-" validate:"required,gte=0"`
+
+	// OpenTelemetry.
+	histogramDuration metric.Float64Histogram `json:"-"`
+	histogramTokens   metric.Int64Histogram   `json:"-"`
+	tracer            trace.Tracer            `json:"-"`
 
 	// A provider may have the following...
 	// Endpoint to reach the provider.
@@ -41,12 +58,45 @@ type Provider struct {
 
 	// Token to authenticate against the provider.
 	Token string `json:"-"`
+
+	// Capabilities of the provider, and its models.
+	Capabilities Capabilities `json:"capabilities"`
+
+	// Retry of failed requests. Default to the HTTP client's.
+	Retry *Retry `json:"retry,omitempty"`
+
+	// Timeout of requests, excluding streamed ones. Default to the HTTP
+	// client's.
+	Timeout time.Duration `json:"timeout,omitempty"`
+
+	// Transport of the HTTP client. Default to the standard one.
+	Transport http.RoundTripper `json:"-"`
 }
 
 //////
 // Implements the IMeta interface.
 //////
 
+// GetCapabilities returns what the provider, and its models support.
+func (s *Provider) GetCapabilities() Capabilities {
+	return s.Capabilities
+}
+
+// GetDefaultModel returns the model used when none is set.
+func (s *Provider) GetDefaultModel() string {
+	return s.DefaultModel
+}
+
+// GetEndpoint returns the endpoint requests are sent to.
+func (s *Provider) GetEndpoint() string {
+	return s.Endpoint
+}
+
+// GetInstance returns the instance name, if set.
+func (s *Provider) GetInstance() string {
+	return s.Instance
+}
+
 // GetLogger returns the logger.
 func (s *Provider) GetLogger() sypl.ISypl {
 	return s.Logger
@@ -81,6 +131,135 @@ func (s *Provider) GetCounterCompletionFailed() *expvar.Int {
 	return s.counterCompletionFailed
 }
 
+// GetCounterCost returns the cost, in USD, metric.
+func (s *Provider) GetCounterCost() *expvar.Float {
+	return s.counterCost
+}
+
+// GetCounterInputTokens returns the input tokens metric.
+func (s *Provider) GetCounterInputTokens() *expvar.Int {
+	return s.counterInputTokens
+}
+
+// GetCounterOutputTokens returns the output tokens metric.
+func (s *Provider) GetCounterOutputTokens() *expvar.Int {
+	return s.counterOutputTokens
+}
+
+//////
+// HTTP client.
+//////
+
+// NewHTTPClient returns a HTTP client named after the provider, using its
+// retry, timeout, and transport, if set.
+func (s *Provider) NewHTTPClient() (*httpclient.Client, error) {
+	// The client publishes its metrics under its name, panicking on
+	// duplicates. It's created with a unique name, a UUID, then renamed, so
+	// many providers of the same name can be created.
+	client, err := httpclient.NewDefault(
+		httpclient.WithClientHeader("User-Agent", s.GetName()),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	client.SetName(s.GetName())
+
+	if s.Retry != nil {
+		client.RetrierBackoffTimes = s.Retry.Times
+
+		if s.Retry.Backoff > 0 {
+			client.RetrierBackoffDuration = s.Retry.Backoff
+		}
+	}
+
+	if s.Timeout > 0 {
+		client.Timeout = s.Timeout
+		client.GetClient().Timeout = s.Timeout
+	}
+
+	if s.Transport != nil {
+		client.GetClient().Transport = s.Transport
+	}
+
+	return client, nil
+}
+
+//////
+// Usage accounting.
+//////
+
+// Reserve reserves the estimated cost of the request (prompt size plus
+// MaxTokens) against the budget carried by ctx, if any, failing if it would
+// exceed it, or if the model is unpriced, see WithUnpricedModels. Call the
+// returned Settle with the actual usage once done. It accounts the usage in
+// the metrics, and in the options' Usage, if set.
+func (s *Provider) Reserve(ctx context.Context, o *Options) (Settle, error) {
+	var reserved float64
+
+	budget, hasBudget := BudgetFromContext(ctx)
+	if hasBudget {
+		// Otherwise, the budget would silently not be enforced.
+		if !o.AllowUnpriced && !Priced(o.Provider, o.Model) {
+			return nil, newUnpricedModelError(o.Model)
+		}
+
+		reserved = Cost(o.Provider, o.Model, EstimateTokens(o), o.MaxTokens)
+
+		if err := budget.reserve(reserved); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(usage Usage) {
+		usage.Cost = UsageCost(o.Provider, o.Model, usage)
+
+		if hasBudget {
+			budget.settle(reserved, usage.Cost)
+		}
+
+		s.GetCounterCost().Add(usage.Cost)
+		metricCost.Add(usage.Cost, s.GetName(), o.Model)
+		s.GetCounterInputTokens().Add(int64(usage.InputTokens))
+		s.GetCounterOutputTokens().Add(int64(usage.OutputTokens))
+
+		if o.Usage != nil {
+			*o.Usage = usage
+		}
+	}, nil
+}
+
+// setupCounters sets up the expvar counters, named as InstanceOf does, so
+// providers with the same name, but different instances don't share them.
+func (s *Provider) setupCounters() error {
+	name := s.Name
+	if s.Instance != "" {
+		name += "." + s.Instance
+	}
+
+	var err error
+
+	if s.counterCompletion, err = metrics.NewIntCounter(Type, name, "completion"); err != nil {
+		return err
+	}
+
+	if s.counterCompletionFailed, err = metrics.NewIntCounter(Type, name, "completion"+"."+status.Failed.String()); err != nil {
+		return err
+	}
+
+	if s.counterCost, err = metrics.NewFloatCounter(Type, name, "cost"); err != nil {
+		return err
+	}
+
+	if s.counterInputTokens, err = metrics.NewIntCounter(Type, name, "tokens.input"); err != nil {
+		return err
+	}
+
+	s.counterOutputTokens, err = metrics.NewIntCounter(Type, name, "tokens.output")
+
+	return err
+}
+
 //////
 // Factory.
 //////
@@ -116,11 +295,9 @@ func New(
 	logger := sypl.NewDefault(name, level.Error).SetTags(Type, name)
 
 	a := &Provider{
-		Logger: logger,
-		Name:   name,
-
-		counterCompletion:       metrics.NewIntCounter(Type, name, "completion"),
-		counterCompletionFailed: metrics.NewIntCounter(Type, name, "completion"+"."+status.Failed.String()),
+		Logger:   logger,
+		Name:     name,
+		Instance: defaultProviderOptions.Instance,
 
 		//////
 		// A provider may have the following...
@@ -128,7 +305,21 @@ func New(
 
 		Endpoint:     defaultProviderOptions.Endpoint,
 		DefaultModel: defaultProviderOptions.Model,
+		Retry:        defaultProviderOptions.Retry,
+		Timeout:      defaultProviderOptions.Timeout,
 		Token:        defaultProviderOptions.Token,
+		Transport:    defaultProviderOptions.Transport,
+	}
+
+	if err := a.setupCounters(); err != nil {
+		return nil, err
+	}
+
+	if err := a.setupTelemetry(
+		defaultProviderOptions.TracerProvider,
+		defaultProviderOptions.MeterProvider,
+	); err != nil {
+		return nil, err
 	}
 
 	// Validate the provider.
@@ -0,0 +1,86 @@
+package provider_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestProvider_NewHTTPClient(t *testing.T) {
+	var requests atomic.Int64
+
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer s.Close()
+
+	tests := []struct {
+		name         string
+		options      []provider.ClientFunc
+		wantRequests int64
+		wantTimeout  time.Duration
+	}{
+		{
+			name:         "retry disabled",
+			options:      []provider.ClientFunc{provider.WithRetry(0, 0), provider.WithTimeout(time.Minute)},
+			wantRequests: 1,
+			wantTimeout:  time.Minute,
+		},
+		{
+			name:         "retry",
+			options:      []provider.ClientFunc{provider.WithRetry(2, time.Millisecond)},
+			wantRequests: 3,
+			wantTimeout:  30 * time.Second,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			requests.Store(0)
+
+			p, err := provider.New("test", append(tt.options, provider.WithEndpoint(s.URL))...)
+			assert.NoError(t, err)
+
+			client, err := p.NewHTTPClient()
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantTimeout, client.GetClient().Timeout)
+
+			_, err = client.Post(context.Background(), s.URL)
+			assert.Error(t, err)
+			assert.Equal(t, tt.wantRequests, requests.Load())
+		})
+	}
+
+	_, err := provider.New("test", provider.WithEndpoint(s.URL), provider.WithRetry(-1, 0))
+	assert.Error(t, err)
+}
+
+func TestNew_instance(t *testing.T) {
+	a, err := provider.New("instance", provider.WithEndpoint("http://a"), provider.WithInstance("a"))
+	assert.NoError(t, err)
+
+	b, err := provider.New("instance", provider.WithEndpoint("http://b"), provider.WithInstance("b"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "a", a.GetInstance())
+	assert.NotSame(t, a.GetCounterCompletion(), b.GetCounterCompletion())
+	assert.NotSame(t, a.GetCounterCost(), b.GetCounterCost())
+
+	// Without an instance, counters are shared by name.
+	c, err := provider.New("instance", provider.WithEndpoint("http://c"))
+	assert.NoError(t, err)
+
+	d, err := provider.New("instance", provider.WithEndpoint("http://d"))
+	assert.NoError(t, err)
+
+	assert.Same(t, c.GetCounterCompletion(), d.GetCounterCompletion())
+	assert.NotSame(t, a.GetCounterCompletion(), c.GetCounterCompletion())
+}
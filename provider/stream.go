@@ -0,0 +1,259 @@
+package provider
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/httpclient/v2"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// maxEventSize is the maximum size of a server-sent event line.
+const maxEventSize = 1024 * 1024
+
+// EventFunc is called with the name, and the data of each server-sent event.
+// The name is empty if not set.
+type EventFunc func(event string, data []byte) error
+
+// ChatCompletionUsage is the usage of an OpenAI-compatible chat completion.
+type ChatCompletionUsage struct {
+	CompletionTokens int `json:"completion_tokens"`
+	PromptTokens     int `json:"prompt_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+// ChatCompletionStream is an OpenAI-compatible streamed chat completion,
+// assembled. Only the first choice is kept.
+type ChatCompletionStream struct {
+	Content      string
+	Created      int64
+	FinishReason string
+	ID           string
+	Model        string
+
+	// Usage, if sent, e.g.: `stream_options.include_usage` set.
+	Usage *ChatCompletionUsage
+}
+
+// chatCompletionChunk is a chunk of an OpenAI-compatible streamed chat
+// completion.
+type chatCompletionChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason string `json:"finish_reason"`
+		Index        int    `json:"index"`
+	} `json:"choices"`
+	Created int64                `json:"created"`
+	ID      string               `json:"id"`
+	Model   string               `json:"model"`
+	Usage   *ChatCompletionUsage `json:"usage,omitempty"`
+}
+
+//////
+// Exported functionalities.
+//////
+
+// PostStream posts the JSON request body to the URL, through the client's
+// transport, and returns the response, if successful, to be read as it
+// streams. Close its body.
+//
+// Failed requests, e.g.: rate limited, are retried as the client's, see
+// Provider.Retry, until the response starts streaming.
+//
+// NOTE: Streams may take a long time, so the client's timeout does not apply.
+// Use the context to control it.
+func PostStream(
+	ctx context.Context,
+	client *httpclient.Client,
+	url string,
+	headers map[string]string,
+	reqBody any,
+) (*http.Response, error) {
+	body, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, customerror.NewFailedToError("marshal request body", customerror.WithError(err))
+	}
+
+	// Same transport as the provider's client, but without timeout.
+	c := &http.Client{Transport: client.GetClient().Transport}
+
+	backoff := client.RetrierBackoffDuration
+
+	for attempt := 0; ; attempt++ {
+		resp, retryable, err := postStream(ctx, c, url, headers, body)
+		if err == nil {
+			return resp, nil
+		}
+
+		if !retryable || attempt >= client.RetrierBackoffTimes {
+			return nil, err
+		}
+
+		// Exponential, as the client's.
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+	}
+}
+
+// ReadEvents reads the server-sent events of r, calling fn with each, until
+// r ends, or the `[DONE]` data is received.
+func ReadEvents(r io.Reader, fn EventFunc) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxEventSize)
+
+	var event string
+
+	var data []string
+
+	dispatch := func() error {
+		defer func() { event, data = "", nil }()
+
+		if len(data) == 0 {
+			return nil
+		}
+
+		return fn(event, []byte(strings.Join(data, "\n")))
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment, e.g.: keep-alive.
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			value := strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+
+			if value == "[DONE]" {
+				return nil
+			}
+
+			data = append(data, value)
+		}
+	}
+
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return customerror.NewFailedToError("read the stream", customerror.WithError(err))
+	}
+
+	return dispatch()
+}
+
+// ReadChatCompletionStream reads the OpenAI-compatible streamed chat
+// completion of r, e.g.: OpenAI's, or HuggingFace's, calling the handler with
+// the content of each chunk, and returns it assembled.
+func ReadChatCompletionStream(r io.Reader, handler StreamFunc) (*ChatCompletionStream, error) {
+	stream := &ChatCompletionStream{}
+
+	var content strings.Builder
+
+	if err := ReadEvents(r, func(_ string, data []byte) error {
+		var chunk chatCompletionChunk
+
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return customerror.NewFailedToError("decode stream chunk", customerror.WithError(err))
+		}
+
+		stream.Created = chunk.Created
+		stream.ID = chunk.ID
+		stream.Model = chunk.Model
+
+		if chunk.Usage != nil {
+			stream.Usage = chunk.Usage
+		}
+
+		for _, c := range chunk.Choices {
+			if c.Index != 0 {
+				continue
+			}
+
+			if c.FinishReason != "" {
+				stream.FinishReason = c.FinishReason
+			}
+
+			if c.Delta.Content == "" {
+				continue
+			}
+
+			content.WriteString(c.Delta.Content)
+
+			if err := handler(c.Delta.Content); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}); err != nil {
+		return nil, err
+	}
+
+	stream.Content = content.String()
+
+	return stream, nil
+}
+
+//////
+// Helpers.
+//////
+
+// postStream posts the request once. Failures are retryable if due to the
+// network, or to a retryable status code, e.g.: `429`.
+func postStream(
+	ctx context.Context,
+	c *http.Client,
+	url string,
+	headers map[string]string,
+	body []byte,
+) (*http.Response, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, false, customerror.NewFailedToError("create request", customerror.WithError(err))
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, ctx.Err() == nil, httpclient.HandleHTTPResponseError(resp, url, "stream", err)
+	}
+
+	if !httpclient.IsRespSuccess(resp) {
+		defer resp.Body.Close()
+
+		retryable := resp.StatusCode == http.StatusRequestTimeout ||
+			resp.StatusCode == http.StatusTooManyRequests ||
+			resp.StatusCode >= http.StatusInternalServerError
+
+		return nil, retryable, httpclient.HandleHTTPResponseError(resp, url, "stream", nil)
+	}
+
+	return resp, false, nil
+}
@@ -0,0 +1,134 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadEvents(t *testing.T) {
+	type event struct {
+		data  string
+		event string
+	}
+
+	stream := ": keep-alive\n\n" +
+		"event: message_start\ndata: {\"a\":1}\n\n" +
+		"data: line 1\ndata: line 2\n\n" +
+		"data:no space\n\n" +
+		"data: [DONE]\n\n" +
+		"data: ignored\n\n"
+
+	events := []event{}
+
+	assert.NoError(t, ReadEvents(strings.NewReader(stream), func(name string, data []byte) error {
+		events = append(events, event{data: string(data), event: name})
+
+		return nil
+	}))
+
+	assert.Equal(t, []event{
+		{data: `{"a":1}`, event: "message_start"},
+		{data: "line 1\nline 2"},
+		{data: "no space"},
+	}, events)
+
+	// Without the trailing blank line.
+	events = events[:0]
+
+	assert.NoError(t, ReadEvents(strings.NewReader("data: last"), func(name string, data []byte) error {
+		events = append(events, event{data: string(data), event: name})
+
+		return nil
+	}))
+
+	assert.Equal(t, []event{{data: "last"}}, events)
+}
+
+func TestReadChatCompletionStream(t *testing.T) {
+	stream := `data: {"id":"a","created":1,"model":"m","choices":[{"index":0,"delta":{"role":"assistant"}}]}` + "\n\n" +
+		`data: {"id":"a","created":1,"model":"m","choices":[{"index":0,"delta":{"content":"po"}},{"index":1,"delta":{"content":"x"}}]}` + "\n\n" +
+		`data: {"id":"a","created":1,"model":"m","choices":[{"index":0,"delta":{"content":"ng"},"finish_reason":"length"}]}` + "\n\n" +
+		`data: {"id":"a","created":1,"model":"m","choices":[],"usage":{"completion_tokens":2,"prompt_tokens":3,"total_tokens":5}}` + "\n\n" +
+		"data: [DONE]\n\n"
+
+	chunks := []string{}
+
+	completion, err := ReadChatCompletionStream(strings.NewReader(stream), func(chunk string) error {
+		chunks = append(chunks, chunk)
+
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"po", "ng"}, chunks)
+	assert.Equal(t, &ChatCompletionStream{
+		Content:      "pong",
+		Created:      1,
+		FinishReason: FinishReasonLength,
+		ID:           "a",
+		Model:        "m",
+		Usage:        &ChatCompletionUsage{CompletionTokens: 2, PromptTokens: 3, TotalTokens: 5},
+	}, completion)
+
+	// Handler errors stop it.
+	_, err = ReadChatCompletionStream(strings.NewReader(stream), func(string) error {
+		return errors.New("client went away")
+	})
+	assert.ErrorContains(t, err, "client went away")
+}
+
+func TestPostStream(t *testing.T) {
+	var requests atomic.Int32
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		switch requests.Add(1) {
+		case 1:
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			_, _ = w.Write(body)
+		}
+	}))
+	defer server.Close()
+
+	newClient := func(times int) *Provider {
+		p, err := New("stream", WithEndpoint(server.URL), WithRetry(times, time.Millisecond))
+		assert.NoError(t, err)
+
+		return p
+	}
+
+	// Retried until it streams.
+	client, err := newClient(2).NewHTTPClient()
+	assert.NoError(t, err)
+
+	resp, err := PostStream(context.Background(), client, server.URL, nil, map[string]string{"a": "b"})
+	assert.NoError(t, err)
+
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	assert.JSONEq(t, `{"a":"b"}`, string(body))
+	assert.Equal(t, int32(3), requests.Load())
+
+	// Not retried.
+	requests.Store(0)
+
+	client, err = newClient(0).NewHTTPClient()
+	assert.NoError(t, err)
+
+	_, err = PostStream(context.Background(), client, server.URL, nil, struct{}{})
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), requests.Load())
+}
@@ -0,0 +1,339 @@
+package provider
+
+import (
+	"context"
+	"strconv"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/metric"
+	"go.opentelemetry.io/otel/trace"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// InstrumentationName is the name of the OpenTelemetry instrumentation scope.
+const InstrumentationName = "github.com/thalesfsp/inference"
+
+// OpenTelemetry GenAI semantic conventions attributes.
+//
+// SEE: https://opentelemetry.io/docs/specs/semconv/gen-ai/
+const (
+	AttrErrorType                 = attribute.Key("error.type")
+	AttrGenAIOperationName        = attribute.Key("gen_ai.operation.name")
+	AttrGenAIRequestMaxTokens     = attribute.Key("gen_ai.request.max_tokens")
+	AttrGenAIRequestModel         = attribute.Key("gen_ai.request.model")
+	AttrGenAIRequestSeed          = attribute.Key("gen_ai.request.seed")
+	AttrGenAIRequestStopSequences = attribute.Key("gen_ai.request.stop_sequences")
+	AttrGenAIRequestTemperature   = attribute.Key("gen_ai.request.temperature")
+	AttrGenAIRequestTopK          = attribute.Key("gen_ai.request.top_k")
+	AttrGenAIRequestTopP          = attribute.Key("gen_ai.request.top_p")
+	AttrGenAIResponseFinish       = attribute.Key("gen_ai.response.finish_reasons")
+	AttrGenAIResponseID           = attribute.Key("gen_ai.response.id")
+	AttrGenAIResponseModel        = attribute.Key("gen_ai.response.model")
+	AttrGenAISystem               = attribute.Key("gen_ai.system")
+	AttrGenAITokenType            = attribute.Key("gen_ai.token.type")
+	AttrGenAIUsageInputTokens     = attribute.Key("gen_ai.usage.input_tokens")
+	AttrGenAIUsageOutputTokens    = attribute.Key("gen_ai.usage.output_tokens")
+)
+
+// Attributes not covered by the OpenTelemetry GenAI semantic conventions.
+const (
+	AttrPromptTemplateName    = attribute.Key("inference.prompt.template.name")
+	AttrPromptTemplateVersion = attribute.Key("inference.prompt.template.version")
+)
+
+// OpenTelemetry GenAI semantic conventions metrics.
+const (
+	MetricGenAIOperationDuration = "gen_ai.client.operation.duration"
+	MetricGenAITokenUsage        = "gen_ai.client.token.usage"
+)
+
+// operationChat is the GenAI operation name of completions.
+const operationChat = "chat"
+
+// errorTypeOther is the error type used when none more specific is known.
+const errorTypeOther = "_OTHER"
+
+// Span traces, and measures a completion. Create it with StartSpan.
+type Span struct {
+	provider *Provider
+	span     trace.Span
+	start    time.Time
+
+	// Attributes shared by the metrics.
+	errorClass    string
+	errorType     string
+	requestModel  string
+	responseModel string
+	usage         *Usage
+}
+
+//////
+// Methods.
+//////
+
+// SetRequest sets what's known about the request, once its options are
+// processed.
+func (sp *Span) SetRequest(o *Options) {
+	sp.requestModel = o.Model
+
+	attrs := []attribute.KeyValue{
+		AttrGenAIRequestModel.String(o.Model),
+		AttrGenAIRequestMaxTokens.Int(o.MaxTokens),
+		AttrGenAIRequestTemperature.Float64(o.Temperature),
+	}
+
+	if o.Seed > 0 {
+		attrs = append(attrs, AttrGenAIRequestSeed.Int(o.Seed))
+	}
+
+	if len(o.Stop) > 0 {
+		attrs = append(attrs, AttrGenAIRequestStopSequences.StringSlice(o.Stop))
+	}
+
+	if o.TopK > 0 {
+		attrs = append(attrs, AttrGenAIRequestTopK.Int(o.TopK))
+	}
+
+	if o.TopP > 0 {
+		attrs = append(attrs, AttrGenAIRequestTopP.Float64(o.TopP))
+	}
+
+	if o.Template != nil {
+		attrs = append(
+			attrs,
+			AttrPromptTemplateName.String(o.Template.Name),
+			AttrPromptTemplateVersion.Int(o.Template.Version),
+		)
+	}
+
+	sp.span.SetName(spanName(o.Model))
+	sp.span.SetAttributes(attrs...)
+}
+
+// SetResponse sets what's known about the response.
+func (sp *Span) SetResponse(id, model string, finishReasons ...string) {
+	sp.responseModel = model
+
+	attrs := []attribute.KeyValue{}
+
+	if id != "" {
+		attrs = append(attrs, AttrGenAIResponseID.String(id))
+	}
+
+	if model != "" {
+		attrs = append(attrs, AttrGenAIResponseModel.String(model))
+	}
+
+	if len(finishReasons) > 0 {
+		attrs = append(attrs, AttrGenAIResponseFinish.StringSlice(finishReasons))
+	}
+
+	sp.span.SetAttributes(attrs...)
+}
+
+// SetUsage sets the usage of the completion.
+func (sp *Span) SetUsage(usage Usage) {
+	sp.usage = &usage
+
+	sp.span.SetAttributes(
+		AttrGenAIUsageInputTokens.Int(usage.InputTokens),
+		AttrGenAIUsageOutputTokens.Int(usage.OutputTokens),
+	)
+}
+
+// Fail records the error, and returns it.
+func (sp *Span) Fail(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	sp.errorClass = ErrorClass(err)
+	sp.errorType = errorType(err)
+
+	sp.span.RecordError(err)
+	sp.span.SetStatus(codes.Error, err.Error())
+	sp.span.SetAttributes(AttrErrorType.String(sp.errorType))
+
+	return err
+}
+
+// End ends the span, and records the metrics.
+func (sp *Span) End() {
+	defer sp.span.End()
+
+	sp.recordMetrics()
+
+	ctx := trace.ContextWithSpan(context.Background(), sp.span)
+
+	attrs := []attribute.KeyValue{
+		AttrGenAIOperationName.String(operationChat),
+		AttrGenAISystem.String(sp.provider.GetName()),
+		AttrGenAIRequestModel.String(sp.requestModel),
+	}
+
+	if sp.responseModel != "" {
+		attrs = append(attrs, AttrGenAIResponseModel.String(sp.responseModel))
+	}
+
+	if sp.errorType != "" {
+		sp.provider.histogramDuration.Record(
+			ctx,
+			time.Since(sp.start).Seconds(),
+			metric.WithAttributes(append(attrs, AttrErrorType.String(sp.errorType))...),
+		)
+
+		return
+	}
+
+	sp.provider.histogramDuration.Record(ctx, time.Since(sp.start).Seconds(), metric.WithAttributes(attrs...))
+
+	if sp.usage == nil {
+		return
+	}
+
+	sp.provider.histogramTokens.Record(
+		ctx,
+		int64(sp.usage.InputTokens),
+		metric.WithAttributes(append(attrs, AttrGenAITokenType.String("input"))...),
+	)
+
+	sp.provider.histogramTokens.Record(
+		ctx,
+		int64(sp.usage.OutputTokens),
+		metric.WithAttributes(append(attrs, AttrGenAITokenType.String("output"))...),
+	)
+}
+
+// recordMetrics records the Prometheus-compatible metrics.
+func (sp *Span) recordMetrics() {
+	name := sp.provider.GetName()
+	duration := time.Since(sp.start).Seconds()
+
+	status := metricStatusSucceeded
+	if sp.errorClass != "" {
+		status = metricStatusFailed
+	}
+
+	metricCompletions.Add(1, name, sp.requestModel, status, sp.errorClass)
+	metricCompletionDuration.Observe(duration, name, sp.requestModel, status, sp.errorClass)
+
+	if sp.usage == nil {
+		return
+	}
+
+	metricTokens.Observe(float64(sp.usage.InputTokens), name, sp.requestModel, "input")
+	metricTokens.Observe(float64(sp.usage.OutputTokens), name, sp.requestModel, "output")
+
+	if sp.usage.CacheCreationInputTokens > 0 {
+		metricTokens.Observe(float64(sp.usage.CacheCreationInputTokens), name, sp.requestModel, "cache_creation")
+	}
+
+	if sp.usage.CacheReadInputTokens > 0 {
+		metricTokens.Observe(float64(sp.usage.CacheReadInputTokens), name, sp.requestModel, "cache_read")
+	}
+}
+
+//////
+// Provider methods.
+//////
+
+// StartSpan starts tracing, and measuring a completion, before its options
+// are processed, so invalid requests are traced, and measured too. Its request
+// model is the default one, until SetRequest sets the processed options. Always
+// End it.
+func (s *Provider) StartSpan(ctx context.Context) (context.Context, *Span) {
+	ctx, span := s.tracer.Start(
+		ctx,
+		spanName(s.DefaultModel),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			AttrGenAIOperationName.String(operationChat),
+			AttrGenAISystem.String(s.GetName()),
+			AttrGenAIRequestModel.String(s.DefaultModel),
+		),
+	)
+
+	return ctx, &Span{
+		provider:     s,
+		requestModel: s.DefaultModel,
+		span:         span,
+		start:        time.Now(),
+	}
+}
+
+//////
+// Helpers.
+//////
+
+// spanName returns the name of the span of a completion with the model.
+func spanName(model string) string {
+	if model == "" {
+		return operationChat
+	}
+
+	return operationChat + " " + model
+}
+
+// errorType returns a low-cardinality type of the error.
+func errorType(err error) string {
+	cE, ok := customerror.To(err)
+	if !ok {
+		return errorTypeOther
+	}
+
+	if cE.Code != "" {
+		return cE.Code
+	}
+
+	if cE.StatusCode > 0 {
+		return strconv.Itoa(cE.StatusCode)
+	}
+
+	return errorTypeOther
+}
+
+// setupTelemetry sets up the tracer, and the metric instruments. Defaults to
+// the global OpenTelemetry providers, which are no-op unless set.
+func (s *Provider) setupTelemetry(tp trace.TracerProvider, mp metric.MeterProvider) error {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+
+	if mp == nil {
+		mp = otel.GetMeterProvider()
+	}
+
+	s.tracer = tp.Tracer(InstrumentationName)
+
+	meter := mp.Meter(InstrumentationName)
+
+	histogramDuration, err := meter.Float64Histogram(
+		MetricGenAIOperationDuration,
+		metric.WithDescription("GenAI operation duration."),
+		metric.WithUnit("s"),
+	)
+	if err != nil {
+		return customerror.NewFailedToError("create duration histogram", customerror.WithError(err))
+	}
+
+	histogramTokens, err := meter.Int64Histogram(
+		MetricGenAITokenUsage,
+		metric.WithDescription("Measures number of input and output tokens used."),
+		metric.WithUnit("{token}"),
+	)
+	if err != nil {
+		return customerror.NewFailedToError("create token histogram", customerror.WithError(err))
+	}
+
+	s.histogramDuration = histogramDuration
+	s.histogramTokens = histogramTokens
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/customerror"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestStartSpan(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	reader := sdkmetric.NewManualReader()
+
+	p, err := New(
+		"telemetry",
+		WithEndpoint("http://localhost"),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+		WithMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader))),
+	)
+	assert.NoError(t, err)
+
+	o, err := NewOptionsFrom(
+		WithModel("gpt-4o"),
+		WithMaxToken(100),
+		WithUserMessages("why is the sky blue"),
+	)
+	assert.NoError(t, err)
+
+	// Succeeded completion.
+	_, span := p.StartSpan(context.Background())
+	span.SetRequest(o)
+	span.SetResponse("chatcmpl-1", "gpt-4o-2024-08-06", "stop")
+	span.SetUsage(Usage{InputTokens: 10, OutputTokens: 20})
+	span.End()
+
+	// Failed completion.
+	_, span = p.StartSpan(context.Background())
+	span.SetRequest(o)
+	assert.Error(t, span.Fail(errors.New("boom")))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 2)
+
+	assert.Equal(t, "chat gpt-4o", spans[0].Name())
+	assert.Contains(t, spans[0].Attributes(), AttrGenAISystem.String("telemetry"))
+	assert.Contains(t, spans[0].Attributes(), AttrGenAIRequestModel.String("gpt-4o"))
+	assert.Contains(t, spans[0].Attributes(), AttrGenAIRequestMaxTokens.Int(100))
+	assert.Contains(t, spans[0].Attributes(), AttrGenAIResponseFinish.StringSlice([]string{"stop"}))
+	assert.Contains(t, spans[0].Attributes(), AttrGenAIUsageInputTokens.Int(10))
+	assert.Contains(t, spans[0].Attributes(), AttrGenAIUsageOutputTokens.Int(20))
+
+	assert.Equal(t, codes.Error, spans[1].Status().Code)
+	assert.Contains(t, spans[1].Attributes(), AttrErrorType.String(errorTypeOther))
+
+	var rm metricdata.ResourceMetrics
+
+	assert.NoError(t, reader.Collect(context.Background(), &rm))
+
+	histograms := map[string]int{}
+
+	for _, sm := range rm.ScopeMetrics {
+		for _, m := range sm.Metrics {
+			switch data := m.Data.(type) {
+			case metricdata.Histogram[float64]:
+				for _, dp := range data.DataPoints {
+					histograms[m.Name] += int(dp.Count)
+				}
+			case metricdata.Histogram[int64]:
+				for _, dp := range data.DataPoints {
+					histograms[m.Name] += int(dp.Count)
+
+					if v, ok := dp.Attributes.Value(AttrGenAITokenType); ok && v == attribute.StringValue("output") {
+						assert.Equal(t, int64(20), dp.Sum)
+					}
+				}
+			}
+		}
+	}
+
+	assert.Equal(t, 2, histograms[MetricGenAIOperationDuration])
+	assert.Equal(t, 2, histograms[MetricGenAITokenUsage])
+}
+
+func TestStartSpan_invalidRequest(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+
+	p, err := New(
+		"telemetry",
+		WithEndpoint("http://localhost"),
+		WithDefaulModel("gpt-4o"),
+		WithTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))),
+	)
+	assert.NoError(t, err)
+
+	invalid := customerror.NewInvalidError("temperature")
+
+	// Failed before the options are processed.
+	_, span := p.StartSpan(context.Background())
+	assert.Error(t, span.Fail(invalid))
+	span.End()
+
+	spans := recorder.Ended()
+	assert.Len(t, spans, 1)
+
+	assert.Equal(t, "chat gpt-4o", spans[0].Name())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Contains(t, spans[0].Attributes(), AttrGenAIRequestModel.String("gpt-4o"))
+	assert.Equal(t, 1.0, metricCompletions.Value("telemetry", "gpt-4o", metricStatusFailed, ErrorClass(invalid)))
+}
@@ -0,0 +1,252 @@
+package provider
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/catalog"
+	"github.com/thalesfsp/inference/message"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// SummaryPrefix prefixes summaries of the earlier conversation, sent as a
+// system message.
+const SummaryPrefix = "Summary of the earlier conversation: "
+
+// CountTokensFunc counts the tokens of the messages, as sent to the model.
+type CountTokensFunc func(model string, messages []message.Message) (int, error)
+
+// SummarizeFunc summarizes the dropped messages, oldest first. The summary
+// replaces them. `ctx` is the one of the request.
+type SummarizeFunc func(ctx context.Context, dropped []message.Message) (string, error)
+
+// Truncated reports what was removed to fit the context window.
+type Truncated struct {
+	// Dropped messages, oldest first.
+	Dropped []message.Message `json:"dropped,omitempty"`
+
+	// Limit of input tokens: the context window minus MaxTokens.
+	Limit int `json:"limit"`
+
+	// Summary replacing the dropped messages, if summarized.
+	Summary string `json:"summary,omitempty"`
+
+	// TokensAfter is the amount of input tokens after truncation.
+	TokensAfter int `json:"tokensAfter"`
+
+	// TokensBefore is the amount of input tokens before truncation.
+	TokensBefore int `json:"tokensBefore"`
+}
+
+// Token counter.
+var (
+	tokenCounterMu sync.RWMutex
+	tokenCounter   CountTokensFunc = EstimateMessagesTokens
+)
+
+//////
+// Exported built-in options.
+//////
+
+// WithContextLimit overrides the context window, in tokens, of the model.
+// Default to the one in the catalog, or in the capabilities.
+func WithContextLimit(limit int) Func {
+	return func(o *Options) error {
+		if limit > 0 {
+			o.ContextLimit = limit
+		}
+
+		return nil
+	}
+}
+
+// WithTruncation enables, or disables the truncation of the conversation to
+// fit the context window. Default to enabled.
+func WithTruncation(enabled bool) Func {
+	return func(o *Options) error {
+		o.DisableTruncation = !enabled
+
+		return nil
+	}
+}
+
+// WithSummarizer summarizes the dropped messages, instead of dropping them.
+func WithSummarizer(summarize SummarizeFunc) Func {
+	return func(o *Options) error {
+		if summarize != nil {
+			o.Summarizer = summarize
+		}
+
+		return nil
+	}
+}
+
+// WithTruncated sets the truncated option. It's filled with what was removed
+// to fit the context window, if anything.
+func WithTruncated(truncated *Truncated) Func {
+	return func(o *Options) error {
+		if truncated != nil {
+			o.Truncated = truncated
+		}
+
+		return nil
+	}
+}
+
+//////
+// Exported functionalities.
+//////
+
+// EstimateMessagesTokens roughly estimates the amount of tokens of the
+// messages, the same way as EstimateTokens. It's the default token counter.
+func EstimateMessagesTokens(_ string, messages []message.Message) (int, error) {
+	tokens := 0
+
+	for _, m := range messages {
+		tokens += EstimateTextTokens(m.Content) + messageOverheadTokens
+	}
+
+	return tokens, nil
+}
+
+// GetTokenCounter returns the token counter used by truncation.
+func GetTokenCounter() CountTokensFunc {
+	tokenCounterMu.RLock()
+	defer tokenCounterMu.RUnlock()
+
+	return tokenCounter
+}
+
+// SetTokenCounter sets the token counter used by truncation. Default to
+// EstimateMessagesTokens.
+func SetTokenCounter(count CountTokensFunc) {
+	tokenCounterMu.Lock()
+	defer tokenCounterMu.Unlock()
+
+	if count == nil {
+		count = EstimateMessagesTokens
+	}
+
+	tokenCounter = count
+}
+
+// ContextLimit returns the context window, in tokens, of the model of the
+// options: the overridden one, or the one in the catalog, or in the
+// capabilities. Zero means unknown.
+func ContextLimit(o *Options) int {
+	if o.ContextLimit > 0 {
+		return o.ContextLimit
+	}
+
+	if model, ok := catalog.Get().Lookup(o.Provider, o.Model); ok && model.ContextWindow > 0 {
+		return model.ContextWindow
+	}
+
+	if o.Capabilities != nil {
+		return o.Capabilities.For(o.Model).MaxContext
+	}
+
+	return 0
+}
+
+// Truncate drops, or summarizes the oldest non-system messages, history first,
+// until the conversation fits the context window minus MaxTokens. System
+// messages, and the latest user message are always kept. If they alone don't
+// fit, it errors in strict mode, otherwise the provider decides.
+//
+// Providers call it once, when building the request, after NewOptionsFrom:
+// summarizing is a billed call, which processing the options must not do.
+func Truncate(ctx context.Context, o *Options) error {
+	if o.DisableTruncation {
+		return nil
+	}
+
+	contextLimit := ContextLimit(o)
+	if contextLimit == 0 {
+		return nil
+	}
+
+	limit := contextLimit - o.MaxTokens
+
+	system := message.NewMessages(o.SystemMessages, nil)
+	conversation := message.NewConversation(nil, o.History, o.UserMessages)
+
+	count := GetTokenCounter()
+
+	systemTokens, err := count(o.Model, system)
+	if err != nil {
+		return customerror.NewFailedToError("count tokens", customerror.WithError(err))
+	}
+
+	tokens := make([]int, len(conversation))
+	total := systemTokens
+
+	for i, m := range conversation {
+		if tokens[i], err = count(o.Model, []message.Message{m}); err != nil {
+			return customerror.NewFailedToError("count tokens", customerror.WithError(err))
+		}
+
+		total += tokens[i]
+	}
+
+	if total <= limit {
+		return nil
+	}
+
+	report := Truncated{Limit: limit, TokensBefore: total}
+
+	// Drop the oldest, keeping the latest.
+	dropped := 0
+
+	for dropped < len(conversation)-1 && total > limit {
+		total -= tokens[dropped]
+		dropped++
+	}
+
+	report.Dropped = conversation[:dropped]
+
+	if dropped > 0 && o.Summarizer != nil {
+		summary, err := o.Summarizer(ctx, report.Dropped)
+		if err != nil {
+			return customerror.NewFailedToError("summarize dropped messages", customerror.WithError(err))
+		}
+
+		summaryTokens, err := count(o.Model, message.NewMessages([]string{SummaryPrefix + summary}, nil))
+		if err != nil {
+			return customerror.NewFailedToError("count tokens", customerror.WithError(err))
+		}
+
+		// A summary which doesn't fit is dropped too.
+		if summary != "" && total+summaryTokens <= limit {
+			o.SystemMessages = append(o.SystemMessages, SummaryPrefix+summary)
+
+			report.Summary = summary
+			total += summaryTokens
+		}
+	}
+
+	report.TokensAfter = total
+
+	// History first, it's older than the user messages.
+	fromHistory := min(dropped, len(o.History))
+
+	o.History = o.History[fromHistory:]
+	o.UserMessages = o.UserMessages[dropped-fromHistory:]
+
+	if o.Truncated != nil {
+		*o.Truncated = report
+	}
+
+	if total > limit && o.Strict {
+		return customerror.NewInvalidError(
+			fmt.Sprintf("messages, %d tokens exceed the %d tokens available in the context window of %s", total, limit, o.Model),
+		)
+	}
+
+	return nil
+}
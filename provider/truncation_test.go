@@ -0,0 +1,152 @@
+package provider
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+)
+
+func TestTruncate(t *testing.T) {
+	// Each turn is 27 + 4 tokens, the latest, and the system message are 2 + 4.
+	turn := strings.Repeat("a", 100)
+
+	options := func(extra ...Func) []Func {
+		return append([]Func{
+			WithProvider("custom"),
+			WithModel("model"),
+			WithMaxToken(100),
+			WithSystemMessages("be brief"),
+			WithUserMessages("first "+turn, "second "+turn, "third "+turn, "latest"),
+		}, extra...)
+	}
+
+	// Processes, then truncates the options, as providers do.
+	build := func(options ...Func) (*Options, error) {
+		o, err := NewOptionsFrom(options...)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := Truncate(context.Background(), o); err != nil {
+			return nil, err
+		}
+
+		return o, nil
+	}
+
+	t.Run("Unknown context window", func(t *testing.T) {
+		o, err := build(options()...)
+		assert.NoError(t, err)
+		assert.Len(t, o.UserMessages, 4)
+	})
+
+	t.Run("Fits", func(t *testing.T) {
+		var truncated Truncated
+
+		o, err := build(options(WithContextLimit(1000), WithTruncated(&truncated))...)
+		assert.NoError(t, err)
+		assert.Len(t, o.UserMessages, 4)
+		assert.Empty(t, truncated.Dropped)
+	})
+
+	t.Run("Drops the oldest", func(t *testing.T) {
+		var truncated Truncated
+
+		o, err := build(options(WithContextLimit(160), WithTruncated(&truncated))...)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"be brief"}, o.SystemMessages)
+		assert.Equal(t, []string{"third " + turn, "latest"}, o.UserMessages)
+
+		assert.Equal(t, 60, truncated.Limit)
+		assert.Len(t, truncated.Dropped, 2)
+		assert.Equal(t, message.User, truncated.Dropped[0].Role)
+		assert.True(t, strings.HasPrefix(truncated.Dropped[0].Content, "first"))
+		assert.Greater(t, truncated.TokensBefore, truncated.Limit)
+		assert.LessOrEqual(t, truncated.TokensAfter, truncated.Limit)
+	})
+
+	t.Run("Summarizes the dropped", func(t *testing.T) {
+		var truncated Truncated
+
+		o, err := build(options(
+			WithContextLimit(160),
+			WithTruncated(&truncated),
+			WithSummarizer(func(_ context.Context, _ []message.Message) (string, error) {
+				return "two turns", nil
+			}),
+		)...)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"be brief", SummaryPrefix + "two turns"}, o.SystemMessages)
+		assert.Equal(t, "two turns", truncated.Summary)
+		assert.LessOrEqual(t, truncated.TokensAfter, truncated.Limit)
+	})
+
+	t.Run("Processing the options doesn't truncate", func(t *testing.T) {
+		calls := 0
+
+		o, err := NewOptionsFrom(options(
+			WithContextLimit(160),
+			WithSummarizer(func(_ context.Context, _ []message.Message) (string, error) {
+				calls++
+
+				return "two turns", nil
+			}),
+		)...)
+		assert.NoError(t, err)
+		assert.Len(t, o.UserMessages, 4)
+		assert.Equal(t, 0, calls)
+	})
+
+	t.Run("Keeps the latest", func(t *testing.T) {
+		o, err := build(options(WithContextLimit(115))...)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"latest"}, o.UserMessages)
+
+		_, err = build(options(WithContextLimit(105), WithStrict(true))...)
+		assert.ErrorContains(t, err, "exceed")
+	})
+
+	t.Run("History first", func(t *testing.T) {
+		o, err := build(
+			WithProvider("custom"),
+			WithModel("model"),
+			WithMaxToken(100),
+			WithContextLimit(170),
+			WithHistory(
+				message.Message{Content: "first " + turn, Role: message.User},
+				message.Message{Content: "second " + turn, Role: message.Assistant},
+			),
+			WithUserMessages("third "+turn, "latest"),
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, []message.Message{{Content: "second " + turn, Role: message.Assistant}}, o.History)
+		assert.Equal(t, []string{"third " + turn, "latest"}, o.UserMessages)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		o, err := build(options(WithContextLimit(160), WithTruncation(false))...)
+		assert.NoError(t, err)
+		assert.Len(t, o.UserMessages, 4)
+	})
+
+	t.Run("Capabilities", func(t *testing.T) {
+		o, err := build(options(WithCapabilities(Capabilities{MaxContext: 160}))...)
+		assert.NoError(t, err)
+		assert.Len(t, o.UserMessages, 2)
+	})
+
+	t.Run("Token counter", func(t *testing.T) {
+		defer SetTokenCounter(nil)
+
+		SetTokenCounter(func(_ string, messages []message.Message) (int, error) {
+			return 60 * len(messages), nil
+		})
+
+		o, err := build(options(WithContextLimit(350))...)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"second " + turn, "third " + turn, "latest"}, o.UserMessages)
+	})
+}
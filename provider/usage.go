@@ -0,0 +1,127 @@
+package provider
+
+import (
+	"github.com/thalesfsp/inference/catalog"
+)
+
+//////
+// Vars, consts, and types.
+//////
+
+// Finish reasons, why the model stopped generating, in the OpenAI vocabulary.
+const (
+	// FinishReasonContentFilter means the response was filtered.
+	FinishReasonContentFilter = "content_filter"
+
+	// FinishReasonLength means MaxTokens was reached.
+	FinishReasonLength = "length"
+
+	// FinishReasonStop means the model ended, or a stop sequence was
+	// generated.
+	FinishReasonStop = "stop"
+
+	// FinishReasonToolCalls means the model called tools.
+	FinishReasonToolCalls = "tool_calls"
+)
+
+// Usage of a completion.
+type Usage struct {
+	// CacheCreationInputTokens is the amount of input tokens written to the
+	// prompt cache, part of InputTokens.
+	CacheCreationInputTokens int `json:"cacheCreationInputTokens,omitempty"`
+
+	// CacheReadInputTokens is the amount of input tokens read from the prompt
+	// cache, part of InputTokens.
+	CacheReadInputTokens int `json:"cacheReadInputTokens,omitempty"`
+
+	// Cost, in USD, of the completion. Zero if the model prices are unknown
+	// by the catalog.
+	Cost float64 `json:"cost"`
+
+	// FinishReason is why the model stopped generating, e.g.:
+	// FinishReasonLength. Empty if unknown.
+	FinishReason string `json:"finishReason,omitempty"`
+
+	// InputTokens is the amount of tokens in the prompt, including the ones
+	// written to, and read from the prompt cache.
+	InputTokens int `json:"inputTokens"`
+
+	// OutputTokens is the amount of tokens in the completion.
+	OutputTokens int `json:"outputTokens"`
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Cost returns the cost, in USD, of the amount of input, and output tokens
+// for the model of the provider. Zero if the model prices are unknown by the
+// catalog.
+func Cost(providerName, model string, inputTokens, outputTokens int) float64 {
+	m, ok := catalog.Get().Lookup(providerName, model)
+	if !ok {
+		return 0
+	}
+
+	return m.Cost(inputTokens, outputTokens)
+}
+
+// Priced returns true if the prices of the model of the provider are known by
+// the catalog.
+func Priced(providerName, model string) bool {
+	m, ok := catalog.Get().Lookup(providerName, model)
+
+	return ok && (m.InputPrice > 0 || m.OutputPrice > 0)
+}
+
+// UsageCost returns the cost, in USD, of the usage for the model of the
+// provider, pricing the input tokens written to, and read from the prompt
+// cache accordingly. Zero if the model prices are unknown by the catalog.
+func UsageCost(providerName, model string, usage Usage) float64 {
+	m, ok := catalog.Get().Lookup(providerName, model)
+	if !ok {
+		return 0
+	}
+
+	cached := usage.CacheCreationInputTokens + usage.CacheReadInputTokens
+
+	return m.Cost(max(0, usage.InputTokens-cached), usage.OutputTokens) +
+		m.CacheCost(usage.CacheCreationInputTokens, usage.CacheReadInputTokens)
+}
+
+// EstimateTokens roughly estimates the amount of input tokens of the options'
+// messages, without calling any API.
+func EstimateTokens(o *Options) int {
+	tokens := 0
+
+	for _, messages := range [][]string{o.SystemMessages, o.UserMessages} {
+		for _, m := range messages {
+			tokens += EstimateTextTokens(m) + messageOverheadTokens
+		}
+	}
+
+	for _, m := range o.History {
+		tokens += EstimateTextTokens(m.Content) + messageOverheadTokens
+	}
+
+	return tokens
+}
+
+// EstimateTextTokens roughly estimates the amount of tokens in text, assuming
+// ~4 characters per token.
+func EstimateTextTokens(text string) int {
+	return (len([]rune(text)) + charsPerToken - 1) / charsPerToken
+}
+
+//////
+// Helpers.
+//////
+
+const (
+	// charsPerToken is the average amount of characters per token.
+	charsPerToken = 4
+
+	// messageOverheadTokens is the amount of tokens added per message, e.g.:
+	// role, and separators.
+	messageOverheadTokens = 4
+)
@@ -0,0 +1,20 @@
+// Package providertest provides test doubles for code using providers: Mock,
+// a scriptable provider.IProvider, and Server, a fake API server speaking the
+// wire format of each supported vendor.
+//
+// Mock is meant to be set as the provider, e.g.:
+//
+//	m, _ := providertest.New(providertest.WithResponses(
+//		providertest.Response{Content: "pong"},
+//	))
+//
+//	openai.Set(m)
+//
+// Server is meant to test the real providers, without reaching the network,
+// e.g.:
+//
+//	s := providertest.NewServer(providertest.OpenAI, providertest.Reply{Content: "pong"})
+//	defer s.Close()
+//
+//	p, _ := openai.New(provider.WithEndpoint(s.Endpoint()), provider.WithToken("test"))
+package providertest
@@ -0,0 +1,491 @@
+package providertest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Defaults of the mock.
+const (
+	DefaultModel = "mock-model"
+	DefaultName  = "mock"
+)
+
+// Response is a scripted response.
+type Response struct {
+	// Content of the response. Defaults to the concatenated chunks.
+	Content string
+
+	// Chunks sent, in order, to the stream handler, if streaming. Defaults to
+	// the content, as a single chunk.
+	Chunks []string
+
+	// ChunkDelay is the delay before each chunk.
+	ChunkDelay time.Duration
+
+	// Err, if set, is returned instead of the response.
+	Err error
+
+	// Expect, if set, asserts on the received options. Its error is returned
+	// instead of the response.
+	Expect func(o *provider.Options) error
+
+	// Latency before responding. Overrides the mock's.
+	Latency time.Duration
+
+	// Usage of the response.
+	Usage provider.Usage
+}
+
+// Call is a received call.
+type Call struct {
+	// Duration of the call.
+	Duration time.Duration
+
+	// Err returned, if any.
+	Err error
+
+	// Options received, processed.
+	Options *provider.Options
+
+	// Response returned.
+	Response string
+}
+
+// Func allows to set mock options.
+type Func func(m *Mock) error
+
+// Mock is a scriptable provider. Each call consumes the next scripted
+// response, or the fallback, once exhausted. It's safe for concurrent use.
+type Mock struct {
+	*provider.Provider
+
+	latency time.Duration
+
+	mu        sync.Mutex
+	calls     []Call
+	fallback  *Response
+	responses []Response
+}
+
+//////
+// Exported built-in options.
+//////
+
+// WithName sets the provider name. Default to DefaultName.
+func WithName(name string) Func {
+	return func(m *Mock) error {
+		if name != "" {
+			m.Name = name
+		}
+
+		return nil
+	}
+}
+
+// WithModel sets the default model. Default to DefaultModel.
+func WithModel(model string) Func {
+	return func(m *Mock) error {
+		if model != "" {
+			m.DefaultModel = model
+		}
+
+		return nil
+	}
+}
+
+// WithCapabilities sets the capabilities. Default to all.
+func WithCapabilities(capabilities provider.Capabilities) Func {
+	return func(m *Mock) error {
+		m.Capabilities = capabilities
+
+		return nil
+	}
+}
+
+// WithLatency sets the latency before responding.
+func WithLatency(latency time.Duration) Func {
+	return func(m *Mock) error {
+		m.latency = latency
+
+		return nil
+	}
+}
+
+// WithResponses scripts the responses.
+func WithResponses(responses ...Response) Func {
+	return func(m *Mock) error {
+		m.responses = append(m.responses, responses...)
+
+		return nil
+	}
+}
+
+// WithFallback sets the response used once the scripted ones are exhausted.
+// Without it, calls then fail.
+func WithFallback(response Response) Func {
+	return func(m *Mock) error {
+		m.fallback = &response
+
+		return nil
+	}
+}
+
+//////
+// Expectations.
+//////
+
+// ExpectModel asserts the model.
+func ExpectModel(model string) func(o *provider.Options) error {
+	return func(o *provider.Options) error {
+		if o.Model != model {
+			return unexpected("model", model, o.Model)
+		}
+
+		return nil
+	}
+}
+
+// ExpectSystemMessages asserts the system messages.
+func ExpectSystemMessages(messages ...string) func(o *provider.Options) error {
+	return func(o *provider.Options) error {
+		if !slices.Equal(o.SystemMessages, messages) {
+			return unexpected("system messages", messages, o.SystemMessages)
+		}
+
+		return nil
+	}
+}
+
+// ExpectUserMessages asserts the user messages.
+func ExpectUserMessages(messages ...string) func(o *provider.Options) error {
+	return func(o *provider.Options) error {
+		if !slices.Equal(o.UserMessages, messages) {
+			return unexpected("user messages", messages, o.UserMessages)
+		}
+
+		return nil
+	}
+}
+
+// ExpectHistory asserts the history.
+func ExpectHistory(messages ...message.Message) func(o *provider.Options) error {
+	return func(o *provider.Options) error {
+		if !slices.Equal(o.History, messages) {
+			return unexpected("history", messages, o.History)
+		}
+
+		return nil
+	}
+}
+
+// ExpectAll asserts all expectations.
+func ExpectAll(expectations ...func(o *provider.Options) error) func(o *provider.Options) error {
+	return func(o *provider.Options) error {
+		for _, expect := range expectations {
+			if err := expect(o); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+//////
+// Implements the IProvider interface.
+//////
+
+// Completion returns the next scripted response. Options are processed like
+// real providers do, and it goes through the same tracing, budget, and usage
+// accounting.
+func (m *Mock) Completion(ctx context.Context, options ...provider.Func) (string, error) {
+	start := time.Now()
+
+	// Started first, so invalid requests are traced, and measured too, as by
+	// the built-in providers.
+	ctx, span := m.StartSpan(ctx)
+	defer span.End()
+
+	o, err := provider.NewOptionsFrom(append(
+		[]provider.Func{
+			provider.WithProvider(m.GetName()),
+			provider.WithModel(m.DefaultModel),
+			provider.WithCapabilities(m.GetCapabilities()),
+		},
+		options...,
+	)...)
+	if err != nil {
+		m.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	if err := provider.Truncate(ctx, o); err != nil {
+		m.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	span.SetRequest(o)
+
+	response, err := m.complete(ctx, span, o)
+
+	m.mu.Lock()
+	m.calls = append(m.calls, Call{
+		Duration: time.Since(start),
+		Err:      err,
+		Options:  o,
+		Response: response,
+	})
+	m.mu.Unlock()
+
+	if err != nil {
+		return "", err
+	}
+
+	return response, nil
+}
+
+// GetClient returns the client. The mock has none.
+func (m *Mock) GetClient() any {
+	return nil
+}
+
+//////
+// Methods.
+//////
+
+// Enqueue scripts more responses.
+func (m *Mock) Enqueue(responses ...Response) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.responses = append(m.responses, responses...)
+}
+
+// Calls returns the received calls, in order.
+func (m *Mock) Calls() []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return slices.Clone(m.calls)
+}
+
+// LastCall returns the last received call, if any.
+func (m *Mock) LastCall() (Call, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.calls) == 0 {
+		return Call{}, false
+	}
+
+	return m.calls[len(m.calls)-1], true
+}
+
+// Pending returns the amount of scripted responses not consumed yet.
+func (m *Mock) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return len(m.responses)
+}
+
+// Reset clears the calls, and the scripted responses.
+func (m *Mock) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.calls = nil
+	m.responses = nil
+}
+
+//////
+// Helpers.
+//////
+
+// next pops the next scripted response.
+func (m *Mock) next() (Response, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if len(m.responses) > 0 {
+		r := m.responses[0]
+		m.responses = m.responses[1:]
+
+		return r, nil
+	}
+
+	if m.fallback != nil {
+		return *m.fallback, nil
+	}
+
+	return Response{}, customerror.NewMissingError(fmt.Sprintf("scripted response for call #%d", len(m.calls)+1))
+}
+
+// complete responds with the next scripted response, traced by the span.
+func (m *Mock) complete(ctx context.Context, span *provider.Span, o *provider.Options) (string, error) {
+	r, err := m.next()
+	if err != nil {
+		m.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	settle, err := m.Reserve(ctx, o)
+	if err != nil {
+		m.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	var usage provider.Usage
+
+	defer func() { settle(usage) }()
+
+	if r.Expect != nil {
+		if err := r.Expect(o); err != nil {
+			m.GetCounterCompletionFailed().Add(1)
+
+			return "", span.Fail(err)
+		}
+	}
+
+	latency := m.latency
+	if r.Latency > 0 {
+		latency = r.Latency
+	}
+
+	if err := sleep(ctx, latency); err != nil {
+		m.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(err)
+	}
+
+	if r.Err != nil {
+		m.GetCounterCompletionFailed().Add(1)
+
+		return "", span.Fail(r.Err)
+	}
+
+	content := r.Content
+	if content == "" {
+		content = strings.Join(r.Chunks, "")
+	}
+
+	if o.StreamHandler != nil {
+		chunks := r.Chunks
+		if len(chunks) == 0 {
+			chunks = []string{content}
+		}
+
+		for _, chunk := range chunks {
+			if err := sleep(ctx, r.ChunkDelay); err != nil {
+				m.GetCounterCompletionFailed().Add(1)
+
+				return "", span.Fail(err)
+			}
+
+			if err := o.StreamHandler(chunk); err != nil {
+				m.GetCounterCompletionFailed().Add(1)
+
+				return "", span.Fail(err)
+			}
+		}
+	}
+
+	usage = r.Usage
+
+	span.SetResponse("", o.Model)
+	span.SetUsage(usage)
+
+	// Optional response body processing.
+	if o.ResponseBody != nil {
+		if err := json.Unmarshal([]byte(content), o.ResponseBody); err != nil {
+			return "", span.Fail(err)
+		}
+	}
+
+	m.GetCounterCompletion().Add(1)
+
+	return content, nil
+}
+
+// sleep sleeps, unless the context is done first.
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// unexpected returns an expectation error.
+func unexpected(what string, expected, got any) error {
+	return customerror.NewInvalidError(fmt.Sprintf("%s, expected %v, got %v", what, expected, got))
+}
+
+//////
+// Factory.
+//////
+
+// New returns a mock provider.
+func New(options ...Func) (*Mock, error) {
+	// Enforces IProvider interface implementation.
+	var _ provider.IProvider = (*Mock)(nil)
+
+	m := &Mock{
+		Provider: &provider.Provider{
+			Capabilities: provider.Capabilities{
+				JSONMode:  true,
+				Seed:      true,
+				Streaming: true,
+				TopK:      true,
+			},
+			DefaultModel: DefaultModel,
+			Name:         DefaultName,
+		},
+	}
+
+	for _, option := range options {
+		if err := option(m); err != nil {
+			return nil, err
+		}
+	}
+
+	p, err := provider.New(
+		m.Name,
+		provider.WithEndpoint("mock://"+m.Name),
+		provider.WithDefaulModel(m.DefaultModel),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	p.Capabilities = m.Capabilities
+	m.Provider = p
+
+	return m, nil
+}
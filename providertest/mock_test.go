@@ -0,0 +1,102 @@
+package providertest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/provider"
+)
+
+func TestMock(t *testing.T) {
+	ctx := context.Background()
+
+	m, err := New(
+		WithName("scripted"),
+		WithResponses(
+			Response{
+				Content: `{"response":"pong"}`,
+				Expect:  ExpectAll(ExpectModel("mock-model"), ExpectUserMessages("ping")),
+				Usage:   provider.Usage{InputTokens: 1, OutputTokens: 2},
+			},
+			Response{Err: errors.New("boom")},
+			Response{Chunks: []string{"po", "ng"}, ChunkDelay: time.Millisecond},
+		),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "scripted", m.GetName())
+
+	// Scripted response, with expectations, usage, and response body.
+	var (
+		body struct {
+			Response string `json:"response"`
+		}
+		usage provider.Usage
+	)
+
+	response, err := m.Completion(
+		ctx,
+		provider.WithUserMessages("ping"),
+		provider.WithResponseBody(&body),
+		provider.WithUsage(&usage),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"response":"pong"}`, response)
+	assert.Equal(t, "pong", body.Response)
+	assert.Equal(t, 2, usage.OutputTokens)
+
+	// Scripted error.
+	_, err = m.Completion(ctx, provider.WithUserMessages("ping"))
+	assert.EqualError(t, err, "boom")
+
+	// Streaming.
+	chunks := []string{}
+
+	response, err = m.Completion(
+		ctx,
+		provider.WithUserMessages("ping"),
+		provider.WithStreamHandler(func(chunk string) error {
+			chunks = append(chunks, chunk)
+
+			return nil
+		}),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, "pong", response)
+	assert.Equal(t, []string{"po", "ng"}, chunks)
+
+	// Exhausted.
+	_, err = m.Completion(ctx, provider.WithUserMessages("ping"))
+	assert.Error(t, err)
+
+	calls := m.Calls()
+	assert.Len(t, calls, 4)
+	assert.Equal(t, []string{"ping"}, calls[0].Options.UserMessages)
+	assert.Error(t, calls[1].Err)
+	assert.Equal(t, 0, m.Pending())
+
+	// Failed expectation.
+	m.Enqueue(Response{Content: "pong", Expect: ExpectSystemMessages("be brief")})
+
+	_, err = m.Completion(ctx, provider.WithUserMessages("ping"))
+	assert.Error(t, err)
+
+	// Fallback, and latency honouring the context.
+	m, err = New(WithFallback(Response{Content: "pong"}), WithLatency(time.Minute))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+
+	_, err = m.Completion(ctx, provider.WithUserMessages("ping"))
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	last, ok := m.LastCall()
+	assert.True(t, ok)
+	assert.ErrorIs(t, last.Err, context.DeadlineExceeded)
+
+	m.Reset()
+	assert.Empty(t, m.Calls())
+}
@@ -0,0 +1,465 @@
+package providertest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Format is a vendor wire format.
+type Format string
+
+// Supported formats.
+const (
+	Anthropic   Format = "anthropic"
+	HuggingFace Format = "huggingface"
+	Ollama      Format = "ollama"
+	OpenAI      Format = "openai"
+)
+
+// paths are the chat endpoint paths of each format.
+var paths = map[Format]string{
+	Anthropic:   "/v1/messages",
+	HuggingFace: "/v1/chat/completions",
+	Ollama:      "/api/chat",
+	OpenAI:      "/v1/chat/completions",
+}
+
+// Reply is a scripted reply of the server.
+type Reply struct {
+	// Content of the reply. Defaults to the concatenated chunks.
+	Content string
+
+	// Chunks streamed, in order, if the request asks for streaming. Defaults
+	// to the content, as a single chunk.
+	Chunks []string
+
+	// ErrorMessage of the reply, if Status isn't 2xx.
+	ErrorMessage string
+
+	// FinishReason of the reply. Defaults to the format's natural stop.
+	FinishReason string
+
+	// Model of the reply. Defaults to the requested one.
+	Model string
+
+	// Status of the reply. Defaults to 200. Non-2xx replies are errors, in
+	// the format's error shape.
+	Status int
+
+	// Usage of the reply.
+	Usage provider.Usage
+}
+
+// Request is a received request.
+type Request struct {
+	Body   []byte
+	Header http.Header
+	Method string
+	Path   string
+}
+
+// Server is a fake API server speaking the wire format of a vendor. Each chat
+// request consumes the next scripted reply, or the fallback, once exhausted.
+// It's safe for concurrent use.
+type Server struct {
+	*httptest.Server
+
+	format Format
+
+	mu       sync.Mutex
+	fallback *Reply
+	replies  []Reply
+	requests []Request
+}
+
+// chatRequest is the part of chat requests the server reads.
+type chatRequest struct {
+	Model  string `json:"model"`
+	Stream bool   `json:"stream"`
+}
+
+//////
+// Request methods.
+//////
+
+// Decode unmarshals the request body into v.
+func (r Request) Decode(v any) error {
+	return json.Unmarshal(r.Body, v)
+}
+
+//////
+// Server methods.
+//////
+
+// Endpoint returns the chat endpoint URL, to be set as the provider endpoint.
+func (s *Server) Endpoint() string {
+	return s.URL + paths[s.format]
+}
+
+// Enqueue scripts more replies.
+func (s *Server) Enqueue(replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.replies = append(s.replies, replies...)
+}
+
+// SetFallback sets the reply used once the scripted ones are exhausted.
+// Without it, requests then fail with a 500.
+func (s *Server) SetFallback(reply Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.fallback = &reply
+}
+
+// Requests returns the received requests, in order.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return slices.Clone(s.requests)
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+
+	s.mu.Lock()
+
+	s.requests = append(s.requests, Request{
+		Body:   body,
+		Header: r.Header.Clone(),
+		Method: r.Method,
+		Path:   r.URL.Path,
+	})
+
+	s.mu.Unlock()
+
+	if r.URL.Path != paths[s.format] {
+		s.writeError(w, http.StatusNotFound, "not found: "+r.URL.Path)
+
+		return
+	}
+
+	var req chatRequest
+
+	if err := json.Unmarshal(body, &req); err != nil {
+		s.writeError(w, http.StatusBadRequest, "invalid body: "+err.Error())
+
+		return
+	}
+
+	reply, ok := s.next()
+	if !ok {
+		s.writeError(w, http.StatusInternalServerError, "no scripted reply")
+
+		return
+	}
+
+	if reply.Status != 0 && (reply.Status < 200 || reply.Status > 299) {
+		s.writeError(w, reply.Status, reply.ErrorMessage)
+
+		return
+	}
+
+	if reply.Model == "" {
+		reply.Model = req.Model
+	}
+
+	if reply.Content == "" {
+		reply.Content = strings.Join(reply.Chunks, "")
+	}
+
+	if len(reply.Chunks) == 0 {
+		reply.Chunks = []string{reply.Content}
+	}
+
+	if req.Stream {
+		s.writeStream(w, reply)
+
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	_ = json.NewEncoder(w).Encode(s.body(reply))
+}
+
+//////
+// Helpers.
+//////
+
+// next pops the next scripted reply.
+func (s *Server) next() (Reply, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.replies) > 0 {
+		r := s.replies[0]
+		s.replies = s.replies[1:]
+
+		return r, true
+	}
+
+	if s.fallback != nil {
+		return *s.fallback, true
+	}
+
+	return Reply{}, false
+}
+
+// finishReason returns the finish reason of the reply.
+func (s *Server) finishReason(r Reply) string {
+	if r.FinishReason != "" {
+		return r.FinishReason
+	}
+
+	if s.format == Anthropic {
+		return "end_turn"
+	}
+
+	return "stop"
+}
+
+// body returns the non-streamed response body.
+func (s *Server) body(r Reply) any {
+	switch s.format {
+	case Anthropic:
+		return map[string]any{
+			"content":       []any{map[string]any{"text": r.Content, "type": "text"}},
+			"id":            "msg_mock",
+			"model":         r.Model,
+			"role":          "assistant",
+			"stop_reason":   s.finishReason(r),
+			"stop_sequence": nil,
+			"type":          "message",
+			"usage":         map[string]any{"input_tokens": r.Usage.InputTokens, "output_tokens": r.Usage.OutputTokens},
+		}
+	case Ollama:
+		return map[string]any{
+			"created_at":        time.Now().UTC().Format(time.RFC3339Nano),
+			"done":              true,
+			"done_reason":       s.finishReason(r),
+			"eval_count":        r.Usage.OutputTokens,
+			"message":           map[string]any{"content": r.Content, "role": "assistant"},
+			"model":             r.Model,
+			"prompt_eval_count": r.Usage.InputTokens,
+		}
+	default:
+		return map[string]any{
+			"choices": []any{map[string]any{
+				"finish_reason": s.finishReason(r),
+				"index":         0,
+				"message":       map[string]any{"content": r.Content, "role": "assistant"},
+			}},
+			"created": time.Now().Unix(),
+			"id":      "chatcmpl-mock",
+			"model":   r.Model,
+			"object":  "chat.completion",
+			"usage":   openAIUsage(r.Usage),
+		}
+	}
+}
+
+// writeStream writes the streamed response: server-sent events, or, for
+// Ollama, newline-delimited JSON.
+func (s *Server) writeStream(w http.ResponseWriter, r Reply) {
+	flusher, _ := w.(http.Flusher)
+
+	write := func(event string, data any) {
+		encoded, _ := json.Marshal(data)
+
+		switch {
+		case s.format == Ollama:
+			fmt.Fprintf(w, "%s\n", encoded)
+		case event != "":
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, encoded)
+		default:
+			fmt.Fprintf(w, "data: %s\n\n", encoded)
+		}
+
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	switch s.format {
+	case Anthropic:
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		write("message_start", map[string]any{
+			"message": map[string]any{
+				"content": []any{},
+				"id":      "msg_mock",
+				"model":   r.Model,
+				"role":    "assistant",
+				"type":    "message",
+				"usage":   map[string]any{"input_tokens": r.Usage.InputTokens, "output_tokens": 0},
+			},
+			"type": "message_start",
+		})
+		write("content_block_start", map[string]any{
+			"content_block": map[string]any{"text": "", "type": "text"},
+			"index":         0,
+			"type":          "content_block_start",
+		})
+
+		for _, chunk := range r.Chunks {
+			write("content_block_delta", map[string]any{
+				"delta": map[string]any{"text": chunk, "type": "text_delta"},
+				"index": 0,
+				"type":  "content_block_delta",
+			})
+		}
+
+		write("content_block_stop", map[string]any{"index": 0, "type": "content_block_stop"})
+		write("message_delta", map[string]any{
+			"delta": map[string]any{"stop_reason": s.finishReason(r), "stop_sequence": nil},
+			"type":  "message_delta",
+			"usage": map[string]any{"output_tokens": r.Usage.OutputTokens},
+		})
+		write("message_stop", map[string]any{"type": "message_stop"})
+	case Ollama:
+		w.Header().Set("Content-Type", "application/x-ndjson")
+
+		for _, chunk := range r.Chunks {
+			write("", map[string]any{
+				"created_at": time.Now().UTC().Format(time.RFC3339Nano),
+				"done":       false,
+				"message":    map[string]any{"content": chunk, "role": "assistant"},
+				"model":      r.Model,
+			})
+		}
+
+		write("", map[string]any{
+			"created_at":        time.Now().UTC().Format(time.RFC3339Nano),
+			"done":              true,
+			"done_reason":       s.finishReason(r),
+			"eval_count":        r.Usage.OutputTokens,
+			"message":           map[string]any{"content": "", "role": "assistant"},
+			"model":             r.Model,
+			"prompt_eval_count": r.Usage.InputTokens,
+		})
+	default:
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		chunk := func(delta map[string]any, finishReason any, usage any) map[string]any {
+			return map[string]any{
+				"choices": []any{map[string]any{"delta": delta, "finish_reason": finishReason, "index": 0}},
+				"created": time.Now().Unix(),
+				"id":      "chatcmpl-mock",
+				"model":   r.Model,
+				"object":  "chat.completion.chunk",
+				"usage":   usage,
+			}
+		}
+
+		write("", chunk(map[string]any{"content": "", "role": "assistant"}, nil, nil))
+
+		for _, c := range r.Chunks {
+			write("", chunk(map[string]any{"content": c}, nil, nil))
+		}
+
+		write("", chunk(map[string]any{}, s.finishReason(r), openAIUsage(r.Usage)))
+
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}
+}
+
+// writeError writes an error in the format's error shape.
+func (s *Server) writeError(w http.ResponseWriter, status int, message string) {
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	var body any
+
+	switch s.format {
+	case Anthropic:
+		body = map[string]any{
+			"error": map[string]any{"message": message, "type": anthropicErrorType(status)},
+			"type":  "error",
+		}
+	case Ollama:
+		body = map[string]any{"error": message}
+	default:
+		body = map[string]any{
+			"error": map[string]any{"code": nil, "message": message, "param": nil, "type": openAIErrorType(status)},
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// openAIUsage returns the usage in the OpenAI format.
+func openAIUsage(u provider.Usage) map[string]any {
+	return map[string]any{
+		"completion_tokens": u.OutputTokens,
+		"prompt_tokens":     u.InputTokens,
+		"total_tokens":      u.InputTokens + u.OutputTokens,
+	}
+}
+
+// anthropicErrorType returns the Anthropic error type of the status.
+func anthropicErrorType(status int) string {
+	switch status {
+	case http.StatusBadRequest:
+		return "invalid_request_error"
+	case http.StatusUnauthorized:
+		return "authentication_error"
+	case http.StatusForbidden:
+		return "permission_error"
+	case http.StatusNotFound:
+		return "not_found_error"
+	case http.StatusTooManyRequests:
+		return "rate_limit_error"
+	case 529:
+		return "overloaded_error"
+	default:
+		return "api_error"
+	}
+}
+
+// openAIErrorType returns the OpenAI error type of the status.
+func openAIErrorType(status int) string {
+	switch status {
+	case http.StatusUnauthorized:
+		return "invalid_authentication"
+	case http.StatusTooManyRequests:
+		return "rate_limit_exceeded"
+	case http.StatusBadRequest, http.StatusNotFound:
+		return "invalid_request_error"
+	default:
+		return "server_error"
+	}
+}
+
+//////
+// Factory.
+//////
+
+// NewServer starts a fake API server speaking the format. Close it when done.
+func NewServer(format Format, replies ...Reply) *Server {
+	s := &Server{format: format, replies: replies}
+
+	s.Server = httptest.NewServer(s)
+
+	return s
+}
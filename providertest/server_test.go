@@ -0,0 +1,171 @@
+package providertest_test
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/anthropic"
+	"github.com/thalesfsp/inference/huggingface"
+	"github.com/thalesfsp/inference/ollama"
+	"github.com/thalesfsp/inference/openai"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/providertest"
+)
+
+func TestServer(t *testing.T) {
+	tests := []struct {
+		format providertest.Format
+		new    func(endpoint string) (provider.IProvider, error)
+	}{
+		{
+			format: providertest.Anthropic,
+			new: func(endpoint string) (provider.IProvider, error) {
+				return anthropic.New(provider.WithEndpoint(endpoint), provider.WithToken("test"), provider.WithRetry(0, 0))
+			},
+		},
+		{
+			format: providertest.HuggingFace,
+			new: func(endpoint string) (provider.IProvider, error) {
+				return huggingface.New(provider.WithEndpoint(endpoint), provider.WithToken("test"), provider.WithRetry(0, 0))
+			},
+		},
+		{
+			format: providertest.Ollama,
+			new: func(endpoint string) (provider.IProvider, error) {
+				return ollama.New(provider.WithEndpoint(endpoint), provider.WithRetry(0, 0))
+			},
+		},
+		{
+			format: providertest.OpenAI,
+			new: func(endpoint string) (provider.IProvider, error) {
+				return openai.New(provider.WithEndpoint(endpoint), provider.WithToken("test"), provider.WithRetry(0, 0))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			s := providertest.NewServer(
+				tt.format,
+				providertest.Reply{Content: "pong", Usage: provider.Usage{InputTokens: 3, OutputTokens: 1}},
+				providertest.Reply{Status: http.StatusUnauthorized, ErrorMessage: "invalid api key"},
+			)
+			defer s.Close()
+
+			p, err := tt.new(s.Endpoint())
+			assert.NoError(t, err)
+
+			var usage provider.Usage
+
+			response, err := p.Completion(
+				context.Background(),
+				provider.WithModel("some-model"),
+				provider.WithUserMessages("ping"),
+				provider.WithUsage(&usage),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, "pong", response)
+			assert.Equal(t, provider.Usage{FinishReason: provider.FinishReasonStop, InputTokens: 3, OutputTokens: 1}, usage)
+
+			_, err = p.Completion(context.Background(), provider.WithModel("some-model"), provider.WithUserMessages("ping"))
+			assert.ErrorContains(t, err, "invalid api key")
+
+			requests := s.Requests()
+			assert.Len(t, requests, 2)
+
+			var body struct {
+				Model string `json:"model"`
+			}
+
+			assert.NoError(t, requests[0].Decode(&body))
+			assert.Equal(t, "some-model", body.Model)
+
+			// Streaming.
+			s.Enqueue(providertest.Reply{
+				Chunks: []string{"po", "ng"},
+				Usage:  provider.Usage{InputTokens: 3, OutputTokens: 2},
+			})
+
+			chunks := []string{}
+
+			response, err = p.Completion(
+				context.Background(),
+				provider.WithModel("some-model"),
+				provider.WithUserMessages("ping"),
+				provider.WithUsage(&usage),
+				provider.WithStreamHandler(func(chunk string) error {
+					chunks = append(chunks, chunk)
+
+					return nil
+				}),
+			)
+			assert.NoError(t, err)
+			assert.Equal(t, "pong", response)
+			assert.Equal(t, []string{"po", "ng"}, chunks)
+			assert.Equal(t, provider.Usage{FinishReason: provider.FinishReasonStop, InputTokens: 3, OutputTokens: 2}, usage)
+
+			// Stream handler errors stop it.
+			s.Enqueue(providertest.Reply{Chunks: []string{"po", "ng"}})
+
+			_, err = p.Completion(
+				context.Background(),
+				provider.WithModel("some-model"),
+				provider.WithUserMessages("ping"),
+				provider.WithStreamHandler(func(string) error { return errors.New("client went away") }),
+			)
+			assert.ErrorContains(t, err, "client went away")
+
+			// Errors before streaming, not retried.
+			s.Enqueue(providertest.Reply{Status: http.StatusTooManyRequests})
+
+			_, err = p.Completion(
+				context.Background(),
+				provider.WithModel("some-model"),
+				provider.WithUserMessages("ping"),
+				provider.WithStreamHandler(func(string) error { return nil }),
+			)
+			assert.Equal(t, provider.ErrorClassRateLimited, provider.ErrorClass(err))
+		})
+	}
+}
+
+func TestServer_stream(t *testing.T) {
+	tests := []struct {
+		format   providertest.Format
+		expected []string
+	}{
+		{format: providertest.OpenAI, expected: []string{`"content":"po"`, `"content":"ng"`, "data: [DONE]"}},
+		{format: providertest.Anthropic, expected: []string{"event: content_block_delta", `"text":"ng"`, "event: message_stop"}},
+		{format: providertest.Ollama, expected: []string{`"content":"po"`, `"done":true`}},
+	}
+
+	for _, tt := range tests {
+		t.Run(string(tt.format), func(t *testing.T) {
+			s := providertest.NewServer(tt.format, providertest.Reply{Chunks: []string{"po", "ng"}})
+			defer s.Close()
+
+			resp, err := http.Post(s.Endpoint(), "application/json", strings.NewReader(`{"model":"m","stream":true}`))
+			assert.NoError(t, err)
+
+			defer resp.Body.Close()
+
+			lines := []string{}
+			scanner := bufio.NewScanner(resp.Body)
+
+			for scanner.Scan() {
+				lines = append(lines, scanner.Text())
+			}
+
+			stream := strings.Join(lines, "\n")
+
+			for _, expected := range tt.expected {
+				assert.Contains(t, stream, expected)
+			}
+		})
+	}
+}
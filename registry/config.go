@@ -0,0 +1,369 @@
+package registry
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/ollama"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/validation"
+	"gopkg.in/yaml.v3"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Supported formats.
+const (
+	JSON = "json"
+	YAML = "yaml"
+)
+
+// DefaultEnvPrefix is the default prefix of the environment variables.
+const DefaultEnvPrefix = "INFERENCE"
+
+// DefaultRetries is the amount of retries if only the backoff is set.
+const DefaultRetries = 3
+
+// tokenless are the types without a token, whose endpoint can be set without
+// TokenEnv.
+var tokenless = map[string]bool{ollama.Name: true}
+
+// Duration is a time.Duration, in its string form, e.g.: `30s`.
+type Duration time.Duration
+
+// Instance is a named provider instance.
+type Instance struct {
+	// Endpoint of the provider. Default to the type's. Requires TokenEnv, not
+	// to send the type's token to another host, unless the type has none,
+	// e.g.: `ollama`.
+	Endpoint string `json:"endpoint,omitempty" yaml:"endpoint,omitempty"`
+
+	// Model used when none is set. Default to the type's.
+	Model string `json:"model,omitempty" yaml:"model,omitempty"`
+
+	// Name of the instance, its key in the provider.Map.
+	Name string `json:"name" yaml:"name" validate:"required"`
+
+	// Retries of failed requests, e.g.: rate limited. Zero disables them.
+	// Default to the type's.
+	Retries *int `json:"retries,omitempty" yaml:"retries,omitempty" validate:"omitempty,gte=0"`
+
+	// RetryBackoff before the first retry, doubled for each next. Default to
+	// the type's.
+	RetryBackoff Duration `json:"retryBackoff,omitempty" yaml:"retryBackoff,omitempty" validate:"gte=0"`
+
+	// Timeout of requests, excluding streamed ones. Default to the type's.
+	Timeout Duration `json:"timeout,omitempty" yaml:"timeout,omitempty" validate:"gte=0"`
+
+	// TokenEnv is the environment variable holding the token. Default to the
+	// type's, e.g.: `OPENAI_API_KEY`.
+	TokenEnv string `json:"tokenEnv,omitempty" yaml:"tokenEnv,omitempty"`
+
+	// Type of the provider, e.g.: `openai`. Default to the name.
+	Type string `json:"type,omitempty" yaml:"type,omitempty"`
+}
+
+// Config lists the provider instances.
+type Config struct {
+	Providers []Instance `json:"providers" yaml:"providers" validate:"required,gt=0,dive"`
+}
+
+//////
+// Methods.
+//////
+
+// MarshalJSON implements the json.Marshaler interface.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON implements the json.Unmarshaler interface.
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return customerror.NewInvalidError("duration, expected a string, e.g.: 30s", customerror.WithError(err))
+	}
+
+	return d.parse(s)
+}
+
+// MarshalYAML implements the yaml.Marshaler interface.
+func (d Duration) MarshalYAML() (any, error) {
+	return time.Duration(d).String(), nil
+}
+
+// UnmarshalYAML implements the yaml.Unmarshaler interface.
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	return d.parse(value.Value)
+}
+
+// parse parses the string form.
+func (d *Duration) parse(s string) error {
+	duration, err := time.ParseDuration(s)
+	if err != nil {
+		return customerror.NewInvalidError(fmt.Sprintf("duration %q", s), customerror.WithError(err))
+	}
+
+	*d = Duration(duration)
+
+	return nil
+}
+
+// withDefaults returns the instance with the defaults set.
+func (i Instance) withDefaults() Instance {
+	if i.Type == "" {
+		i.Type = i.Name
+	}
+
+	return i
+}
+
+// validate validates the instance: its endpoint, if set, comes with the
+// token's environment variable.
+func (i Instance) validate() error {
+	if i.Endpoint != "" && i.TokenEnv == "" && !tokenless[i.Type] {
+		return customerror.NewMissingError(fmt.Sprintf("tokenEnv of provider %s, required with its endpoint", i.Name))
+	}
+
+	return nil
+}
+
+// options returns the client options of the instance.
+func (i Instance) options() ([]provider.ClientFunc, error) {
+	if err := i.validate(); err != nil {
+		return nil, err
+	}
+
+	options := []provider.ClientFunc{
+		provider.WithDefaulModel(i.Model),
+		provider.WithEndpoint(i.Endpoint),
+		provider.WithInstance(i.Name),
+		provider.WithTimeout(time.Duration(i.Timeout)),
+	}
+
+	if i.TokenEnv != "" {
+		token := os.Getenv(i.TokenEnv)
+		if token == "" {
+			return nil, customerror.NewMissingError(fmt.Sprintf("token of provider %s, set %s", i.Name, i.TokenEnv))
+		}
+
+		options = append(options, provider.WithToken(token))
+	}
+
+	if i.Retries != nil || i.RetryBackoff > 0 {
+		retries := DefaultRetries
+		if i.Retries != nil {
+			retries = *i.Retries
+		}
+
+		options = append(options, provider.WithRetry(retries, time.Duration(i.RetryBackoff)))
+	}
+
+	return options, nil
+}
+
+// Validate validates the configuration: instances are named uniquely, their
+// types registered, and overridden endpoints have their token.
+func (c *Config) Validate() error {
+	if err := validation.Validate(c); err != nil {
+		return err
+	}
+
+	names := make(map[string]bool, len(c.Providers))
+
+	for _, instance := range c.Providers {
+		if names[instance.Name] {
+			return customerror.NewInvalidError(fmt.Sprintf("providers, %s is repeated", instance.Name))
+		}
+
+		names[instance.Name] = true
+
+		instance = instance.withDefaults()
+
+		mu.RLock()
+		_, ok := factories[instance.Type]
+		mu.RUnlock()
+
+		if !ok {
+			return customerror.NewNotFoundError(fmt.Sprintf("type %s of provider %s", instance.Type, instance.Name))
+		}
+
+		if err := instance.validate(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Build creates the provider instances, configured by the common options,
+// e.g.: the tracer provider, then the instance, keyed by name.
+func (c *Config) Build(options ...provider.ClientFunc) (provider.Map, error) {
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	m := make(provider.Map, len(c.Providers))
+
+	for _, instance := range c.Providers {
+		p, err := New(instance, options...)
+		if err != nil {
+			return nil, err
+		}
+
+		m[instance.Name] = p
+	}
+
+	return m, nil
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Load loads the configuration in `data` of the given format. Unknown fields
+// are rejected.
+func Load(data []byte, format string) (*Config, error) {
+	var c Config
+
+	switch format {
+	case JSON:
+		decoder := json.NewDecoder(bytes.NewReader(data))
+
+		decoder.DisallowUnknownFields()
+
+		if err := decoder.Decode(&c); err != nil {
+			return nil, customerror.NewFailedToError("unmarshal configuration", customerror.WithError(err))
+		}
+	case YAML:
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+
+		decoder.KnownFields(true)
+
+		if err := decoder.Decode(&c); err != nil {
+			return nil, customerror.NewFailedToError("unmarshal configuration", customerror.WithError(err))
+		}
+	default:
+		return nil, customerror.NewInvalidError("format " + format)
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+// LoadFile loads the configuration in the file. Format is inferred from the
+// extension (`.json`, `.yaml`, or `.yml`).
+func LoadFile(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, customerror.NewFailedToError("read configuration", customerror.WithError(err))
+	}
+
+	format := JSON
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		format = YAML
+	}
+
+	return Load(data, format)
+}
+
+// LoadEnv loads the configuration from the environment variables of the
+// prefix, default to DefaultEnvPrefix, e.g.:
+//
+//	INFERENCE_PROVIDERS=fast,local
+//	INFERENCE_FAST_TYPE=openai
+//	INFERENCE_FAST_ENDPOINT=https://api.groq.com/openai/v1/chat/completions
+//	INFERENCE_FAST_TOKEN_ENV=GROQ_API_KEY
+//	INFERENCE_FAST_MODEL=llama-3.1-8b-instant
+//	INFERENCE_FAST_TIMEOUT=10s
+//	INFERENCE_FAST_RETRIES=5
+//	INFERENCE_FAST_RETRY_BACKOFF=2s
+//	INFERENCE_LOCAL_TYPE=ollama
+//
+// Names are upper-cased, non-alphanumeric characters replaced by `_`.
+func LoadEnv(prefix string) (*Config, error) {
+	if prefix == "" {
+		prefix = DefaultEnvPrefix
+	}
+
+	names := os.Getenv(prefix + "_PROVIDERS")
+	if names == "" {
+		return nil, customerror.NewMissingError(prefix + "_PROVIDERS")
+	}
+
+	var c Config
+
+	for _, name := range strings.Split(names, ",") {
+		name = strings.TrimSpace(name)
+
+		env := func(field string) string {
+			return os.Getenv(prefix + "_" + envName(name) + "_" + field)
+		}
+
+		instance := Instance{
+			Endpoint: env("ENDPOINT"),
+			Model:    env("MODEL"),
+			Name:     name,
+			TokenEnv: env("TOKEN_ENV"),
+			Type:     env("TYPE"),
+		}
+
+		if v := env("RETRIES"); v != "" {
+			retries, err := strconv.Atoi(v)
+			if err != nil {
+				return nil, customerror.NewInvalidError(fmt.Sprintf("retries of provider %s", name), customerror.WithError(err))
+			}
+
+			instance.Retries = &retries
+		}
+
+		for field, d := range map[string]*Duration{"RETRY_BACKOFF": &instance.RetryBackoff, "TIMEOUT": &instance.Timeout} {
+			if v := env(field); v != "" {
+				if err := d.parse(v); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		c.Providers = append(c.Providers, instance)
+	}
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+//////
+// Helpers.
+//////
+
+// envName returns the name, in the form of environment variables.
+func envName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
@@ -0,0 +1,34 @@
+// Package registry builds providers from a declarative configuration, listing
+// any number of named provider instances, e.g.: two OpenAI-compatible
+// endpoints, and a local Ollama:
+//
+//	providers:
+//	  - name: fast
+//	    type: openai
+//	    endpoint: https://api.groq.com/openai/v1/chat/completions
+//	    tokenEnv: GROQ_API_KEY
+//	    model: llama-3.1-8b-instant
+//	    timeout: 10s
+//	  - name: smart
+//	    type: anthropic
+//	    model: claude-3-5-sonnet-latest
+//	    retries: 5
+//	    retryBackoff: 2s
+//	  - name: local
+//	    type: ollama
+//	    model: llama3.2
+//
+// Configurations are loaded from YAML, or JSON files, see LoadFile, or from
+// the environment, see LoadEnv, then built into a provider.Map:
+//
+//	cfg, err := registry.LoadFile("providers.yaml")
+//
+//	providers, err := cfg.Build()
+//
+// Unset fields default to the ones of the type, e.g.: the token to the
+// `OPENAI_API_KEY` environment variable for `openai`, except the token of an
+// overridden endpoint: set its `tokenEnv`. Built-in types are `anthropic`,
+// `huggingface`, `ollama`, and `openai`, built without setting the package's
+// singleton, so its Get is left as is. Other provider packages plug in through
+// Register.
+package registry
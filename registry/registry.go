@@ -0,0 +1,145 @@
+package registry
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/anthropic"
+	"github.com/thalesfsp/inference/huggingface"
+	"github.com/thalesfsp/inference/internal/builtin"
+	"github.com/thalesfsp/inference/internal/config"
+	"github.com/thalesfsp/inference/ollama"
+	"github.com/thalesfsp/inference/openai"
+	"github.com/thalesfsp/inference/provider"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Factory creates a provider of a type, configured by the options, set after
+// the type's defaults.
+type Factory func(options ...provider.ClientFunc) (provider.IProvider, error)
+
+// Registered factories, by type.
+var (
+	mu        sync.RWMutex
+	factories = make(map[string]Factory)
+)
+
+//////
+// Exported functionalities.
+//////
+
+// Register registers the factory of the provider type, e.g.: in the `init`
+// of the provider package. Types are registered once.
+func Register(typ string, factory Factory) error {
+	if typ == "" {
+		return customerror.NewRequiredError("type")
+	}
+
+	if factory == nil {
+		return customerror.NewRequiredError("factory")
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	if _, ok := factories[typ]; ok {
+		return customerror.NewInvalidError(fmt.Sprintf("type %s, already registered", typ))
+	}
+
+	factories[typ] = factory
+
+	return nil
+}
+
+// Types returns the registered types, sorted.
+func Types() []string {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+
+	for typ := range factories {
+		types = append(types, typ)
+	}
+
+	sort.Strings(types)
+
+	return types
+}
+
+// New creates the provider instance, configured by the common options, then
+// the instance.
+func New(instance Instance, options ...provider.ClientFunc) (provider.IProvider, error) {
+	instance = instance.withDefaults()
+
+	mu.RLock()
+	factory, ok := factories[instance.Type]
+	mu.RUnlock()
+
+	if !ok {
+		return nil, customerror.NewNotFoundError(fmt.Sprintf("type %s of provider %s", instance.Type, instance.Name))
+	}
+
+	instanceOptions, err := instance.options()
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := factory(slices.Concat(options, instanceOptions)...)
+	if err != nil {
+		return nil, customerror.NewFailedToError(
+			fmt.Sprintf("create provider %s", instance.Name),
+			customerror.WithError(err),
+		)
+	}
+
+	return p, nil
+}
+
+//////
+// Helpers.
+//////
+
+// asFactory converts the constructor of a built-in provider, configured by
+// the defaults, read when called, then the options. It panics if the
+// constructor isn't registered.
+func asFactory(name string, defaults func(cfg *config.Config) []provider.ClientFunc) Factory {
+	constructor, ok := builtin.Get(name)
+	if !ok {
+		panic(fmt.Sprintf("constructor of %s not registered", name))
+	}
+
+	return func(options ...provider.ClientFunc) (provider.IProvider, error) {
+		return constructor(slices.Concat(defaults(config.Get()), options)...)
+	}
+}
+
+// Built-in types, configured by default from the environment, as their
+// NewDefault, but built without replacing the package's singleton: a
+// configuration may have several instances of a type.
+func init() {
+	for typ, factory := range map[string]Factory{
+		anthropic.Name: asFactory(anthropic.Name, func(cfg *config.Config) []provider.ClientFunc {
+			return []provider.ClientFunc{provider.WithEndpoint(cfg.AnthropicEndpoint), provider.WithToken(cfg.AnthropicToken)}
+		}),
+		huggingface.Name: asFactory(huggingface.Name, func(cfg *config.Config) []provider.ClientFunc {
+			return []provider.ClientFunc{provider.WithEndpoint(cfg.HuggingFaceEndpoint), provider.WithToken(cfg.HuggingFaceToken)}
+		}),
+		ollama.Name: asFactory(ollama.Name, func(cfg *config.Config) []provider.ClientFunc {
+			return []provider.ClientFunc{provider.WithEndpoint(cfg.OllamaEndpoint)}
+		}),
+		openai.Name: asFactory(openai.Name, func(cfg *config.Config) []provider.ClientFunc {
+			return []provider.ClientFunc{provider.WithEndpoint(cfg.OpenAIEndpoint), provider.WithToken(cfg.OpenAIToken)}
+		}),
+	} {
+		if err := Register(typ, factory); err != nil {
+			panic(err)
+		}
+	}
+}
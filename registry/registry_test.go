@@ -0,0 +1,224 @@
+package registry_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/anthropic"
+	"github.com/thalesfsp/inference/ollama"
+	"github.com/thalesfsp/inference/openai"
+	"github.com/thalesfsp/inference/provider"
+	"github.com/thalesfsp/inference/providertest"
+	"github.com/thalesfsp/inference/registry"
+)
+
+// captured are the client options the test factory was called with.
+var captured provider.ClientOptions
+
+func init() {
+	if err := registry.Register("test", func(options ...provider.ClientFunc) (provider.IProvider, error) {
+		captured = provider.ClientOptions{}
+
+		for _, option := range options {
+			if err := option(&captured); err != nil {
+				return nil, err
+			}
+		}
+
+		return providertest.New(providertest.WithName("test"), providertest.WithModel(captured.Model))
+	}); err != nil {
+		panic(err)
+	}
+}
+
+func TestRegister(t *testing.T) {
+	assert.Equal(t, []string{"anthropic", "huggingface", "ollama", "openai", "test"}, registry.Types())
+
+	assert.ErrorContains(t, registry.Register("test", func(...provider.ClientFunc) (provider.IProvider, error) {
+		return nil, nil
+	}), "already registered")
+	assert.Error(t, registry.Register("", nil))
+	assert.Error(t, registry.Register("other", nil))
+}
+
+func TestNew(t *testing.T) {
+	t.Setenv("TEST_TOKEN", "secret")
+
+	retries := 0
+
+	p, err := registry.New(registry.Instance{
+		Endpoint:     "http://localhost:8080",
+		Model:        "some-model",
+		Name:         "a",
+		Retries:      &retries,
+		RetryBackoff: registry.Duration(time.Second),
+		Timeout:      registry.Duration(time.Minute),
+		TokenEnv:     "TEST_TOKEN",
+		Type:         "test",
+	}, provider.WithEndpoint("http://ignored"), provider.WithToken("ignored"))
+	assert.NoError(t, err)
+	assert.Equal(t, "some-model", provider.DefaultModel(p))
+
+	assert.Equal(t, provider.ClientOptions{
+		Endpoint: "http://localhost:8080",
+		Instance: "a",
+		Model:    "some-model",
+		Retry:    &provider.Retry{Backoff: time.Second},
+		Timeout:  time.Minute,
+		Token:    "secret",
+	}, captured)
+
+	_, err = registry.New(registry.Instance{Endpoint: "http://localhost:8080", Name: "a", Type: "test"})
+	assert.ErrorContains(t, err, "missing tokenEnv of provider a")
+
+	_, err = registry.New(registry.Instance{Name: "a", Type: "test", TokenEnv: "TEST_MISSING_TOKEN"})
+	assert.ErrorContains(t, err, "set TEST_MISSING_TOKEN")
+
+	_, err = registry.New(registry.Instance{Name: "unknown"})
+	assert.ErrorContains(t, err, "type unknown of provider unknown not found")
+}
+
+func TestConfig_Build(t *testing.T) {
+	t.Setenv("GROQ_API_KEY", "groq")
+	t.Setenv("SMART_API_KEY", "smart")
+	t.Setenv("ANTHROPIC_API_KEY", "anthropic")
+
+	cfg, err := registry.Load([]byte(`
+providers:
+  - name: fast
+    type: openai
+    endpoint: https://api.groq.com/openai/v1/chat/completions
+    tokenEnv: GROQ_API_KEY
+    model: llama-3.1-8b-instant
+    timeout: 10s
+    retryBackoff: 2s
+  - name: smart
+    type: openai
+    model: gpt-4o
+    tokenEnv: SMART_API_KEY
+    retries: 0
+  - name: anthropic
+  - name: local
+    type: ollama
+    endpoint: http://localhost:11434/api/chat
+`), registry.YAML)
+	assert.NoError(t, err)
+
+	// Built instances don't replace the package's.
+	p, err := providertest.New(providertest.WithName(openai.Name))
+	assert.NoError(t, err)
+
+	openai.Set(p)
+	t.Cleanup(func() { openai.Set(nil) })
+
+	m, err := cfg.Build(provider.WithTimeout(time.Minute))
+	assert.NoError(t, err)
+	assert.Len(t, m, 4)
+	assert.Same(t, p, openai.Get())
+
+	fast, ok := m["fast"].(*openai.OpenAI)
+	assert.True(t, ok)
+	assert.Equal(t, openai.Name, fast.GetName())
+	assert.Equal(t, "https://api.groq.com/openai/v1/chat/completions", fast.Endpoint)
+	assert.Equal(t, "groq", fast.Token)
+	assert.Equal(t, "llama-3.1-8b-instant", fast.DefaultModel)
+	assert.Equal(t, 10*time.Second, fast.Timeout)
+	assert.Equal(t, &provider.Retry{Backoff: 2 * time.Second, Times: registry.DefaultRetries}, fast.Retry)
+
+	smart, ok := m["smart"].(*openai.OpenAI)
+	assert.True(t, ok)
+	assert.Equal(t, "gpt-4o", smart.DefaultModel)
+	assert.Equal(t, time.Minute, smart.Timeout)
+	assert.Equal(t, &provider.Retry{}, smart.Retry)
+
+	_, ok = m["anthropic"].(*anthropic.Anthropic)
+	assert.True(t, ok)
+
+	local, ok := m["local"].(*ollama.Ollama)
+	assert.True(t, ok)
+	assert.Equal(t, "http://localhost:11434/api/chat", local.Endpoint)
+}
+
+func TestLoad(t *testing.T) {
+	cfg, err := registry.Load([]byte(`{
+		"providers": [
+			{"name": "a", "type": "test", "model": "some-model", "timeout": "1m30s", "retries": 2}
+		]
+	}`), registry.JSON)
+	assert.NoError(t, err)
+
+	retries := 2
+
+	assert.Equal(t, &registry.Config{Providers: []registry.Instance{{
+		Model:   "some-model",
+		Name:    "a",
+		Retries: &retries,
+		Timeout: registry.Duration(90 * time.Second),
+		Type:    "test",
+	}}}, cfg)
+
+	tests := []struct {
+		name    string
+		data    string
+		format  string
+		wantErr string
+	}{
+		{name: "format", data: `{}`, format: "toml", wantErr: "invalid format toml"},
+		{name: "unknown field", data: `{"providers": [{"name": "a", "tokn": "x"}]}`, format: registry.JSON, wantErr: "tokn"},
+		{name: "unknown yaml field", data: "providers:\n  - name: a\n    tokn: x\n", format: registry.YAML, wantErr: "tokn"},
+		{name: "duration", data: `{"providers": [{"name": "a", "type": "test", "timeout": 10}]}`, format: registry.JSON, wantErr: "duration"},
+		{name: "empty", data: `{"providers": []}`, format: registry.JSON, wantErr: "Providers"},
+		{name: "name", data: `{"providers": [{"type": "test"}]}`, format: registry.JSON, wantErr: "Name"},
+		{name: "repeated", data: `{"providers": [{"name": "a", "type": "test"}, {"name": "a", "type": "test"}]}`, format: registry.JSON, wantErr: "a is repeated"},
+		{name: "type", data: `{"providers": [{"name": "a", "type": "unknown"}]}`, format: registry.JSON, wantErr: "type unknown of provider a not found"},
+		{name: "retries", data: `{"providers": [{"name": "a", "type": "test", "retries": -1}]}`, format: registry.JSON, wantErr: "Retries"},
+		{name: "endpoint", data: `{"providers": [{"name": "a", "type": "openai", "endpoint": "http://localhost:8080"}]}`, format: registry.JSON, wantErr: "missing tokenEnv of provider a"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := registry.Load([]byte(tt.data), tt.format)
+			assert.ErrorContains(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	t.Setenv("TEST_PROVIDERS", "fast, my-local")
+	t.Setenv("TEST_FAST_TYPE", "test")
+	t.Setenv("TEST_FAST_ENDPOINT", "http://localhost:8080")
+	t.Setenv("TEST_FAST_TOKEN_ENV", "FAST_API_KEY")
+	t.Setenv("TEST_FAST_MODEL", "some-model")
+	t.Setenv("TEST_FAST_TIMEOUT", "10s")
+	t.Setenv("TEST_FAST_RETRIES", "5")
+	t.Setenv("TEST_FAST_RETRY_BACKOFF", "2s")
+	t.Setenv("TEST_MY_LOCAL_TYPE", "ollama")
+
+	cfg, err := registry.LoadEnv("TEST")
+	assert.NoError(t, err)
+
+	retries := 5
+
+	assert.Equal(t, &registry.Config{Providers: []registry.Instance{
+		{
+			Endpoint:     "http://localhost:8080",
+			Model:        "some-model",
+			Name:         "fast",
+			Retries:      &retries,
+			RetryBackoff: registry.Duration(2 * time.Second),
+			Timeout:      registry.Duration(10 * time.Second),
+			TokenEnv:     "FAST_API_KEY",
+			Type:         "test",
+		},
+		{Name: "my-local", Type: "ollama"},
+	}}, cfg)
+
+	t.Setenv("TEST_FAST_RETRIES", "many")
+
+	_, err = registry.LoadEnv("TEST")
+	assert.ErrorContains(t, err, "retries of provider fast")
+
+	_, err = registry.LoadEnv("TEST_UNSET")
+	assert.ErrorContains(t, err, "missing TEST_UNSET_PROVIDERS")
+}
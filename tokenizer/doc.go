@@ -0,0 +1,21 @@
+// Package tokenizer counts tokens offline, to pre-check prompt sizes, split
+// inputs, and estimate costs without calling any API.
+//
+// OpenAI models are counted exactly with their BPE encodings (`cl100k_base`,
+// and `o200k_base`), embedded in the binary. Other models are counted with an
+// estimator, calibrated per model family, which can be refined with the usage
+// reported by the providers:
+//
+//	n, err := tokenizer.CountTokens("gpt-4o", messages)
+//
+//	tokenizer.Get().Calibrate("llama3.2", messages, usage.InputTokens)
+//
+// Remote backends, e.g.: Anthropic's `count_tokens` endpoint, can be used for
+// models matching a prefix:
+//
+//	t, err := tokenizer.New(tokenizer.WithRemote("claude", anthropicProvider))
+//
+// Use it for the context window truncation with:
+//
+//	provider.SetTokenCounter(tokenizer.CountTokens)
+package tokenizer
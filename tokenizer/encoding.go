@@ -0,0 +1,104 @@
+package tokenizer
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+	tiktoken_loader "github.com/pkoukk/tiktoken-go-loader"
+	"github.com/thalesfsp/customerror"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Encodings.
+const (
+	// CL100K is the BPE encoding of GPT-4, GPT-3.5, and the v3 embeddings.
+	CL100K = "cl100k_base"
+
+	// O200K is the BPE encoding of GPT-4o, and later models.
+	O200K = "o200k_base"
+
+	// Estimate means the amount of tokens is estimated.
+	Estimate = "estimate"
+)
+
+// Encodings of OpenAI models, by model name prefix. The longest matching
+// prefix wins.
+var Encodings = map[string]string{
+	"chatgpt-4o":             O200K,
+	"gpt-3.5":                CL100K,
+	"gpt-4":                  CL100K,
+	"gpt-4.1":                O200K,
+	"gpt-4.5":                O200K,
+	"gpt-4o":                 O200K,
+	"gpt-5":                  O200K,
+	"o1":                     O200K,
+	"o3":                     O200K,
+	"o4":                     O200K,
+	"text-embedding-3":       CL100K,
+	"text-embedding-ada-002": CL100K,
+}
+
+// Loaded encodings, by name.
+var (
+	loaderOnce sync.Once
+	encodingMu sync.Mutex
+	encodings  = map[string]*tiktoken.Tiktoken{}
+)
+
+//////
+// Exported functionalities.
+//////
+
+// EncodingFor returns the encoding of the model, or Estimate if unknown.
+func EncodingFor(model string) string {
+	return longestPrefix(Encodings, model, Estimate)
+}
+
+//////
+// Helpers.
+//////
+
+// encoding returns the loaded encoding. Encodings are loaded once, from the
+// embedded files.
+func encoding(name string) (*tiktoken.Tiktoken, error) {
+	loaderOnce.Do(func() {
+		tiktoken.SetBpeLoader(tiktoken_loader.NewOfflineLoader())
+	})
+
+	encodingMu.Lock()
+	defer encodingMu.Unlock()
+
+	if enc, ok := encodings[name]; ok {
+		return enc, nil
+	}
+
+	enc, err := tiktoken.GetEncoding(name)
+	if err != nil {
+		return nil, customerror.NewFailedToError("load encoding "+name, customerror.WithError(err))
+	}
+
+	encodings[name] = enc
+
+	return enc, nil
+}
+
+// longestPrefix returns the value of the longest key prefixing s, or def.
+func longestPrefix[T any](m map[string]T, s string, def T) T {
+	match, found := "", false
+
+	for prefix := range m {
+		if strings.HasPrefix(s, prefix) && (!found || len(prefix) > len(match)) {
+			match, found = prefix, true
+		}
+	}
+
+	if !found {
+		return def
+	}
+
+	return m[match]
+}
@@ -0,0 +1,86 @@
+package tokenizer
+
+import (
+	"math"
+	"sync"
+	"unicode/utf8"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// priorTokens is the weight, in tokens, of the initial ratio against the
+// calibration samples.
+const priorTokens = 1000
+
+// Estimator estimates the amount of tokens from the amount of characters. It's
+// safe for concurrent use.
+type Estimator struct {
+	// CharsPerToken is the initial, average amount of characters per token.
+	CharsPerToken float64 `json:"charsPerToken" validate:"gt=0"`
+
+	// TokensPerMessage is the overhead of each message, e.g.: role, and
+	// delimiters.
+	TokensPerMessage int `json:"tokensPerMessage" validate:"gte=0"`
+
+	mu     sync.RWMutex
+	chars  int
+	tokens int
+}
+
+// Initial characters per token, and tokens per message, by model name
+// prefix, measured on English prose. The longest matching prefix wins.
+var defaultEstimators = map[string][2]float64{
+	"":        {4, 4},
+	"claude":  {3.5, 5},
+	"gemma":   {4.2, 4},
+	"llama":   {3.8, 5},
+	"mistral": {3.6, 4},
+	"qwen":    {3.9, 5},
+}
+
+//////
+// Methods.
+//////
+
+// Ratio returns the current amount of characters per token: the initial one,
+// refined by the calibration samples.
+func (e *Estimator) Ratio() float64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return (e.CharsPerToken*priorTokens + float64(e.chars)) / float64(priorTokens+e.tokens)
+}
+
+// Calibrate refines the ratio with the actual amount of tokens of text of
+// `chars` characters.
+func (e *Estimator) Calibrate(chars, tokens int) {
+	if chars <= 0 || tokens <= 0 {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.chars += chars
+	e.tokens += tokens
+}
+
+// Count estimates the amount of tokens of text.
+func (e *Estimator) Count(text string) int {
+	return int(math.Ceil(float64(utf8.RuneCountInString(text)) / e.Ratio()))
+}
+
+//////
+// Factory.
+//////
+
+// NewEstimator returns an estimator with the initial amount of characters per
+// token, and overhead per message.
+func NewEstimator(charsPerToken float64, tokensPerMessage int) *Estimator {
+	return &Estimator{
+		CharsPerToken:    charsPerToken,
+		TokensPerMessage: tokensPerMessage,
+	}
+}
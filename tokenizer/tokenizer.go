@@ -0,0 +1,287 @@
+package tokenizer
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/thalesfsp/customerror"
+	"github.com/thalesfsp/inference/message"
+)
+
+//////
+// Const, vars, and types.
+//////
+
+// Chat format overhead of OpenAI models: tokens per message, besides the
+// role, and content, and tokens priming the reply.
+//
+// SEE: https://cookbook.openai.com/examples/how_to_count_tokens_with_tiktoken
+const (
+	bpeTokensPerMessage = 3
+	bpeTokensPerReply   = 3
+)
+
+// Singleton.
+var (
+	once      sync.Once
+	singleton *Tokenizer
+)
+
+// Counter counts the tokens of messages, e.g.: remotely.
+type Counter interface {
+	CountTokens(ctx context.Context, model string, messages []message.Message) (int, error)
+}
+
+// Func allows to set options.
+type Func func(t *Tokenizer) error
+
+// Tokenizer counts tokens, safe for concurrent use.
+type Tokenizer struct {
+	mu         sync.RWMutex
+	estimators map[string]*Estimator
+	remotes    map[string]Counter
+}
+
+//////
+// Exported options.
+//////
+
+// WithEstimator sets the estimator of models matching the prefix.
+func WithEstimator(prefix string, estimator *Estimator) Func {
+	return func(t *Tokenizer) error {
+		if estimator == nil || estimator.CharsPerToken <= 0 {
+			return customerror.NewInvalidError("estimator, characters per token must be positive")
+		}
+
+		t.estimators[prefix] = estimator
+
+		return nil
+	}
+}
+
+// WithRemote counts the tokens of models matching the prefix remotely, e.g.:
+// with Anthropic's `count_tokens` endpoint.
+func WithRemote(prefix string, counter Counter) Func {
+	return func(t *Tokenizer) error {
+		if prefix == "" || counter == nil {
+			return customerror.NewRequiredError("remote prefix, and counter")
+		}
+
+		t.remotes[prefix] = counter
+
+		return nil
+	}
+}
+
+//////
+// Methods.
+//////
+
+// Encoding returns the encoding of the model, or Estimate if unknown.
+func (t *Tokenizer) Encoding(model string) string {
+	return EncodingFor(model)
+}
+
+// Estimator returns the estimator of the model.
+func (t *Tokenizer) Estimator(model string) *Estimator {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	return longestPrefix(t.estimators, model, t.estimators[""])
+}
+
+// CountText counts the tokens of text, offline.
+func (t *Tokenizer) CountText(model, text string) (int, error) {
+	name := t.Encoding(model)
+	if name == Estimate {
+		return t.Estimator(model).Count(text), nil
+	}
+
+	enc, err := encoding(name)
+	if err != nil {
+		return 0, err
+	}
+
+	return len(enc.Encode(text, nil, nil)), nil
+}
+
+// CountTokens counts the tokens of the messages, as sent to the model.
+func (t *Tokenizer) CountTokens(model string, messages []message.Message) (int, error) {
+	return t.CountTokensContext(context.Background(), model, messages)
+}
+
+// CountTokensContext counts the tokens of the messages, as sent to the model.
+// Models with a remote backend are counted remotely, others offline.
+func (t *Tokenizer) CountTokensContext(
+	ctx context.Context,
+	model string,
+	messages []message.Message,
+) (int, error) {
+	t.mu.RLock()
+	remote := longestPrefix(t.remotes, model, nil)
+	t.mu.RUnlock()
+
+	if remote != nil {
+		tokens, err := remote.CountTokens(ctx, model, messages)
+		if err != nil {
+			return 0, customerror.NewFailedToError("count tokens remotely", customerror.WithError(err))
+		}
+
+		return tokens, nil
+	}
+
+	name := t.Encoding(model)
+
+	if name == Estimate {
+		estimator := t.Estimator(model)
+
+		tokens := 0
+
+		for _, m := range messages {
+			tokens += estimator.TokensPerMessage + estimator.Count(m.Content)
+		}
+
+		return tokens, nil
+	}
+
+	enc, err := encoding(name)
+	if err != nil {
+		return 0, err
+	}
+
+	tokens := bpeTokensPerReply
+
+	for _, m := range messages {
+		tokens += bpeTokensPerMessage + len(enc.Encode(m.Role, nil, nil)) + len(enc.Encode(m.Content, nil, nil))
+	}
+
+	return tokens, nil
+}
+
+// Calibrate refines the estimator of the model with the amount of input
+// tokens reported by the provider for the messages. Models with a BPE
+// encoding don't need it.
+func (t *Tokenizer) Calibrate(model string, messages []message.Message, inputTokens int) {
+	if t.Encoding(model) != Estimate {
+		return
+	}
+
+	estimator := t.Estimator(model)
+
+	chars := 0
+
+	for _, m := range messages {
+		chars += utf8.RuneCountInString(m.Content)
+	}
+
+	estimator.Calibrate(chars, inputTokens-len(messages)*estimator.TokensPerMessage)
+}
+
+// Split splits text into chunks of at most maxTokens tokens, e.g.: to fit
+// the context window, or the input limit of embedding models.
+func (t *Tokenizer) Split(model, text string, maxTokens int) ([]string, error) {
+	if maxTokens <= 0 {
+		return nil, customerror.NewInvalidError(fmt.Sprintf("maxTokens %d, must be positive", maxTokens))
+	}
+
+	if text == "" {
+		return nil, nil
+	}
+
+	name := t.Encoding(model)
+
+	if name == Estimate {
+		runes := []rune(text)
+		size := max(1, int(float64(maxTokens)*t.Estimator(model).Ratio()))
+
+		chunks := make([]string, 0, len(runes)/size+1)
+
+		for start := 0; start < len(runes); start += size {
+			chunks = append(chunks, string(runes[start:min(start+size, len(runes))]))
+		}
+
+		return chunks, nil
+	}
+
+	enc, err := encoding(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := enc.Encode(text, nil, nil)
+	chunks := []string{}
+
+	for start := 0; start < len(tokens); {
+		end := min(start+maxTokens, len(tokens))
+		chunk := enc.Decode(tokens[start:end])
+
+		// Don't split multi-byte characters spanning tokens.
+		for end < len(tokens) && end-1 > start && !utf8.ValidString(chunk) {
+			end--
+			chunk = enc.Decode(tokens[start:end])
+		}
+
+		chunks = append(chunks, chunk)
+		start = end
+	}
+
+	return chunks, nil
+}
+
+//////
+// Factory.
+//////
+
+// New returns a tokenizer with the default estimators.
+func New(options ...Func) (*Tokenizer, error) {
+	t := &Tokenizer{
+		estimators: make(map[string]*Estimator, len(defaultEstimators)),
+		remotes:    make(map[string]Counter),
+	}
+
+	for prefix, e := range defaultEstimators {
+		t.estimators[prefix] = NewEstimator(e[0], int(e[1]))
+	}
+
+	for _, option := range options {
+		if err := option(t); err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+//////
+// Exported functionalities.
+//////
+
+// Get returns the default tokenizer.
+func Get() *Tokenizer {
+	once.Do(func() {
+		singleton, _ = New()
+	})
+
+	return singleton
+}
+
+// Set sets the default tokenizer.
+func Set(t *Tokenizer) {
+	once.Do(func() {})
+
+	singleton = t
+}
+
+// CountTokens counts the tokens of the messages, as sent to the model, with
+// the default tokenizer. It can be set as the token counter of the context
+// window truncation: `provider.SetTokenCounter(tokenizer.CountTokens)`.
+func CountTokens(model string, messages []message.Message) (int, error) {
+	return Get().CountTokens(model, messages)
+}
+
+// CountText counts the tokens of text, offline, with the default tokenizer.
+func CountText(model, text string) (int, error) {
+	return Get().CountText(model, text)
+}
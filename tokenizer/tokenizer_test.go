@@ -0,0 +1,139 @@
+package tokenizer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/thalesfsp/inference/message"
+)
+
+type counterFunc func(ctx context.Context, model string, messages []message.Message) (int, error)
+
+func (f counterFunc) CountTokens(ctx context.Context, model string, messages []message.Message) (int, error) {
+	return f(ctx, model, messages)
+}
+
+func TestEncodingFor(t *testing.T) {
+	assert.Equal(t, CL100K, EncodingFor("gpt-4"))
+	assert.Equal(t, CL100K, EncodingFor("gpt-4-turbo"))
+	assert.Equal(t, O200K, EncodingFor("gpt-4o-mini"))
+	assert.Equal(t, O200K, EncodingFor("gpt-4.1-nano"))
+	assert.Equal(t, O200K, EncodingFor("o3-mini"))
+	assert.Equal(t, Estimate, EncodingFor("claude-3-5-haiku-20241022"))
+	assert.Equal(t, Estimate, EncodingFor("llama3.2:3b"))
+}
+
+func TestCountText(t *testing.T) {
+	tests := []struct {
+		model string
+		text  string
+		want  int
+	}{
+		{model: "gpt-4", text: "hello world", want: 2},
+		{model: "gpt-4o", text: "hello world", want: 2},
+		{model: "gpt-3.5-turbo", text: "tiktoken is great!", want: 6},
+		{model: "gpt-4o", text: "", want: 0},
+		// 35 characters, at 3.5 characters per token.
+		{model: "claude-3-5-haiku-20241022", text: strings.Repeat("a", 35), want: 10},
+		// 40 characters, at 4 characters per token.
+		{model: "unknown", text: strings.Repeat("a", 40), want: 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.model+" "+tt.text, func(t *testing.T) {
+			got, err := CountText(tt.model, tt.text)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestCountTokens(t *testing.T) {
+	messages := message.NewMessages([]string{"be brief"}, []string{"hello world"})
+
+	// 3 per reply, and for each message: 3, the role, and the content.
+	got, err := CountTokens("gpt-4o", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, 3+(3+1+2)+(3+1+2), got)
+
+	// 4 per message, and the content.
+	got, err = CountTokens("unknown", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, (4+2)+(4+3), got)
+
+	t.Run("Remote", func(t *testing.T) {
+		tk, err := New(WithRemote("claude", counterFunc(func(_ context.Context, model string, m []message.Message) (int, error) {
+			assert.Equal(t, "claude-3-5-haiku-20241022", model)
+			assert.Equal(t, messages, m)
+
+			return 42, nil
+		})))
+		assert.NoError(t, err)
+
+		got, err := tk.CountTokens("claude-3-5-haiku-20241022", messages)
+		assert.NoError(t, err)
+		assert.Equal(t, 42, got)
+
+		// Others are counted offline.
+		got, err = tk.CountTokens("gpt-4o", messages)
+		assert.NoError(t, err)
+		assert.Equal(t, 15, got)
+	})
+}
+
+func TestCalibrate(t *testing.T) {
+	tk, err := New(WithEstimator("custom", NewEstimator(4, 0)))
+	assert.NoError(t, err)
+
+	messages := message.NewMessages(nil, []string{strings.Repeat("a", 4000)})
+
+	got, err := tk.CountTokens("custom-model", messages)
+	assert.NoError(t, err)
+	assert.Equal(t, 1000, got)
+
+	// The model actually uses 2 characters per token.
+	tk.Calibrate("custom-model", messages, 2000)
+	tk.Calibrate("custom-model", messages, 2000)
+
+	assert.InDelta(t, 2.4, tk.Estimator("custom-model").Ratio(), 0.001)
+
+	// Models with a BPE encoding are not calibrated.
+	tk.Calibrate("gpt-4o", messages, 1)
+	assert.Equal(t, 4.0, tk.Estimator("gpt-4o").Ratio())
+
+	_, err = New(WithEstimator("custom", NewEstimator(0, 0)))
+	assert.Error(t, err)
+}
+
+func TestSplit(t *testing.T) {
+	text := strings.Repeat("The quick brown fox jumps over the lazy dog. ", 20)
+
+	for _, model := range []string{"gpt-4o", "llama3.2"} {
+		t.Run(model, func(t *testing.T) {
+			chunks, err := Get().Split(model, text, 16)
+			assert.NoError(t, err)
+			assert.Greater(t, len(chunks), 1)
+			assert.Equal(t, text, strings.Join(chunks, ""))
+
+			for _, chunk := range chunks {
+				tokens, err := CountText(model, chunk)
+				assert.NoError(t, err)
+				assert.LessOrEqual(t, tokens, 16)
+			}
+		})
+	}
+
+	// Multi-byte characters are not split.
+	chunks, err := Get().Split("gpt-4", strings.Repeat("日本語のテキスト", 10), 3)
+	assert.NoError(t, err)
+
+	for _, chunk := range chunks {
+		assert.True(t, utf8.ValidString(chunk), chunk)
+	}
+
+	_, err = Get().Split("gpt-4o", text, 0)
+	assert.Error(t, err)
+}